@@ -0,0 +1,76 @@
+package caldav
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// Filter - упрощенный calendar-query: тип компонента и интервал (RFC 4791, 9.7).
+// Остальные условия (prop-filter, text-match) не поддерживаются и отбор не сужают
+type Filter struct {
+    Component string // VEVENT или VTODO; пусто - любые
+    Start     *time.Time
+    End       *time.Time
+}
+
+type compFilter struct {
+    Name        string       `xml:"name,attr"`
+    CompFilters []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+    TimeRange   *struct {
+        Start string `xml:"start,attr"`
+        End   string `xml:"end,attr"`
+    } `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+}
+
+// filter - из <C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">...
+func (cf compFilter) filter() (Filter, error) {
+    var f Filter
+    if !strings.EqualFold(cf.Name, "VCALENDAR") || len(cf.CompFilters) == 0 {
+        return f, nil
+    }
+
+    inner := cf.CompFilters[0]
+    f.Component = strings.ToUpper(inner.Name)
+    if inner.TimeRange == nil {
+        return f, nil
+    }
+    for _, bound := range []struct {
+        value  string
+        target **time.Time
+    }{{inner.TimeRange.Start, &f.Start}, {inner.TimeRange.End, &f.End}} {
+        if bound.value == "" {
+            continue
+        }
+        t, err := time.Parse("20060102T150405Z", bound.value)
+        if err != nil {
+            return f, fmt.Errorf("неверная граница time-range %q", bound.value)
+        }
+        *bound.target = &t
+    }
+    return f, nil
+}
+
+// Match - подходит ли ресурс. start, end - интервал компонента (nil - без дат);
+// повторяющийся ресурс подходит, если начался до конца интервала
+func (f Filter) Match(component string, start, end *time.Time, recurring bool) bool {
+    if f.Component != "" && f.Component != component {
+        return false
+    }
+    if start == nil && end == nil {
+        return true // VTODO без дат попадает в любой интервал
+    }
+    if start == nil {
+        start = end
+    }
+    if end == nil {
+        end = start
+    }
+    if f.End != nil && !start.Before(*f.End) {
+        return false
+    }
+    if f.Start != nil && !recurring && end.Before(*f.Start) {
+        return false
+    }
+    return true
+}
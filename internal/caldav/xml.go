@@ -0,0 +1,302 @@
+// Package caldav - XML-часть WebDAV/CalDAV (RFC 4918, RFC 4791): разбор PROPFIND и REPORT,
+// сборка ответа multistatus. Что лежит в коллекциях, решают обработчики
+package caldav
+
+import (
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strings"
+)
+
+// Пространства имен свойств
+const (
+    NSDAV       = "DAV:"
+    NSCalDAV    = "urn:ietf:params:xml:ns:caldav"
+    NSCalServer = "http://calendarserver.org/ns/"
+)
+
+// prefixes - префиксы, объявленные в корне ответа
+var prefixes = map[string]string{
+    NSDAV:       "D",
+    NSCalDAV:    "C",
+    NSCalServer: "CS",
+}
+
+// Свойства, которые отдает сервер
+var (
+    PropResourceType          = xml.Name{Space: NSDAV, Local: "resourcetype"}
+    PropDisplayName           = xml.Name{Space: NSDAV, Local: "displayname"}
+    PropGetETag               = xml.Name{Space: NSDAV, Local: "getetag"}
+    PropGetContentType        = xml.Name{Space: NSDAV, Local: "getcontenttype"}
+    PropGetContentLength      = xml.Name{Space: NSDAV, Local: "getcontentlength"}
+    PropGetLastModified       = xml.Name{Space: NSDAV, Local: "getlastmodified"}
+    PropCurrentUserPrincipal  = xml.Name{Space: NSDAV, Local: "current-user-principal"}
+    PropPrincipalURL          = xml.Name{Space: NSDAV, Local: "principal-URL"}
+    PropOwner                 = xml.Name{Space: NSDAV, Local: "owner"}
+    PropSupportedReportSet    = xml.Name{Space: NSDAV, Local: "supported-report-set"}
+    PropCalendarHomeSet       = xml.Name{Space: NSCalDAV, Local: "calendar-home-set"}
+    PropCalendarDescription   = xml.Name{Space: NSCalDAV, Local: "calendar-description"}
+    PropCalendarData          = xml.Name{Space: NSCalDAV, Local: "calendar-data"}
+    PropSupportedComponentSet = xml.Name{Space: NSCalDAV, Local: "supported-calendar-component-set"}
+    PropGetCTag               = xml.Name{Space: NSCalServer, Local: "getctag"}
+)
+
+// PropFind - какие свойства запрошены: список или все (allprop, пустое тело)
+type PropFind struct {
+    AllProp  bool
+    PropName bool
+    Props    []xml.Name
+}
+
+// propList - дочерние элементы <D:prop> (значения в запросе не нужны)
+type propList struct {
+    Names []xml.Name
+}
+
+func (p *propList) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+    for {
+        tok, err := d.Token()
+        if err != nil {
+            return err
+        }
+        switch t := tok.(type) {
+        case xml.StartElement:
+            p.Names = append(p.Names, t.Name)
+            if err := d.Skip(); err != nil {
+                return err
+            }
+        case xml.EndElement:
+            return nil
+        }
+    }
+}
+
+type propfindRequest struct {
+    XMLName  xml.Name  `xml:"DAV: propfind"`
+    AllProp  *struct{} `xml:"DAV: allprop"`
+    PropName *struct{} `xml:"DAV: propname"`
+    Prop     *propList `xml:"DAV: prop"`
+}
+
+// ParsePropFind - тело PROPFIND; пустое тело по RFC 4918 означает allprop
+func ParsePropFind(r io.Reader) (*PropFind, error) {
+    var req propfindRequest
+    if err := xml.NewDecoder(r).Decode(&req); err != nil {
+        if err == io.EOF {
+            return &PropFind{AllProp: true}, nil
+        }
+        return nil, fmt.Errorf("неверный PROPFIND: %w", err)
+    }
+    pf := &PropFind{AllProp: req.AllProp != nil, PropName: req.PropName != nil}
+    if req.Prop != nil {
+        pf.Props = req.Prop.Names
+    }
+    if !pf.PropName && len(pf.Props) == 0 {
+        pf.AllProp = true
+    }
+    return pf, nil
+}
+
+// Поддерживаемые отчеты
+const (
+    ReportCalendarQuery    = "calendar-query"
+    ReportCalendarMultiget = "calendar-multiget"
+)
+
+// Report - разобранный REPORT
+type Report struct {
+    Kind string // ReportCalendarQuery или ReportCalendarMultiget
+    PropFind
+    Hrefs  []string // calendar-multiget
+    Filter Filter   // calendar-query
+}
+
+type reportRequest struct {
+    XMLName xml.Name
+    AllProp *struct{} `xml:"DAV: allprop"`
+    Prop    *propList `xml:"DAV: prop"`
+    Hrefs   []string  `xml:"DAV: href"`
+    Filter  *struct {
+        CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+    } `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+// ErrUnsupportedReport - REPORT, которого сервер не умеет
+var ErrUnsupportedReport = fmt.Errorf("отчет не поддерживается")
+
+// ParseReport - тело REPORT: calendar-query или calendar-multiget
+func ParseReport(r io.Reader) (*Report, error) {
+    var req reportRequest
+    if err := xml.NewDecoder(r).Decode(&req); err != nil {
+        return nil, fmt.Errorf("неверный REPORT: %w", err)
+    }
+    if req.XMLName.Space != NSCalDAV ||
+        (req.XMLName.Local != ReportCalendarQuery && req.XMLName.Local != ReportCalendarMultiget) {
+        return nil, fmt.Errorf("%s: %w", req.XMLName.Local, ErrUnsupportedReport)
+    }
+
+    report := &Report{Kind: req.XMLName.Local, Hrefs: req.Hrefs}
+    report.AllProp = req.AllProp != nil
+    if req.Prop != nil {
+        report.Props = req.Prop.Names
+    }
+    if len(report.Props) == 0 {
+        report.AllProp = true
+    }
+    if req.Filter != nil {
+        filter, err := req.Filter.CompFilter.filter()
+        if err != nil {
+            return nil, err
+        }
+        report.Filter = filter
+    }
+    return report, nil
+}
+
+// Props - значения свойств ресурса: готовый XML содержимого элемента (см. Text, Href)
+type Props map[xml.Name]string
+
+// Text - текстовое значение свойства
+func Text(s string) string {
+    var b strings.Builder
+    xml.EscapeText(&b, []byte(s))
+    return b.String()
+}
+
+// Href - значение-ссылка (current-user-principal, calendar-home-set...)
+func Href(path string) string {
+    return "<D:href>" + Text(path) + "</D:href>"
+}
+
+// Element - пустой элемент (значение resourcetype, supported-calendar-component-set...)
+func Element(name xml.Name, attrs ...string) string {
+    var b strings.Builder
+    b.WriteString("<" + qualified(name))
+    for i := 0; i+1 < len(attrs); i += 2 {
+        b.WriteString(fmt.Sprintf(` %s="%s"`, attrs[i], Text(attrs[i+1])))
+    }
+    b.WriteString(xmlnsAttr(name) + "/>")
+    return b.String()
+}
+
+func qualified(name xml.Name) string {
+    if name.Space == "" {
+        return name.Local
+    }
+    if prefix, ok := prefixes[name.Space]; ok {
+        return prefix + ":" + name.Local
+    }
+    return "X:" + name.Local
+}
+
+// xmlnsAttr - объявление пространства имен, которого нет в корне ответа
+func xmlnsAttr(name xml.Name) string {
+    if _, ok := prefixes[name.Space]; ok || name.Space == "" {
+        return ""
+    }
+    return ` xmlns:X="` + Text(name.Space) + `"`
+}
+
+type response struct {
+    href     string
+    status   int // ответ без свойств (404 в multiget)
+    found    []string
+    notFound []string
+}
+
+// Multistatus - ответ 207 на PROPFIND и REPORT
+type Multistatus struct {
+    responses []response
+}
+
+// Add - ресурс со свойствами: запрошенные и известные - в 200, неизвестные - в 404
+func (m *Multistatus) Add(href string, props Props, pf *PropFind) {
+    resp := response{href: href}
+    switch {
+    case pf.PropName:
+        for _, name := range sortedNames(props) {
+            resp.found = append(resp.found, Element(name))
+        }
+    case pf.AllProp:
+        for _, name := range sortedNames(props) {
+            if name == PropCalendarData {
+                continue // только по явному запросу
+            }
+            resp.found = append(resp.found, propElement(name, props[name]))
+        }
+    default:
+        for _, name := range pf.Props {
+            if value, ok := props[name]; ok {
+                resp.found = append(resp.found, propElement(name, value))
+            } else {
+                resp.notFound = append(resp.notFound, Element(name))
+            }
+        }
+    }
+    m.responses = append(m.responses, resp)
+}
+
+// AddStatus - ресурс без свойств, например отсутствующий href в calendar-multiget
+func (m *Multistatus) AddStatus(href string, status int) {
+    m.responses = append(m.responses, response{href: href, status: status})
+}
+
+// Bytes - XML ответа
+func (m *Multistatus) Bytes() []byte {
+    var b strings.Builder
+    b.WriteString(xml.Header)
+    b.WriteString("<D:multistatus")
+    for _, ns := range []string{NSDAV, NSCalDAV, NSCalServer} {
+        b.WriteString(fmt.Sprintf(` xmlns:%s="%s"`, prefixes[ns], ns))
+    }
+    b.WriteString(">\n")
+
+    for _, resp := range m.responses {
+        b.WriteString("<D:response>" + Href(resp.href))
+        if resp.status != 0 {
+            b.WriteString(statusLine(resp.status))
+        }
+        for _, group := range []struct {
+            props  []string
+            status int
+        }{{resp.found, http.StatusOK}, {resp.notFound, http.StatusNotFound}} {
+            if len(group.props) == 0 {
+                continue
+            }
+            b.WriteString("<D:propstat><D:prop>" + strings.Join(group.props, "") + "</D:prop>")
+            b.WriteString(statusLine(group.status) + "</D:propstat>")
+        }
+        b.WriteString("</D:response>\n")
+    }
+
+    b.WriteString("</D:multistatus>\n")
+    return []byte(b.String())
+}
+
+func propElement(name xml.Name, value string) string {
+    if value == "" {
+        return Element(name)
+    }
+    return "<" + qualified(name) + xmlnsAttr(name) + ">" + value + "</" + qualified(name) + ">"
+}
+
+func statusLine(status int) string {
+    return fmt.Sprintf("<D:status>HTTP/1.1 %d %s</D:status>", status, http.StatusText(status))
+}
+
+func sortedNames(props Props) []xml.Name {
+    names := make([]xml.Name, 0, len(props))
+    for name := range props {
+        names = append(names, name)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        if names[i].Space != names[j].Space {
+            return names[i].Space < names[j].Space
+        }
+        return names[i].Local < names[j].Local
+    })
+    return names
+}
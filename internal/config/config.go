@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+    "os"
+    "kanban-calendar/internal/datetime"
+)
 
 type Config struct {
     DBHost         string
@@ -12,6 +15,8 @@ type Config struct {
     TelegramToken  string
     TelegramChatID string
     DBRetryDelay   string
+    TimeZone       string // IANA-пояс сервера: ввод дат без смещения, напоминания, календари
+    TrashRetention string // Сколько дней удаленные задачи хранятся в корзине (0 - бессрочно)
 }
 
 func Load() *Config {
@@ -25,6 +30,8 @@ func Load() *Config {
         TelegramToken:  getEnv("TELEGRAM_TOKEN", ""),
         TelegramChatID: getEnv("TELEGRAM_CHAT_ID", ""),
         DBRetryDelay:   getEnv("DB_RETRY_DELAY", "5"),
+        TimeZone:       getEnv("APP_TIMEZONE", datetime.DefaultZone),
+        TrashRetention: getEnv("TRASH_RETENTION_DAYS", "30"),
     }
 }
 
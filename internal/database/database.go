@@ -1,19 +1,22 @@
 package database
 
 import (
+    "context"
     "database/sql"
     "fmt"
     "log"
     "time"
     "kanban-calendar/internal/config"
+    "kanban-calendar/migrations"
     _ "github.com/lib/pq" // Драйвер PostgreSQL
 )
 
 // Connect - подключается к PostgreSQL с повторными попытками
 func Connect(cfg *config.Config) (*sql.DB, error) {
-    // Строка подключения
+    // Строка подключения. Даты хранятся в TIMESTAMP без пояса в UTC, поэтому сессия тоже в UTC:
+    // иначе CURRENT_TIMESTAMP и NOW() идут в поясе сервера БД (TZ контейнера) и сравнения съезжают
     connStr := fmt.Sprintf(
-        "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+        "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable timezone=UTC",
         cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
     )
     
@@ -57,27 +60,23 @@ func Connect(cfg *config.Config) (*sql.DB, error) {
     return nil, fmt.Errorf("не удалось подключиться к БД после %d попыток: %w", maxAttempts, err)
 }
 
-// Migrate - выполняет миграции
+// Migrate - применяет все новые миграции из каталога migrations
 func Migrate(db *sql.DB) error {
-    // Пока просто проверяем таблицу
-    query := `
-        SELECT EXISTS (
-            SELECT FROM information_schema.tables 
-            WHERE table_name = 'tasks'
-        )
-    `
-    
-    var exists bool
-    err := db.QueryRow(query).Scan(&exists)
+    m, err := NewMigrator(db, migrations.FS)
     if err != nil {
-        return fmt.Errorf("ошибка проверки таблицы: %w", err)
+        return err
     }
-    
-    if !exists {
-        log.Println("Таблица 'tasks' не существует. Нужно выполнить миграции.")
+
+    applied, err := m.Up(context.Background())
+    if err != nil {
+        return err
+    }
+
+    if applied > 0 {
+        log.Printf("Применено миграций: %d", applied)
     } else {
-        log.Println("Таблица 'tasks' существует")
+        log.Println("Схема БД актуальна")
     }
-    
+
     return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,294 @@
+package database
+
+import (
+    "context"
+    "crypto/sha256"
+    "database/sql"
+    "encoding/hex"
+    "fmt"
+    "io/fs"
+    "log"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+)
+
+// migrationLockID - ключ advisory-блокировки, чтобы два инстанса не катили миграции одновременно
+const migrationLockID = 7214509031
+
+// migrationFileRe - формат имени файла: 001_init_schema.up.sql
+var migrationFileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migration - одна версия схемы (пара up/down)
+type Migration struct {
+    Version  int
+    Name     string
+    Up       string
+    Down     string
+    Checksum string // sha256 от up-скрипта
+}
+
+// MigrationStatus - состояние миграции относительно базы
+type MigrationStatus struct {
+    Version   int
+    Name      string
+    Applied   bool
+    AppliedAt *time.Time
+    Modified  bool // файл изменили после применения
+}
+
+type appliedMigration struct {
+    name      string
+    checksum  string
+    appliedAt time.Time
+}
+
+// Migrator - применяет и откатывает миграции, записывая версии в schema_migrations
+type Migrator struct {
+    db         *sql.DB
+    migrations []Migration
+}
+
+// NewMigrator - конструктор, читает миграции из fsys
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+    migrations, err := loadMigrations(fsys)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations - собирает пары up/down и сортирует по версии
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+    entries, err := fs.ReadDir(fsys, ".")
+    if err != nil {
+        return nil, fmt.Errorf("ошибка чтения миграций: %w", err)
+    }
+
+    byVersion := make(map[int]*Migration)
+    for _, entry := range entries {
+        if entry.IsDir() {
+            continue
+        }
+
+        m := migrationFileRe.FindStringSubmatch(entry.Name())
+        if m == nil {
+            return nil, fmt.Errorf("некорректное имя файла миграции: %s", entry.Name())
+        }
+
+        version, _ := strconv.Atoi(m[1])
+        body, err := fs.ReadFile(fsys, entry.Name())
+        if err != nil {
+            return nil, fmt.Errorf("ошибка чтения %s: %w", entry.Name(), err)
+        }
+
+        mig, ok := byVersion[version]
+        if !ok {
+            mig = &Migration{Version: version, Name: m[2]}
+            byVersion[version] = mig
+        } else if mig.Name != m[2] {
+            return nil, fmt.Errorf("версия %d используется в двух миграциях: %s и %s", version, mig.Name, m[2])
+        }
+
+        if m[3] == "up" {
+            mig.Up = string(body)
+            sum := sha256.Sum256(body)
+            mig.Checksum = hex.EncodeToString(sum[:])
+        } else {
+            mig.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, mig := range byVersion {
+        if mig.Up == "" {
+            return nil, fmt.Errorf("у миграции %03d_%s нет up-скрипта", mig.Version, mig.Name)
+        }
+        migrations = append(migrations, *mig)
+    }
+    sort.Slice(migrations, func(i, j int) bool {
+        return migrations[i].Version < migrations[j].Version
+    })
+
+    return migrations, nil
+}
+
+// Up - применяет все непримененные миграции, возвращает их количество
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+    count := 0
+    err := m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.verify(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for _, mig := range m.migrations {
+            if _, ok := applied[mig.Version]; ok {
+                continue
+            }
+
+            log.Printf("Применяем миграцию %03d_%s", mig.Version, mig.Name)
+            err := inTx(ctx, conn, func(tx *sql.Tx) error {
+                if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+                    return err
+                }
+                _, err := tx.ExecContext(ctx,
+                    `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+                    mig.Version, mig.Name, mig.Checksum,
+                )
+                return err
+            })
+            if err != nil {
+                return fmt.Errorf("миграция %03d_%s: %w", mig.Version, mig.Name, err)
+            }
+            count++
+        }
+        return nil
+    })
+    return count, err
+}
+
+// Down - откатывает последние steps примененных миграций
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+    count := 0
+    err := m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := m.verify(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+            mig := m.migrations[i]
+            if _, ok := applied[mig.Version]; !ok {
+                continue
+            }
+            if mig.Down == "" {
+                return fmt.Errorf("у миграции %03d_%s нет down-скрипта", mig.Version, mig.Name)
+            }
+
+            log.Printf("Откатываем миграцию %03d_%s", mig.Version, mig.Name)
+            err := inTx(ctx, conn, func(tx *sql.Tx) error {
+                if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+                    return err
+                }
+                _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+                return err
+            })
+            if err != nil {
+                return fmt.Errorf("откат %03d_%s: %w", mig.Version, mig.Name, err)
+            }
+            count++
+        }
+        return nil
+    })
+    return count, err
+}
+
+// Status - возвращает состояние каждой известной миграции
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+    var result []MigrationStatus
+    err := m.withLock(ctx, func(conn *sql.Conn) error {
+        applied, err := loadApplied(ctx, conn)
+        if err != nil {
+            return err
+        }
+
+        for _, mig := range m.migrations {
+            st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+            if a, ok := applied[mig.Version]; ok {
+                appliedAt := a.appliedAt
+                st.Applied = true
+                st.AppliedAt = &appliedAt
+                st.Modified = a.checksum != mig.Checksum
+            }
+            result = append(result, st)
+        }
+        return nil
+    })
+    return result, err
+}
+
+// verify - проверяет, что примененные миграции не были изменены после применения
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+    applied, err := loadApplied(ctx, conn)
+    if err != nil {
+        return nil, err
+    }
+
+    known := make(map[int]bool, len(m.migrations))
+    for _, mig := range m.migrations {
+        known[mig.Version] = true
+        if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+            return nil, fmt.Errorf("миграция %03d_%s изменена после применения (checksum не совпадает)", mig.Version, mig.Name)
+        }
+    }
+    for version, a := range applied {
+        if !known[version] {
+            log.Printf("Предупреждение: в БД применена неизвестная миграция %03d_%s", version, a.name)
+        }
+    }
+
+    return applied, nil
+}
+
+// withLock - выполняет fn на выделенном соединении под advisory-блокировкой
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+
+    if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+        return fmt.Errorf("не удалось получить блокировку миграций: %w", err)
+    }
+    defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+    query := `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            checksum VARCHAR(64) NOT NULL,
+            applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+        )
+    `
+    if _, err := conn.ExecContext(ctx, query); err != nil {
+        return fmt.Errorf("ошибка создания schema_migrations: %w", err)
+    }
+
+    return fn(conn)
+}
+
+// loadApplied - читает примененные версии из schema_migrations
+func loadApplied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+    rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    applied := make(map[int]appliedMigration)
+    for rows.Next() {
+        var version int
+        var a appliedMigration
+        if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+            return nil, err
+        }
+        applied[version] = a
+    }
+    return applied, rows.Err()
+}
+
+// inTx - выполняет fn в транзакции, откатывая ее при ошибке
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    if err := fn(tx); err != nil {
+        tx.Rollback()
+        return err
+    }
+    return tx.Commit()
+}
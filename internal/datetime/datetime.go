@@ -0,0 +1,42 @@
+// Package datetime - часовые пояса и единые правила разбора и вывода дат.
+// В БД время хранится в UTC; пояс нужен, чтобы понять ввод без смещения и показать дату человеку
+package datetime
+
+import (
+    "fmt"
+    "strings"
+    "time"
+)
+
+// DefaultZone - пояс сервера, если в конфигурации ничего не задано
+const DefaultZone = "Asia/Yekaterinburg"
+
+// Форматы вывода для людей (Telegram, тексты ошибок)
+const (
+    LayoutDateTime = "02.01.2006 15:04"
+    LayoutDate     = "02.01.2006"
+)
+
+// LoadZone - пояс IANA по имени (Europe/Moscow). "Local" не принимается:
+// пояс машины, на которой запущен сервер, ничего не говорит пользователю
+func LoadZone(name string) (*time.Location, error) {
+    name = strings.TrimSpace(name)
+    if name == "" || name == "Local" {
+        return nil, fmt.Errorf("часовой пояс не указан")
+    }
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+    }
+    return loc, nil
+}
+
+// Format - дата и время в поясе loc
+func Format(t time.Time, loc *time.Location) string {
+    return t.In(loc).Format(LayoutDateTime)
+}
+
+// FormatDate - только дата в поясе loc (задачи на весь день)
+func FormatDate(t time.Time, loc *time.Location) string {
+    return t.In(loc).Format(LayoutDate)
+}
@@ -0,0 +1,192 @@
+package datetime
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Value - разобранная дата: момент в UTC и признак "на весь день" (введена только дата)
+type Value struct {
+    Time   time.Time
+    AllDay bool
+}
+
+// inputHint - что принимает Parse; добавляется к ошибкам, чтобы клиент видел допустимые формы
+const inputHint = "ожидается RFC3339, ГГГГ-ММ-ДД[TЧЧ:ММ], ДД.ММ.ГГГГ [ЧЧ:ММ], +3d или next friday 18:00"
+
+// localLayouts - ввод со временем, но без смещения: время понимается в поясе пользователя
+var localLayouts = []string{
+    "2006-01-02T15:04:05",
+    "2006-01-02T15:04",
+    "2006-01-02 15:04:05",
+    "2006-01-02 15:04",
+    "02.01.2006 15:04",
+}
+
+// dateLayouts - только дата: задача на весь день
+var dateLayouts = []string{
+    "2006-01-02",
+    "02.01.2006",
+}
+
+// relativeRe - смещение от текущего момента: +3d, +1w2d, -30m, +1d12h
+var relativeRe = regexp.MustCompile(`^([+-])((?:\d+[mhdw])+)$`)
+var relativePartRe = regexp.MustCompile(`(\d+)([mhdw])`)
+
+// Слова относительных дат (английские и русские)
+var (
+    nextWords = map[string]bool{
+        "next": true, "следующий": true, "следующая": true, "следующую": true, "следующее": true, "след": true,
+    }
+    skipWords = map[string]bool{"at": true, "on": true, "в": true, "во": true}
+    dayWords  = map[string]int{
+        "today": 0, "сегодня": 0,
+        "tomorrow": 1, "завтра": 1,
+        "послезавтра": 2,
+    }
+    weekdayWords = map[string]time.Weekday{
+        "monday": time.Monday, "mon": time.Monday, "понедельник": time.Monday, "пн": time.Monday,
+        "tuesday": time.Tuesday, "tue": time.Tuesday, "вторник": time.Tuesday, "вт": time.Tuesday,
+        "wednesday": time.Wednesday, "wed": time.Wednesday, "среда": time.Wednesday, "среду": time.Wednesday, "ср": time.Wednesday,
+        "thursday": time.Thursday, "thu": time.Thursday, "четверг": time.Thursday, "чт": time.Thursday,
+        "friday": time.Friday, "fri": time.Friday, "пятница": time.Friday, "пятницу": time.Friday, "пт": time.Friday,
+        "saturday": time.Saturday, "sat": time.Saturday, "суббота": time.Saturday, "субботу": time.Saturday, "сб": time.Saturday,
+        "sunday": time.Sunday, "sun": time.Sunday, "воскресенье": time.Sunday, "вс": time.Sunday,
+    }
+)
+
+// Parse - дата из запроса; результат - в UTC. Формы ввода:
+//   - RFC3339 со смещением (2026-01-20T15:00:00Z) - момент времени, пояс не нужен;
+//   - местное время пояса loc: 2026-01-20T18:00, 20.01.2026 18:00;
+//   - только дата (2026-01-20, 20.01.2026) - полночь в поясе loc, AllDay;
+//   - смещение от now: +3d, +2h, +1w2d, -30m (m, h, d, w);
+//   - слова: today, tomorrow, friday, next friday 18:00, завтра 9:00, в следующую пятницу.
+//     Без времени - весь день. "friday" - ближайшая пятница начиная с сегодняшнего дня,
+//     "next friday" - ближайшая пятница после сегодняшнего дня
+func Parse(s string, loc *time.Location, now time.Time) (Value, error) {
+    s = strings.TrimSpace(s)
+    if s == "" {
+        return Value{}, fmt.Errorf("пустая дата: %s", inputHint)
+    }
+    if t, err := time.Parse(time.RFC3339, s); err == nil {
+        return Value{Time: t.UTC()}, nil
+    }
+    for _, layout := range localLayouts {
+        if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+            return Value{Time: t.UTC()}, nil
+        }
+    }
+    for _, layout := range dateLayouts {
+        if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+            return Value{Time: t.UTC(), AllDay: true}, nil
+        }
+    }
+
+    lower := strings.ToLower(s)
+    if m := relativeRe.FindStringSubmatch(strings.ReplaceAll(lower, " ", "")); m != nil {
+        return parseRelative(m[1], m[2], loc, now)
+    }
+    if v, ok, err := parseWords(lower, loc, now); ok {
+        return v, err
+    }
+    return Value{}, fmt.Errorf("неверный формат даты %q: %s", s, inputHint)
+}
+
+// ParseTime - как Parse, но нужен только момент времени (интервалы запросов, исключенные даты)
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+    v, err := Parse(s, loc, time.Now())
+    return v.Time, err
+}
+
+// ParseOptional - как Parse, но пустая строка означает "даты нет"
+func ParseOptional(s string, loc *time.Location, now time.Time) (*Value, error) {
+    if strings.TrimSpace(s) == "" {
+        return nil, nil
+    }
+    v, err := Parse(s, loc, now)
+    if err != nil {
+        return nil, err
+    }
+    return &v, nil
+}
+
+// parseRelative - +3d, +1w2d: дни и недели - календарные (в поясе loc), часы и минуты - точные
+func parseRelative(sign, parts string, loc *time.Location, now time.Time) (Value, error) {
+    dir := 1
+    if sign == "-" {
+        dir = -1
+    }
+    t := now.In(loc)
+    for _, part := range relativePartRe.FindAllStringSubmatch(parts, -1) {
+        n, err := strconv.Atoi(part[1])
+        if err != nil || n > 10000 {
+            return Value{}, fmt.Errorf("слишком большое смещение %q", part[0])
+        }
+        n *= dir
+        switch part[2] {
+        case "m":
+            t = t.Add(time.Duration(n) * time.Minute)
+        case "h":
+            t = t.Add(time.Duration(n) * time.Hour)
+        case "d":
+            t = t.AddDate(0, 0, n)
+        case "w":
+            t = t.AddDate(0, 0, 7*n)
+        }
+    }
+    return Value{Time: t.UTC()}, nil
+}
+
+// parseWords - today, tomorrow 9:00, next friday 18:00, в следующую пятницу. ok = false - это не слова
+func parseWords(s string, loc *time.Location, now time.Time) (Value, bool, error) {
+    fields := strings.Fields(s)
+    if len(fields) > 0 && skipWords[fields[0]] {
+        fields = fields[1:]
+    }
+    next := false
+    if len(fields) > 0 && nextWords[fields[0]] {
+        next = true
+        fields = fields[1:]
+    }
+    if len(fields) == 0 {
+        return Value{}, false, nil
+    }
+
+    today := now.In(loc)
+    var offset int
+    if days, ok := dayWords[fields[0]]; ok {
+        if next {
+            return Value{}, true, fmt.Errorf("%q: next используется только с днем недели", s)
+        }
+        offset = days
+    } else if weekday, ok := weekdayWords[fields[0]]; ok {
+        offset = (int(weekday) - int(today.Weekday()) + 7) % 7
+        if next && offset == 0 {
+            offset = 7
+        }
+    } else {
+        return Value{}, false, nil
+    }
+    fields = fields[1:]
+
+    if len(fields) > 0 && skipWords[fields[0]] {
+        fields = fields[1:]
+    }
+    switch len(fields) {
+    case 0:
+        day := time.Date(today.Year(), today.Month(), today.Day()+offset, 0, 0, 0, 0, loc)
+        return Value{Time: day.UTC(), AllDay: true}, true, nil
+    case 1:
+        clock, err := time.Parse("15:04", fields[0])
+        if err != nil {
+            return Value{}, true, fmt.Errorf("неверное время %q: ожидается ЧЧ:ММ", fields[0])
+        }
+        t := time.Date(today.Year(), today.Month(), today.Day()+offset, clock.Hour(), clock.Minute(), 0, 0, loc)
+        return Value{Time: t.UTC()}, true, nil
+    default:
+        return Value{}, true, fmt.Errorf("неверный формат даты %q: %s", s, inputHint)
+    }
+}
@@ -0,0 +1,76 @@
+package datetime
+
+import (
+    "testing"
+    "time"
+    _ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+    moscow, err := time.LoadLocation("Europe/Moscow")
+    if err != nil {
+        t.Fatal(err)
+    }
+    now := time.Date(2026, 1, 21, 12, 0, 0, 0, time.UTC) // среда, 15:00 по Москве
+    local := func(d, h, m int) time.Time {
+        return time.Date(2026, 1, d, h, m, 0, 0, moscow).UTC()
+    }
+
+    tests := []struct {
+        in      string
+        want    time.Time
+        allDay  bool
+        wantErr bool
+    }{
+        {in: "2026-01-20T15:00:00Z", want: time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)},
+        {in: "2026-01-20T18:00:00+05:00", want: time.Date(2026, 1, 20, 13, 0, 0, 0, time.UTC)},
+        {in: "2026-01-20T18:00", want: local(20, 18, 0)},
+        {in: "2026-01-20 18:00:30", want: local(20, 18, 0).Add(30 * time.Second)},
+        {in: "20.01.2026 18:00", want: local(20, 18, 0)},
+        {in: "2026-01-20", want: local(20, 0, 0), allDay: true},
+        {in: " 20.01.2026 ", want: local(20, 0, 0), allDay: true},
+        {in: "+3d", want: now.AddDate(0, 0, 3)},
+        {in: "+1w2d", want: now.AddDate(0, 0, 9)},
+        {in: "-30m", want: now.Add(-30 * time.Minute)},
+        {in: "+ 2h", want: now.Add(2 * time.Hour)},
+        {in: "today", want: local(21, 0, 0), allDay: true},
+        {in: "Завтра", want: local(22, 0, 0), allDay: true},
+        {in: "завтра 9:00", want: local(22, 9, 0)},
+        {in: "tomorrow at 09:30", want: local(22, 9, 30)},
+        {in: "friday", want: local(23, 0, 0), allDay: true},
+        {in: "wednesday", want: local(21, 0, 0), allDay: true},
+        {in: "next wednesday", want: local(28, 0, 0), allDay: true},
+        {in: "next friday 18:00", want: local(23, 18, 0)},
+        {in: "в следующую пятницу", want: local(23, 0, 0), allDay: true},
+        {in: "в пн 10:00", want: local(26, 10, 0)},
+        {in: "", wantErr: true},
+        {in: "2026-13-01", wantErr: true},
+        {in: "31.02.2026", wantErr: true},
+        {in: "+3y", wantErr: true},
+        {in: "+99999d", wantErr: true},
+        {in: "next today", wantErr: true},
+        {in: "завтра 25:00", wantErr: true},
+        {in: "friday 18:00 sharp", wantErr: true},
+        {in: "когда-нибудь", wantErr: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.in, func(t *testing.T) {
+            got, err := Parse(tt.in, moscow, now)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("Parse(%q) = %+v, ожидалась ошибка", tt.in, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Parse(%q): %v", tt.in, err)
+            }
+            if !got.Time.Equal(tt.want) || got.AllDay != tt.allDay {
+                t.Fatalf("Parse(%q) = %v (весь день: %v), ожидалось %v (весь день: %v)", tt.in, got.Time, got.AllDay, tt.want, tt.allDay)
+            }
+            if got.Time.Location() != time.UTC {
+                t.Fatalf("Parse(%q): результат не в UTC", tt.in)
+            }
+        })
+    }
+}
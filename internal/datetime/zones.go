@@ -0,0 +1,81 @@
+package datetime
+
+import (
+    "fmt"
+    "sync"
+    "time"
+    "kanban-calendar/internal/models"
+)
+
+// Zones - пояс сервера и переопределения для пользователей (исполнителей) и чатов Telegram.
+// Безопасен для одновременного использования из обработчиков и планировщика
+type Zones struct {
+    mu    sync.RWMutex
+    def   *time.Location
+    users map[string]*time.Location
+    chats map[string]*time.Location
+}
+
+// NewZones - реестр с поясом по умолчанию
+func NewZones(def *time.Location) *Zones {
+    return &Zones{
+        def:   def,
+        users: map[string]*time.Location{},
+        chats: map[string]*time.Location{},
+    }
+}
+
+// Default - пояс сервера
+func (z *Zones) Default() *time.Location {
+    return z.def
+}
+
+// User - пояс исполнителя; без переопределения - пояс сервера
+func (z *Zones) User(name string) *time.Location {
+    return z.lookup(z.users, name)
+}
+
+// Chat - пояс чата Telegram; без переопределения - пояс сервера
+func (z *Zones) Chat(chatID string) *time.Location {
+    return z.lookup(z.chats, chatID)
+}
+
+func (z *Zones) lookup(m map[string]*time.Location, key string) *time.Location {
+    z.mu.RLock()
+    defer z.mu.RUnlock()
+    if loc, ok := m[key]; ok && key != "" {
+        return loc
+    }
+    return z.def
+}
+
+// Set - переопределяет пояс пользователя или чата; nil убирает переопределение
+func (z *Zones) Set(subjectType, subject string, loc *time.Location) error {
+    z.mu.Lock()
+    defer z.mu.Unlock()
+
+    var m map[string]*time.Location
+    switch subjectType {
+    case models.ZoneSubjectUser:
+        m = z.users
+    case models.ZoneSubjectChat:
+        m = z.chats
+    default:
+        return fmt.Errorf("неизвестный тип владельца пояса %q", subjectType)
+    }
+    if loc == nil {
+        delete(m, subject)
+    } else {
+        m[subject] = loc
+    }
+    return nil
+}
+
+// Apply - переопределение из БД
+func (z *Zones) Apply(tz models.TimeZone) error {
+    loc, err := LoadZone(tz.Zone)
+    if err != nil {
+        return err
+    }
+    return z.Set(tz.SubjectType, tz.Subject, loc)
+}
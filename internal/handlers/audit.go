@@ -0,0 +1,118 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+    "kanban-calendar/internal/datetime"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// RequestActor - изменения задач в запросе записываются в историю от имени X-User
+// (без заголовка - от имени repository.SystemActor)
+func RequestActor() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if user := requestUser(c); user != "" {
+            c.Request = c.Request.WithContext(repository.WithActor(c.Request.Context(), user))
+        }
+        c.Next()
+    }
+}
+
+// GetTaskHistory - история задачи от создания; доступна и для удаленных задач
+func GetTaskHistory(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        events, err := repo.GetTaskHistory(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения истории", "details": err.Error()})
+            return
+        }
+        if len(events) == 0 {
+            c.JSON(http.StatusNotFound, gin.H{"error": "История задачи не найдена"})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"task_id": id, "events": events, "count": len(events)})
+    }
+}
+
+// GetAudit - журнал изменений задач, новые первыми. Фильтры: task_id, board_id,
+// actor и action (несколько через запятую), field - менялось поле, from/to - даты в поясе запроса;
+// ?limit= и ?cursor= - next_cursor предыдущей страницы
+func GetAudit(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        filter := repository.AuditFilter{
+            Actors:  queryList(c, "actor"),
+            Actions: queryList(c, "action"),
+            Field:   c.Query("field"),
+        }
+
+        ints := []struct {
+            name   string
+            target *int
+        }{
+            {"task_id", &filter.TaskID}, {"board_id", &filter.BoardID}, {"limit", &filter.Limit},
+        }
+        for _, p := range ints {
+            value := c.Query(p.name)
+            if value == "" {
+                continue
+            }
+            n, err := strconv.Atoi(value)
+            if err != nil || n < 1 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Неверный параметр %s: нужно целое число больше 0", p.name)})
+                return
+            }
+            *p.target = n
+        }
+        if value := c.Query("cursor"); value != "" {
+            cursor, err := strconv.ParseInt(value, 10, 64)
+            if err != nil || cursor < 1 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный курсор страницы"})
+                return
+            }
+            filter.Cursor = cursor
+        }
+
+        ranges := []struct {
+            name   string
+            target **time.Time
+        }{
+            {"from", &filter.From}, {"to", &filter.To},
+        }
+        for _, r := range ranges {
+            value := c.Query(r.name)
+            if value == "" {
+                continue
+            }
+            t, err := datetime.ParseTime(value, zoneOf(c))
+            if err != nil {
+                c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Неверный параметр %s", r.name), "details": err.Error()})
+                return
+            }
+            *r.target = &t
+        }
+
+        events, next, err := repo.GetAudit(c.Request.Context(), filter)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения журнала", "details": err.Error()})
+            return
+        }
+
+        var nextCursor interface{} // null на последней странице
+        if next != 0 {
+            nextCursor = strconv.FormatInt(next, 10)
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "events":      events,
+            "count":       len(events),
+            "next_cursor": nextCursor,
+        })
+    }
+}
@@ -0,0 +1,369 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// GetBoards - список досок
+func GetBoards(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        boards, err := repo.GetBoards(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка получения досок",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if boards == nil {
+            boards = []models.Board{}
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "boards": boards,
+            "count":  len(boards),
+        })
+    }
+}
+
+// GetBoardByID - доска с колонками
+func GetBoardByID(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        board, err := repo.GetBoardByID(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Доска не найдена",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, board)
+    }
+}
+
+// CreateBoard - создает доску (без колонок в запросе - todo/in_progress/done)
+func CreateBoard(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.CreateBoardRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        columns := req.Columns
+        if len(columns) == 0 {
+            columns = models.DefaultColumns()
+        }
+        for _, col := range columns {
+            if !validColumnLimit(c, col.WIPLimit, col.WIPMode) {
+                return
+            }
+        }
+        
+        board := &models.Board{Name: req.Name, Description: req.Description}
+        if err := repo.CreateBoard(c.Request.Context(), board, columns); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка создания доски",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusCreated, board)
+    }
+}
+
+// UpdateBoard - обновляет название/описание доски
+func UpdateBoard(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        board, err := repo.GetBoardByID(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Доска не найдена",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        var req models.UpdateBoardRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if req.Name != "" {
+            board.Name = req.Name
+        }
+        if req.Description != nil {
+            board.Description = *req.Description
+        }
+        
+        if err := repo.UpdateBoard(c.Request.Context(), board); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка обновления доски",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, board)
+    }
+}
+
+// DeleteBoard - удаляет пустую доску
+func DeleteBoard(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        if err := repo.DeleteBoard(c.Request.Context(), id); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка удаления доски",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "message": "Доска успешно удалена",
+            "id":      id,
+        })
+    }
+}
+
+// GetBoardTasks - задачи доски
+func GetBoardTasks(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        tasks, err := repo.GetBoardTasks(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка получения задач",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if tasks == nil {
+            tasks = []models.Task{}
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "tasks":    tasks,
+            "count":    len(tasks),
+            "board_id": id,
+        })
+    }
+}
+
+// GetColumns - колонки доски
+func GetColumns(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        board, err := repo.GetBoardByID(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Доска не найдена",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        columns := board.Columns
+        if columns == nil {
+            columns = []models.BoardColumn{}
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "columns":  columns,
+            "count":    len(columns),
+            "board_id": id,
+        })
+    }
+}
+
+// CreateColumn - добавляет колонку на доску
+func CreateColumn(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+            return
+        }
+        
+        var req models.CreateColumnRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if !validColumnLimit(c, req.WIPLimit, req.WIPMode) {
+            return
+        }
+        
+        col, err := repo.CreateColumn(c.Request.Context(), id, req)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка создания колонки",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusCreated, col)
+    }
+}
+
+// UpdateColumn - обновляет колонку доски
+func UpdateColumn(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        col, ok := loadBoardColumn(c, repo)
+        if !ok {
+            return
+        }
+        
+        var req models.UpdateColumnRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if req.Name != "" {
+            col.Name = req.Name
+        }
+        if req.Slug != "" {
+            col.Slug = models.TaskStatus(req.Slug)
+        }
+        if req.Position != nil {
+            col.Position = *req.Position
+        }
+        if req.IsDone != nil {
+            col.IsDone = *req.IsDone
+        }
+        if req.Color != nil {
+            col.Color = *req.Color
+        }
+        if req.WIPLimit != nil {
+            col.WIPLimit = *req.WIPLimit
+        }
+        if req.WIPMode != "" {
+            col.WIPMode = req.WIPMode
+        }
+        if !validColumnLimit(c, col.WIPLimit, col.WIPMode) {
+            return
+        }
+        
+        if err := repo.UpdateColumn(c.Request.Context(), col); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка обновления колонки",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, col)
+    }
+}
+
+// DeleteColumn - удаляет пустую колонку
+func DeleteColumn(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        col, ok := loadBoardColumn(c, repo)
+        if !ok {
+            return
+        }
+        
+        if err := repo.DeleteColumn(c.Request.Context(), col.ID); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка удаления колонки",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "message": "Колонка успешно удалена",
+            "id":      col.ID,
+        })
+    }
+}
+
+// loadBoardColumn - читает :id и :column_id и проверяет, что колонка принадлежит доске
+func loadBoardColumn(c *gin.Context, repo *repository.TaskRepository) (*models.BoardColumn, bool) {
+    boardID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID доски"})
+        return nil, false
+    }
+    columnID, err := strconv.Atoi(c.Param("column_id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID колонки"})
+        return nil, false
+    }
+    
+    col, err := repo.ResolveColumn(c.Request.Context(), boardID, columnID, "")
+    if err != nil {
+        c.JSON(errorStatus(err), gin.H{
+            "error":   "Колонка не найдена",
+            "details": err.Error(),
+        })
+        return nil, false
+    }
+    return col, true
+}
+
+// validColumnLimit - проверяет WIP-лимит и режим колонки, при ошибке отвечает 400
+func validColumnLimit(c *gin.Context, limit int, mode string) bool {
+    if limit < 0 {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "WIP-лимит не может быть отрицательным"})
+        return false
+    }
+    if mode != "" && !models.ValidWIPMode(mode) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный режим WIP-лимита. Допустимые значения: reject, warn"})
+        return false
+    }
+    return true
+}
@@ -0,0 +1,567 @@
+package handlers
+
+import (
+    "crypto/sha1"
+    "encoding/hex"
+    "encoding/xml"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/caldav"
+    "kanban-calendar/internal/ical"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// Адреса CalDAV: принципал, домашняя коллекция, календарь на каждую доску
+const (
+    caldavRoot      = "/caldav/"
+    caldavCalendars = "/caldav/calendars/"
+    caldavSource    = "caldav" // источник задач, созданных CalDAV-клиентами
+    maxResourceSize = 1 << 20
+)
+
+// caldavMethods - методы, на которые отвечает CalDAV
+var caldavMethods = []string{"OPTIONS", "GET", "HEAD", "PUT", "DELETE", "PROPFIND", "PROPPATCH", "REPORT"}
+
+// davPath - разобранный путь под /caldav/
+type davPath struct {
+    home    bool   // /caldav/calendars/
+    boardID int    // /caldav/calendars/<board>/
+    name    string // /caldav/calendars/<board>/<name>
+}
+
+func parseDAVPath(path string) (davPath, bool) {
+    parts := strings.Split(strings.Trim(path, "/"), "/")
+    if parts[0] == "" {
+        return davPath{}, true // принципал
+    }
+    if parts[0] != "calendars" || len(parts) > 3 {
+        return davPath{}, false
+    }
+    if len(parts) == 1 {
+        return davPath{home: true}, true
+    }
+    boardID, err := strconv.Atoi(parts[1])
+    if err != nil {
+        return davPath{}, false
+    }
+    p := davPath{boardID: boardID}
+    if len(parts) == 3 {
+        p.name = parts[2]
+    }
+    return p, true
+}
+
+func collectionHref(boardID int) string {
+    return fmt.Sprintf("%s%d/", caldavCalendars, boardID)
+}
+
+func resourceHref(task *models.Task) string {
+    return collectionHref(task.BoardID) + url.PathEscape(repository.CalDAVResourceName(task))
+}
+
+// CalDAVWellKnown - /.well-known/caldav ведет на принципала
+func CalDAVWellKnown(c *gin.Context) {
+    c.Redirect(http.StatusMovedPermanently, caldavRoot)
+}
+
+// CalDAV - сервер CalDAV поверх задач: каждая доска - календарь, каждая задача - ресурс VEVENT или VTODO.
+// Изменения из клиента проходят ту же проверку, что и POST/PUT /api/tasks
+func CalDAV(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        p, ok := parseDAVPath(c.Param("path"))
+        if !ok {
+            c.String(http.StatusNotFound, "Ресурс не найден")
+            return
+        }
+
+        c.Header("DAV", "1, 3, calendar-access")
+        switch c.Request.Method {
+        case "OPTIONS":
+            c.Header("Allow", strings.Join(caldavMethods, ", "))
+            c.Status(http.StatusOK)
+        case "PROPFIND":
+            davPropfind(c, repo, p)
+        case "REPORT":
+            davReport(c, repo, p)
+        case "GET", "HEAD":
+            davGet(c, repo, p)
+        case "PUT":
+            davPut(c, repo, p)
+        case "DELETE":
+            davDelete(c, repo, p)
+        default:
+            c.String(http.StatusForbidden, "Свойства коллекций меняются через API досок")
+        }
+    }
+}
+
+// davResource - задача, сериализованная как ресурс календаря
+type davResource struct {
+    task *models.Task
+    body []byte
+    etag string
+}
+
+// newDAVResource - loc - пояс для дат на весь день (пояс запроса)
+func newDAVResource(task *models.Task, loc *time.Location) *davResource {
+    body := renderDAVResource(task, loc)
+    etag := body
+    if loc != time.UTC {
+        etag = renderDAVResource(task, time.UTC)
+    }
+    return &davResource{task: task, body: body, etag: davETag(etag)}
+}
+
+// renderDAVResource - задача как .ics одного ресурса
+func renderDAVResource(task *models.Task, loc *time.Location) []byte {
+    cal := ical.Export([]models.Task{*task}, ical.ExportOptions{
+        Component: ical.ComponentAuto,
+        Location:  loc,
+        Alarms:    ical.DefaultAlarms,
+        Resource:  true,
+    })
+    return []byte(cal.Serialize())
+}
+
+// davETag - ETag ресурса считается по выводу в UTC: у неизмененной задачи он один для клиентов в любых поясах
+func davETag(utcBody []byte) string {
+    sum := sha1.Sum(utcBody)
+    return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func (res *davResource) props() caldav.Props {
+    component := ical.ComponentOf(res.task)
+    return caldav.Props{
+        caldav.PropResourceType:     "",
+        caldav.PropGetETag:          caldav.Text(res.etag),
+        caldav.PropGetContentType:   caldav.Text("text/calendar; charset=utf-8; component=" + strings.ToLower(component)),
+        caldav.PropGetContentLength: strconv.Itoa(len(res.body)),
+        caldav.PropGetLastModified:  caldav.Text(res.task.UpdatedAt.UTC().Format(http.TimeFormat)),
+        caldav.PropCalendarData:     caldav.Text(string(res.body)),
+    }
+}
+
+// span - интервал ресурса для time-range, как его выгружает ical.Export
+func (res *davResource) span() (start, end *time.Time) {
+    task := res.task
+    if ical.ComponentOf(task) == string(ics.ComponentVTodo) {
+        return task.StartDate, task.Deadline
+    }
+    start, end = task.StartDate, task.EndDate
+    if start == nil {
+        start = task.Deadline
+    }
+    if start == nil {
+        start = &task.CreatedAt
+    }
+    if end == nil {
+        end = task.Deadline
+    }
+    return start, end
+}
+
+func principalProps() caldav.Props {
+    return caldav.Props{
+        caldav.PropResourceType:         caldav.Element(xmlName(caldav.NSDAV, "collection")) + caldav.Element(xmlName(caldav.NSDAV, "principal")),
+        caldav.PropDisplayName:          caldav.Text("Kanban"),
+        caldav.PropCurrentUserPrincipal: caldav.Href(caldavRoot),
+        caldav.PropPrincipalURL:         caldav.Href(caldavRoot),
+        caldav.PropCalendarHomeSet:      caldav.Href(caldavCalendars),
+    }
+}
+
+func homeProps() caldav.Props {
+    return caldav.Props{
+        caldav.PropResourceType:         caldav.Element(xmlName(caldav.NSDAV, "collection")),
+        caldav.PropDisplayName:          caldav.Text("Доски"),
+        caldav.PropCurrentUserPrincipal: caldav.Href(caldavRoot),
+        caldav.PropOwner:                caldav.Href(caldavRoot),
+    }
+}
+
+func collectionProps(board *models.Board, ctag string) caldav.Props {
+    comp := func(name string) string {
+        return caldav.Element(xmlName(caldav.NSCalDAV, "comp"), "name", name)
+    }
+    report := func(name string) string {
+        return "<D:supported-report><D:report>" + caldav.Element(xmlName(caldav.NSCalDAV, name)) + "</D:report></D:supported-report>"
+    }
+    return caldav.Props{
+        caldav.PropResourceType:          caldav.Element(xmlName(caldav.NSDAV, "collection")) + caldav.Element(xmlName(caldav.NSCalDAV, "calendar")),
+        caldav.PropDisplayName:           caldav.Text(board.Name),
+        caldav.PropCalendarDescription:   caldav.Text(board.Description),
+        caldav.PropSupportedComponentSet: comp("VEVENT") + comp("VTODO"),
+        caldav.PropSupportedReportSet:    report(caldav.ReportCalendarQuery) + report(caldav.ReportCalendarMultiget),
+        caldav.PropGetCTag:               caldav.Text(ctag),
+        caldav.PropGetETag:               caldav.Text(`"` + ctag + `"`),
+        caldav.PropCurrentUserPrincipal:  caldav.Href(caldavRoot),
+        caldav.PropOwner:                 caldav.Href(caldavRoot),
+    }
+}
+
+func xmlName(space, local string) xml.Name {
+    return xml.Name{Space: space, Local: local}
+}
+
+// davPropfind - свойства принципала, домашней коллекции, календаря доски или задачи (Depth: 0 или 1)
+func davPropfind(c *gin.Context, repo *repository.TaskRepository, p davPath) {
+    pf, err := caldav.ParsePropFind(c.Request.Body)
+    if err != nil {
+        c.String(http.StatusBadRequest, err.Error())
+        return
+    }
+    children := c.GetHeader("Depth") != "0"
+    ctx := c.Request.Context()
+    ms := &caldav.Multistatus{}
+
+    switch {
+    case p.home:
+        ms.Add(caldavCalendars, homeProps(), pf)
+        if children {
+            boards, err := repo.GetBoards(ctx)
+            if err != nil {
+                c.String(http.StatusInternalServerError, err.Error())
+                return
+            }
+            for i := range boards {
+                ctag, err := repo.CalendarCTag(ctx, boards[i].ID)
+                if err != nil {
+                    c.String(http.StatusInternalServerError, err.Error())
+                    return
+                }
+                ms.Add(collectionHref(boards[i].ID), collectionProps(&boards[i], ctag), pf)
+            }
+        }
+    case p.boardID == 0:
+        ms.Add(caldavRoot, principalProps(), pf)
+        if children {
+            ms.Add(caldavCalendars, homeProps(), pf)
+        }
+    case p.name == "":
+        board, err := repo.GetBoardByID(ctx, p.boardID)
+        if err != nil {
+            c.String(errorStatus(err), err.Error())
+            return
+        }
+        ctag, err := repo.CalendarCTag(ctx, board.ID)
+        if err != nil {
+            c.String(http.StatusInternalServerError, err.Error())
+            return
+        }
+        ms.Add(collectionHref(board.ID), collectionProps(board, ctag), pf)
+        if children {
+            resources, err := boardResources(c, repo, board.ID)
+            if err != nil {
+                c.String(http.StatusInternalServerError, err.Error())
+                return
+            }
+            for _, res := range resources {
+                ms.Add(resourceHref(res.task), res.props(), pf)
+            }
+        }
+    default:
+        task, err := repo.GetCalDAVTask(ctx, p.boardID, p.name)
+        if err != nil {
+            c.String(errorStatus(err), err.Error())
+            return
+        }
+        ms.Add(resourceHref(task), newDAVResource(task, zoneOf(c)).props(), pf)
+    }
+
+    c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", ms.Bytes())
+}
+
+func boardResources(c *gin.Context, repo *repository.TaskRepository, boardID int) ([]*davResource, error) {
+    tasks, err := repo.ListTasks(c.Request.Context(), repository.TaskFilter{BoardID: boardID})
+    if err != nil {
+        return nil, err
+    }
+    resources := make([]*davResource, 0, len(tasks))
+    for i := range tasks {
+        resources = append(resources, newDAVResource(&tasks[i], zoneOf(c)))
+    }
+    return resources, nil
+}
+
+// davReport - calendar-query (компонент и интервал) и calendar-multiget по списку href
+func davReport(c *gin.Context, repo *repository.TaskRepository, p davPath) {
+    if p.boardID == 0 || p.name != "" {
+        c.String(http.StatusForbidden, "REPORT выполняется на календаре доски")
+        return
+    }
+    report, err := caldav.ParseReport(c.Request.Body)
+    if err != nil {
+        status := http.StatusBadRequest
+        if errors.Is(err, caldav.ErrUnsupportedReport) {
+            status = http.StatusForbidden
+        }
+        c.String(status, err.Error())
+        return
+    }
+
+    ms := &caldav.Multistatus{}
+    switch report.Kind {
+    case caldav.ReportCalendarQuery:
+        resources, err := boardResources(c, repo, p.boardID)
+        if err != nil {
+            c.String(http.StatusInternalServerError, err.Error())
+            return
+        }
+        for _, res := range resources {
+            start, end := res.span()
+            if report.Filter.Match(ical.ComponentOf(res.task), start, end, res.task.RRule != "") {
+                ms.Add(resourceHref(res.task), res.props(), &report.PropFind)
+            }
+        }
+    case caldav.ReportCalendarMultiget:
+        for _, href := range report.Hrefs {
+            task := multigetTask(c, repo, href)
+            if task == nil {
+                ms.AddStatus(href, http.StatusNotFound)
+                continue
+            }
+            ms.Add(href, newDAVResource(task, zoneOf(c)).props(), &report.PropFind)
+        }
+    }
+
+    c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", ms.Bytes())
+}
+
+// multigetTask - задача по href из calendar-multiget (абсолютный URL или путь), nil - не найдена
+func multigetTask(c *gin.Context, repo *repository.TaskRepository, href string) *models.Task {
+    u, err := url.Parse(strings.TrimSpace(href))
+    if err != nil || !strings.HasPrefix(u.Path, caldavRoot) {
+        return nil
+    }
+    p, ok := parseDAVPath(strings.TrimPrefix(u.Path, caldavRoot))
+    if !ok || p.boardID == 0 || p.name == "" {
+        return nil
+    }
+    task, err := repo.GetCalDAVTask(c.Request.Context(), p.boardID, p.name)
+    if err != nil {
+        return nil
+    }
+    return task
+}
+
+// davGet - ресурс задачи или весь календарь доски
+func davGet(c *gin.Context, repo *repository.TaskRepository, p davPath) {
+    if p.boardID == 0 {
+        c.String(http.StatusOK, "Kanban Calendar CalDAV: календари досок в %s", caldavCalendars)
+        return
+    }
+    if p.name == "" {
+        c.Header("Content-Disposition", `inline; filename="tasks.ics"`)
+        writeCalendar(c, repo, repository.TaskFilter{BoardID: p.boardID}, "Kanban", zoneOf(c))
+        return
+    }
+
+    task, err := repo.GetCalDAVTask(c.Request.Context(), p.boardID, p.name)
+    if err != nil {
+        c.String(errorStatus(err), err.Error())
+        return
+    }
+    res := newDAVResource(task, zoneOf(c))
+    c.Header("ETag", res.etag)
+    c.Header("Last-Modified", task.UpdatedAt.UTC().Format(http.TimeFormat))
+    if match := c.GetHeader("If-None-Match"); match != "" && match == res.etag {
+        c.Status(http.StatusNotModified)
+        return
+    }
+    c.Data(http.StatusOK, "text/calendar; charset=utf-8", res.body)
+}
+
+// davPreconditions - If-Match / If-None-Match для PUT и DELETE (current = nil - ресурса нет)
+func davPreconditions(c *gin.Context, current *models.Task) bool {
+    ifMatch, ifNoneMatch := c.GetHeader("If-Match"), c.GetHeader("If-None-Match")
+    switch {
+    case ifNoneMatch == "*" && current != nil:
+    case ifMatch != "" && current == nil:
+    case ifMatch != "" && ifMatch != "*" && ifMatch != davETag(renderDAVResource(current, time.UTC)):
+    default:
+        return true
+    }
+    c.String(http.StatusPreconditionFailed, "Ресурс изменился, обновите календарь")
+    return false
+}
+
+// davPut - создает или обновляет задачу из VEVENT/VTODO. ETag в ответе не отдается: сервер
+// нормализует ресурс (колонка, теги), и клиент должен перечитать его
+func davPut(c *gin.Context, repo *repository.TaskRepository, p davPath) {
+    if p.name == "" {
+        c.String(http.StatusMethodNotAllowed, "PUT выполняется на ресурсе задачи")
+        return
+    }
+    ctx := c.Request.Context()
+    board, err := repo.GetBoardByID(ctx, p.boardID)
+    if err != nil {
+        c.String(errorStatus(err), err.Error())
+        return
+    }
+
+    current, err := repo.GetCalDAVTask(ctx, board.ID, p.name)
+    if err != nil && !errors.Is(err, repository.ErrNotFound) {
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+    if !davPreconditions(c, current) {
+        return
+    }
+
+    cal, err := ics.ParseCalendar(http.MaxBytesReader(c.Writer, c.Request.Body, maxResourceSize))
+    if err != nil {
+        c.String(http.StatusBadRequest, "Ошибка формата .ics: %v", err)
+        return
+    }
+    res, err := ical.ParseResource(cal, zoneOf(c))
+    if err != nil {
+        c.String(http.StatusBadRequest, err.Error())
+        return
+    }
+    columns, err := repo.GetColumns(ctx, board.ID)
+    if err != nil {
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+
+    if current == nil {
+        davCreate(c, repo, board.ID, p.name, res, columns)
+    } else {
+        davUpdate(c, repo, current, res, columns)
+    }
+}
+
+func davCreate(c *gin.Context, repo *repository.TaskRepository, boardID int, name string, res *ical.Resource, columns []models.BoardColumn) {
+    ctx := c.Request.Context()
+    incoming := res.Task
+    if strings.TrimSpace(incoming.Title) == "" {
+        c.String(http.StatusBadRequest, "У задачи нет SUMMARY")
+        return
+    }
+
+    // Тот же UID под другим именем - клиент пытается создать задачу второй раз
+    existing, err := repo.GetCalDAVTaskByUID(ctx, boardID, incoming.ExternalUID, ical.TaskIDFromUID(incoming.ExternalUID))
+    switch {
+    case err == nil:
+        c.String(http.StatusConflict, "UID уже используется ресурсом %s", resourceHref(existing))
+        return
+    case !errors.Is(err, repository.ErrNotFound):
+        c.String(http.StatusInternalServerError, err.Error())
+        return
+    }
+
+    req := taskRequest(incoming)
+    req.Status = ical.StatusColumn(columns, incoming.Status, res.Status)
+    req.BoardID = boardID
+
+    task, reqErr := newTask(ctx, repo, req, zoneOf(c))
+    if reqErr != nil {
+        c.String(reqErr.status, reqErr.Error())
+        return
+    }
+    task.ExternalUID = incoming.ExternalUID
+    task.ExternalSource = caldavSource
+    task.ExternalPriority = incoming.ExternalPriority
+    task.ExternalPercent = incoming.ExternalPercent
+    task.CalDAVName = name
+    task.CalDAVComponent = res.Component
+
+    if err := repo.CreateTask(ctx, task); err != nil {
+        davSaveError(c, err)
+        return
+    }
+    c.Header("Location", resourceHref(task))
+    c.Status(http.StatusCreated)
+}
+
+func davUpdate(c *gin.Context, repo *repository.TaskRepository, task *models.Task, res *ical.Resource, columns []models.BoardColumn) {
+    ctx := c.Request.Context()
+    incoming := res.Task
+
+    slug := incoming.Status
+    if slug == "" {
+        slug = task.Status
+    }
+    // Ресурс заменяет задачу целиком: поля, которые клиент стер, очищаются. Исключение - свойства
+    // канбана, которые большинство клиентов не сохраняет: без X-KANBAN-ASSIGNEE и PRIORITY
+    // исполнитель и приоритет остаются прежними
+    req := taskRequest(incoming)
+    if !res.HasAssignee {
+        req.Assignee = task.Assignee
+    }
+    if incoming.ExternalPriority == 0 {
+        req.Priority = task.Priority
+        incoming.ExternalPriority = task.ExternalPriority
+    }
+    req.Status = ical.StatusColumn(columns, slug, res.Status)
+    req.BoardID = task.BoardID
+    req.ParentID = task.ParentID // подзадачи в календаре не видны - связь остается как в канбане
+    if req.Status == "" {
+        req.ColumnID = task.ColumnID
+    }
+
+    updated, reqErr := replaceTask(ctx, repo, task, req, zoneOf(c))
+    if reqErr != nil {
+        c.String(reqErr.status, reqErr.Error())
+        return
+    }
+    updated.ExternalPriority = incoming.ExternalPriority
+    updated.ExternalPercent = incoming.ExternalPercent
+    // Версия, которую проверил davPreconditions: правка между проверкой и записью дает 412
+    updated.Version = task.Version
+    if err := repo.UpdateTask(ctx, updated); err != nil {
+        davSaveError(c, err)
+        return
+    }
+    if task.CalDAVComponent != res.Component {
+        if err := repo.SetCalDAVResource(ctx, task.ID, task.CalDAVName, res.Component); err != nil {
+            c.String(errorStatus(err), err.Error())
+            return
+        }
+    }
+    c.Status(http.StatusNoContent)
+}
+
+// davSaveError - ошибка сохранения задачи; WIP-лимит - 409, как и в JSON API
+func davSaveError(c *gin.Context, err error) {
+    var wipErr *models.WIPLimitError
+    if errors.As(err, &wipErr) {
+        c.String(http.StatusConflict, "Превышен WIP-лимит колонки: %s", wipErr.Error())
+        return
+    }
+    var blockedErr *models.BlockedError
+    if errors.As(err, &blockedErr) {
+        c.String(http.StatusConflict, "Задачу нельзя начать: %s", blockedErr.Error())
+        return
+    }
+    c.String(errorStatus(err), err.Error())
+}
+
+// davDelete - удаляет задачу; коллекции досок удаляются только через API
+func davDelete(c *gin.Context, repo *repository.TaskRepository, p davPath) {
+    if p.name == "" {
+        c.String(http.StatusForbidden, "Доски удаляются через /api/boards")
+        return
+    }
+    task, err := repo.GetCalDAVTask(c.Request.Context(), p.boardID, p.name)
+    if err != nil {
+        c.String(errorStatus(err), err.Error())
+        return
+    }
+    if !davPreconditions(c, task) {
+        return
+    }
+    if err := repo.DeleteTask(c.Request.Context(), task.ID, task.Version); err != nil {
+        c.String(errorStatus(err), err.Error())
+        return
+    }
+    c.Status(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package handlers
 import (
     "net/http"
     "time"
+    "kanban-calendar/internal/datetime"
     "kanban-calendar/internal/models"
     "kanban-calendar/internal/repository"
     "github.com/gin-gonic/gin"
@@ -15,8 +16,8 @@ func GetCalendarEvents(repo *repository.TaskRepository) gin.HandlerFunc {
         startStr := c.DefaultQuery("start", time.Now().AddDate(0, -1, 0).Format(time.RFC3339))
         endStr := c.DefaultQuery("end", time.Now().AddDate(0, 1, 0).Format(time.RFC3339))
         
-        // Парс дат
-        start, err := time.Parse(time.RFC3339, startStr)
+        // Парс дат: RFC3339 или местное время пояса запроса
+        start, err := datetime.ParseTime(startStr, zoneOf(c))
         if err != nil {
             c.JSON(http.StatusBadRequest, gin.H{
                 "error":   "Неверный формат даты начала",
@@ -26,7 +27,7 @@ func GetCalendarEvents(repo *repository.TaskRepository) gin.HandlerFunc {
             return
         }
         
-        end, err := time.Parse(time.RFC3339, endStr)
+        end, err := datetime.ParseTime(endStr, zoneOf(c))
         if err != nil {
             c.JSON(http.StatusBadRequest, gin.H{
                 "error":   "Неверный формат даты окончания",
@@ -44,8 +45,14 @@ func GetCalendarEvents(repo *repository.TaskRepository) gin.HandlerFunc {
             return
         }
         
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+
         // Получаем события из БД
-        events, err := repo.GetCalendarEvents(c.Request.Context(), start, end)
+        events, err := repo.GetCalendarEvents(c.Request.Context(), start, end, filter)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error":   "Ошибка получения событий календаря",
@@ -58,11 +65,31 @@ func GetCalendarEvents(repo *repository.TaskRepository) gin.HandlerFunc {
             events = []models.CalendarEvent{}
         }
         
+        // Ребра зависимостей для диаграммы Ганта: только между задачами из ответа
+        seen := map[int]bool{}
+        var ids []int
+        for _, event := range events {
+            if !seen[event.ID] {
+                seen[event.ID] = true
+                ids = append(ids, event.ID)
+            }
+        }
+        deps, err := repo.GetDependencies(c.Request.Context(), ids)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка получения зависимостей",
+                "details": err.Error(),
+            })
+            return
+        }
+        
         c.JSON(http.StatusOK, gin.H{
-            "events": events,
-            "count":  len(events),
-            "start":  start.Format(time.RFC3339),
-            "end":    end.Format(time.RFC3339),
+            "events":       events,
+            "dependencies": deps,
+            "count":        len(events),
+            "start":        start.Format(time.RFC3339),
+            "end":          end.Format(time.RFC3339),
+            "zone":         zoneOf(c).String(),
         })
     }
 }
\ No newline at end of file
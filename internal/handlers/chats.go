@@ -0,0 +1,62 @@
+package handlers
+
+import (
+    "net/http"
+    "strings"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// GetTelegramChats - пользователи с привязанными чатами Telegram
+func GetTelegramChats(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        chats, err := repo.GetTelegramChats(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"chats": chats, "count": len(chats)})
+    }
+}
+
+// SetTelegramChat - привязывает к пользователю личный чат с ботом: PUT /api/telegram/chats/<пользователь>
+func SetTelegramChat(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        user := strings.TrimSpace(c.Param("user"))
+        if user == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан пользователь"})
+            return
+        }
+
+        var req models.SetTelegramChatRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+        chatID := strings.TrimSpace(req.ChatID)
+        if chatID == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан ID чата"})
+            return
+        }
+
+        chat := &models.TelegramChat{User: user, ChatID: chatID}
+        if err := repo.SetTelegramChat(c.Request.Context(), chat); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, chat)
+    }
+}
+
+// DeleteTelegramChat - отвязывает чат пользователя
+func DeleteTelegramChat(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        user := strings.TrimSpace(c.Param("user"))
+        if err := repo.DeleteTelegramChat(c.Request.Context(), user); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Чат отвязан", "user": user})
+    }
+}
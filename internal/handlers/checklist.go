@@ -0,0 +1,140 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// maxChecklistText - длина текста пункта чек-листа (как у колонки в БД)
+const maxChecklistText = 500
+
+// GetSubtasks - подзадачи задачи
+func GetSubtasks(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        task, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        subtasks, err := repo.GetSubtasks(c.Request.Context(), task.ID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения подзадач", "details": err.Error()})
+            return
+        }
+        if subtasks == nil {
+            subtasks = []models.Task{}
+        }
+        c.JSON(http.StatusOK, gin.H{"tasks": subtasks, "count": len(subtasks), "progress": task.Progress})
+    }
+}
+
+// GetChecklist - чек-лист задачи
+func GetChecklist(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        task, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        items := task.Checklist
+        if items == nil {
+            items = []models.ChecklistItem{}
+        }
+        c.JSON(http.StatusOK, gin.H{"items": items, "count": len(items), "progress": task.Progress})
+    }
+}
+
+// checklistRequest - тело создания или изменения пункта; create - текст обязателен
+func checklistRequest(c *gin.Context, create bool) (models.ChecklistItemRequest, bool) {
+    var req models.ChecklistItemRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+        return req, false
+    }
+    if req.Text != nil {
+        text := strings.TrimSpace(*req.Text)
+        req.Text = &text
+    }
+    switch {
+    case req.Text == nil && create, req.Text != nil && *req.Text == "":
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан текст пункта"})
+        return req, false
+    case req.Text != nil && len([]rune(*req.Text)) > maxChecklistText:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Текст пункта длиннее 500 символов"})
+        return req, false
+    }
+    return req, true
+}
+
+// checklistIDs - ID задачи и пункта из пути
+func checklistIDs(c *gin.Context) (int, int, bool) {
+    taskID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+        return 0, 0, false
+    }
+    itemID, err := strconv.Atoi(c.Param("item_id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID пункта"})
+        return 0, 0, false
+    }
+    return taskID, itemID, true
+}
+
+// CreateChecklistItem - добавляет пункт в чек-лист задачи
+func CreateChecklistItem(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        req, ok := checklistRequest(c, true)
+        if !ok {
+            return
+        }
+        item, err := repo.CreateChecklistItem(c.Request.Context(), taskID, req)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка добавления пункта", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusCreated, item)
+    }
+}
+
+// UpdateChecklistItem - меняет текст, отметку или место пункта
+func UpdateChecklistItem(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, itemID, ok := checklistIDs(c)
+        if !ok {
+            return
+        }
+        req, ok := checklistRequest(c, false)
+        if !ok {
+            return
+        }
+        item, err := repo.UpdateChecklistItem(c.Request.Context(), taskID, itemID, req)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка изменения пункта", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, item)
+    }
+}
+
+// DeleteChecklistItem - удаляет пункт из чек-листа
+func DeleteChecklistItem(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, itemID, ok := checklistIDs(c)
+        if !ok {
+            return
+        }
+        if err := repo.DeleteChecklistItem(c.Request.Context(), taskID, itemID); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка удаления пункта", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Пункт удален", "id": itemID})
+    }
+}
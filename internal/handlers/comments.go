@@ -0,0 +1,191 @@
+package handlers
+
+import (
+    "context"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "kanban-calendar/telegram"
+    "github.com/gin-gonic/gin"
+)
+
+// maxCommentBody - длина комментария в символах
+const maxCommentBody = 10000
+
+// GetComments - комментарии задачи
+func GetComments(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        task, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        comments, err := repo.GetComments(c.Request.Context(), task.ID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения комментариев", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"comments": comments, "count": len(comments)})
+    }
+}
+
+// GetComment - комментарий по ID
+func GetComment(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, commentID, ok := commentIDs(c)
+        if !ok {
+            return
+        }
+        comment, err := repo.GetComment(c.Request.Context(), taskID, commentID)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Комментарий не найден", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, comment)
+    }
+}
+
+// CreateComment - добавляет комментарий от имени X-User и уведомляет упомянутых
+func CreateComment(repo *repository.TaskRepository, bot *telegram.TelegramBot) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        author, body, ok := commentRequest(c)
+        if !ok {
+            return
+        }
+        comment, notifications, err := repo.CreateComment(c.Request.Context(), taskID, author, body)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка добавления комментария", "details": err.Error()})
+            return
+        }
+        sendMentions(repo, bot, notifications)
+        c.JSON(http.StatusCreated, comment)
+    }
+}
+
+// UpdateComment - меняет текст своего комментария; прежний текст остается в истории правок
+func UpdateComment(repo *repository.TaskRepository, bot *telegram.TelegramBot) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, commentID, ok := commentIDs(c)
+        if !ok {
+            return
+        }
+        editor, body, ok := commentRequest(c)
+        if !ok {
+            return
+        }
+        comment, notifications, err := repo.UpdateComment(c.Request.Context(), taskID, commentID, editor, body)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка изменения комментария", "details": err.Error()})
+            return
+        }
+        sendMentions(repo, bot, notifications)
+        c.JSON(http.StatusOK, comment)
+    }
+}
+
+// DeleteComment - удаляет свой комментарий
+func DeleteComment(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, commentID, ok := commentIDs(c)
+        if !ok {
+            return
+        }
+        user := requestUser(c)
+        if user == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите заголовок X-User"})
+            return
+        }
+        if err := repo.DeleteComment(c.Request.Context(), taskID, commentID, user); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка удаления комментария", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Комментарий удален", "id": commentID})
+    }
+}
+
+// GetCommentHistory - текущий текст комментария и его прежние версии
+func GetCommentHistory(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        taskID, commentID, ok := commentIDs(c)
+        if !ok {
+            return
+        }
+        comment, err := repo.GetComment(c.Request.Context(), taskID, commentID)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Комментарий не найден", "details": err.Error()})
+            return
+        }
+        revisions, err := repo.GetCommentRevisions(c.Request.Context(), taskID, commentID)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка получения истории правок", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"comment": comment, "revisions": revisions, "count": len(revisions)})
+    }
+}
+
+// commentRequest - автор из X-User и текст комментария из тела
+func commentRequest(c *gin.Context) (string, string, bool) {
+    user := requestUser(c)
+    if user == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Комментарии подписываются: укажите заголовок X-User"})
+        return "", "", false
+    }
+    var req models.CommentRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+        return "", "", false
+    }
+    body := strings.TrimSpace(req.Body)
+    switch {
+    case body == "":
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Пустой комментарий"})
+        return "", "", false
+    case len([]rune(body)) > maxCommentBody:
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Комментарий длиннее 10000 символов"})
+        return "", "", false
+    }
+    return user, body, true
+}
+
+// commentIDs - ID задачи и комментария из пути
+func commentIDs(c *gin.Context) (int, int, bool) {
+    taskID, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+        return 0, 0, false
+    }
+    commentID, err := strconv.Atoi(c.Param("comment_id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID комментария"})
+        return 0, 0, false
+    }
+    return taskID, commentID, true
+}
+
+// sendMentions - отправляет уведомления об упоминаниях в фоне, не задерживая ответ.
+// Неотправленные остаются в notifications, их повторяет планировщик
+func sendMentions(repo *repository.TaskRepository, bot *telegram.TelegramBot, notifications []models.Notification) {
+    if bot == nil || len(notifications) == 0 {
+        return
+    }
+    go func() {
+        ctx := context.Background()
+        for _, n := range notifications {
+            if err := bot.SendMention(n); err != nil {
+                log.Printf("Ошибка отправки упоминания %d в TG: %v", n.ID, err)
+                continue
+            }
+            if err := repo.MarkNotificationSent(ctx, n.ID); err != nil {
+                log.Printf("Ошибка отметки уведомления %d: %v", n.ID, err)
+            }
+        }
+    }()
+}
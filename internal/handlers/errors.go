@@ -0,0 +1,76 @@
+package handlers
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// errorStatus - HTTP-код для ошибки репозитория
+func errorStatus(err error) int {
+    switch {
+    case errors.Is(err, repository.ErrNotFound):
+        return http.StatusNotFound
+    case errors.Is(err, repository.ErrConflict):
+        return http.StatusConflict
+    case errors.Is(err, repository.ErrVersionMismatch):
+        return http.StatusPreconditionFailed
+    case errors.Is(err, repository.ErrBadCursor):
+        return http.StatusBadRequest
+    case errors.Is(err, repository.ErrInvalidRelation):
+        return http.StatusUnprocessableEntity
+    case errors.Is(err, repository.ErrForbidden):
+        return http.StatusForbidden
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+// requestError - запрос не прошел проверку: HTTP-код и тело JSON-ответа
+type requestError struct {
+    status int
+    body   gin.H
+}
+
+func (e *requestError) Error() string {
+    if details, ok := e.body["details"]; ok {
+        return fmt.Sprintf("%v: %v", e.body["error"], details)
+    }
+    return fmt.Sprint(e.body["error"])
+}
+
+// respondWIPLimit - отвечает 409 с описанием переполненной колонки, если err - превышение WIP-лимита
+func respondWIPLimit(c *gin.Context, err error) bool {
+    var wipErr *models.WIPLimitError
+    if !errors.As(err, &wipErr) {
+        return false
+    }
+    
+    c.JSON(http.StatusConflict, gin.H{
+        "error":   "Превышен WIP-лимит колонки",
+        "code":    "wip_limit_exceeded",
+        "details": wipErr.Error(),
+        "wip":     wipErr.WIPViolation,
+    })
+    return true
+}
+
+// respondBlocked - отвечает 409 со списком блокирующих задач, если err - попытка начать заблокированную задачу
+func respondBlocked(c *gin.Context, err error) bool {
+    var blockedErr *models.BlockedError
+    if !errors.As(err, &blockedErr) {
+        return false
+    }
+    
+    c.JSON(http.StatusConflict, gin.H{
+        "error":    "Задачу блокируют незавершенные задачи",
+        "code":     "task_blocked",
+        "details":  blockedErr.Error(),
+        "blockers": blockedErr.Blockers,
+        "hint":     "Чтобы начать задачу все равно, повторите запрос с force",
+    })
+    return true
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+    "kanban-calendar/internal/datetime"
+    "kanban-calendar/internal/ical"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// ExportCalendar - выгрузка задач в .ics (фильтры как у списка задач + start, end, type)
+func ExportCalendar(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        c.Header("Content-Disposition", `attachment; filename="tasks.ics"`)
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+        writeCalendar(c, repo, filter, "Kanban", zoneOf(c))
+    }
+}
+
+// GetFeed - подписываемая лента по токену (/api/calendar/feed/<token>.ics); исполнитель задан лентой,
+// и даты на весь день выгружаются в его поясе
+func GetFeed(repo *repository.TaskRepository, zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        token := strings.TrimSuffix(c.Param("token"), ".ics")
+        feed, err := repo.GetFeedByToken(c.Request.Context(), token)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Лента не найдена"})
+            return
+        }
+
+        loc, err := resolveZone(c, zones, feed.Assignee)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+        if feed.Assignee != "" {
+            filter.Assignees = []string{feed.Assignee}
+        }
+        writeCalendar(c, repo, filter, feed.Name, loc)
+    }
+}
+
+// writeCalendar - общая часть выгрузки: период, тип компонентов и сериализация в поясе loc
+func writeCalendar(c *gin.Context, repo *repository.TaskRepository, filter repository.TaskFilter, name string, loc *time.Location) {
+    params := []struct {
+        name   string
+        target **time.Time
+    }{{"start", &filter.From}, {"end", &filter.To}}
+    for _, param := range params {
+        value := c.Query(param.name)
+        if value == "" {
+            continue
+        }
+        t, err := datetime.ParseTime(value, loc)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   fmt.Sprintf("Неверный формат параметра %s", param.name),
+                "details": err.Error(),
+                "example": "2024-01-01T00:00:00Z",
+            })
+            return
+        }
+        *param.target = &t
+    }
+
+    component := c.DefaultQuery("type", ical.ComponentAuto)
+    if !ical.ValidComponent(component) {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "type должен быть auto, event или todo"})
+        return
+    }
+
+    tasks, err := repo.ListTasks(c.Request.Context(), filter)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения задач", "details": err.Error()})
+        return
+    }
+
+    cal := ical.Export(tasks, ical.ExportOptions{
+        Name:      name,
+        Component: component,
+        Location:  loc,
+        Alarms:    ical.DefaultAlarms,
+    })
+    c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(cal.Serialize()))
+}
+
+// feedURL - полный адрес ленты для подписки в календаре
+func feedURL(c *gin.Context, token string) string {
+    scheme := "http"
+    if c.Request.TLS != nil {
+        scheme = "https"
+    }
+    if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+        scheme = proto
+    }
+    return fmt.Sprintf("%s://%s/api/calendar/feed/%s.ics", scheme, c.Request.Host, token)
+}
+
+// GetFeeds - список лент (?assignee=)
+func GetFeeds(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        feeds, err := repo.GetFeeds(c.Request.Context(), c.Query("assignee"))
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        for i := range feeds {
+            feeds[i].URL = feedURL(c, feeds[i].Token)
+        }
+        c.JSON(http.StatusOK, gin.H{"feeds": feeds, "count": len(feeds)})
+    }
+}
+
+// CreateFeed - создает ленту и возвращает ее URL
+func CreateFeed(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.CreateFeedRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+
+        feed := &models.CalendarFeed{Name: req.Name, Assignee: strings.TrimSpace(req.Assignee)}
+        if err := repo.CreateFeed(c.Request.Context(), feed); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        feed.URL = feedURL(c, feed.Token)
+        c.JSON(http.StatusCreated, feed)
+    }
+}
+
+// DeleteFeed - отзывает ленту
+func DeleteFeed(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID ленты"})
+            return
+        }
+        if err := repo.DeleteFeed(c.Request.Context(), id); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Лента удалена"})
+    }
+}
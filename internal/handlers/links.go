@@ -0,0 +1,77 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// GetTaskLinks - связи задачи: блокирующие, зависящие, связанные и дубликаты
+func GetTaskLinks(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        task, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        links, err := repo.GetTaskLinks(c.Request.Context(), task.ID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения связей", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, links)
+    }
+}
+
+// CreateLink - связывает задачу из пути с task_id; тип задается со стороны задачи из пути
+func CreateLink(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        var req models.CreateLinkRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+        source, target, linkType, ok := repository.NormalizeLink(id, req.TaskID, req.Type)
+        if !ok {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error": "Неизвестный тип связи",
+                "types": []string{models.LinkBlocks, models.LinkBlockedBy, models.LinkRelatesTo, models.LinkDuplicates, models.LinkDuplicatedBy},
+            })
+            return
+        }
+
+        link := &models.TaskLink{SourceID: source, TargetID: target, Type: linkType}
+        if err := repo.CreateLink(c.Request.Context(), link); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка создания связи", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusCreated, link)
+    }
+}
+
+// DeleteLink - удаляет связь задачи
+func DeleteLink(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        linkID, err := strconv.Atoi(c.Param("link_id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID связи"})
+            return
+        }
+        if err := repo.DeleteLink(c.Request.Context(), id, linkID); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка удаления связи", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Связь удалена", "id": linkID})
+    }
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "time"
+    "kanban-calendar/internal/models"
+    "github.com/gin-gonic/gin"
+    "github.com/gin-gonic/gin/binding"
+)
+
+// mergePatch - JSON Merge Patch (RFC 7386): null удаляет ключ, объекты сливаются рекурсивно,
+// остальные значения (в том числе массивы) заменяются целиком
+func mergePatch(target interface{}, patch interface{}) interface{} {
+    patchObj, ok := patch.(map[string]interface{})
+    if !ok {
+        return patch
+    }
+    targetObj, ok := target.(map[string]interface{})
+    if !ok {
+        targetObj = map[string]interface{}{}
+    }
+    for key, value := range patchObj {
+        if value == nil {
+            delete(targetObj, key)
+            continue
+        }
+        targetObj[key] = mergePatch(targetObj[key], value)
+    }
+    return targetObj
+}
+
+// taskRequest - задача в виде тела создания: то, что видит и меняет PATCH
+func taskRequest(task *models.Task) models.CreateTaskRequest {
+    date := func(t *time.Time) string {
+        if t == nil {
+            return ""
+        }
+        return t.UTC().Format(time.RFC3339)
+    }
+    req := models.CreateTaskRequest{
+        Title:       task.Title,
+        Description: task.Description,
+        Status:      task.Status,
+        Priority:    task.Priority,
+        Deadline:    date(task.Deadline),
+        StartDate:   date(task.StartDate),
+        EndDate:     date(task.EndDate),
+        Assignee:    task.Assignee,
+        Tags:        task.Tags,
+        BoardID:     task.BoardID,
+        ColumnID:    task.ColumnID,
+        RRule:       task.RRule,
+        AllDay:      task.AllDay,
+        ParentID:    task.ParentID,
+    }
+    for _, ex := range task.ExDates {
+        req.ExDates = append(req.ExDates, date(&ex))
+    }
+    return req
+}
+
+// patchTaskRequest - применяет merge patch к текущей задаче и проверяет результат как тело создания
+func patchTaskRequest(current *models.Task, body []byte) (*models.CreateTaskRequest, *requestError) {
+    var patch map[string]interface{}
+    if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+        return nil, &requestError{http.StatusBadRequest, gin.H{
+            "error": "Тело PATCH должно быть JSON-объектом (application/merge-patch+json)",
+        }}
+    }
+
+    raw, err := json.Marshal(taskRequest(current))
+    if err != nil {
+        return nil, &requestError{http.StatusInternalServerError, gin.H{"error": err.Error()}}
+    }
+    var doc map[string]interface{}
+    if err := json.Unmarshal(raw, &doc); err != nil {
+        return nil, &requestError{http.StatusInternalServerError, gin.H{"error": err.Error()}}
+    }
+
+    // column_id приоритетнее status: текущая колонка не должна перекрыть перенос по статусу или на другую доску
+    _, hasColumn := patch["column_id"]
+    _, hasStatus := patch["status"]
+    board, hasBoard := patch["board_id"]
+    boardMoved := hasBoard && board != float64(current.BoardID)
+    if !hasColumn && (hasStatus || boardMoved) {
+        delete(doc, "column_id")
+    }
+    if !hasColumn && !hasStatus && boardMoved {
+        delete(doc, "status") // на другой доске - первая колонка, как при создании
+    }
+
+    merged, err := json.Marshal(mergePatch(doc, patch))
+    if err != nil {
+        return nil, &requestError{http.StatusInternalServerError, gin.H{"error": err.Error()}}
+    }
+    var req models.CreateTaskRequest
+    if err := json.Unmarshal(merged, &req); err != nil {
+        return nil, &requestError{http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()}}
+    }
+    if err := binding.Validator.ValidateStruct(&req); err != nil {
+        return nil, &requestError{http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()}}
+    }
+    return &req, nil
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "reflect"
+    "testing"
+    "time"
+    "kanban-calendar/internal/models"
+)
+
+func TestMergePatch(t *testing.T) {
+    // Примеры из приложения A RFC 7386
+    tests := []struct {
+        target, patch, want string
+    }{
+        {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+        {`{"a":"b"}`, `{"a":null}`, `{}`},
+        {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+        {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+        {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+        {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+        {`{"a":"b"}`, `["c"]`, `["c"]`},
+        {`{"a":"foo"}`, `null`, `null`},
+        {`{"a":"foo"}`, `"bar"`, `"bar"`},
+        {`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+        {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+        {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+            var target, patch, want interface{}
+            for _, v := range []struct {
+                src string
+                dst *interface{}
+            }{{tt.target, &target}, {tt.patch, &patch}, {tt.want, &want}} {
+                if err := json.Unmarshal([]byte(v.src), v.dst); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            if got := mergePatch(target, patch); !reflect.DeepEqual(got, want) {
+                t.Fatalf("mergePatch = %v, ожидалось %v", got, want)
+            }
+        })
+    }
+}
+
+func TestPatchTaskRequest(t *testing.T) {
+    deadline := time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)
+    current := &models.Task{
+        Title:    "Отчет",
+        Status:   "todo",
+        Priority: "high",
+        Assignee: "anna",
+        Tags:     []string{"backend", "q1"},
+        Deadline: &deadline,
+        BoardID:  1,
+        ColumnID: 2,
+    }
+
+    tests := []struct {
+        name   string
+        body   string
+        want   func(req *models.CreateTaskRequest)
+        status int // 0 - успех
+    }{
+        {
+            name: "меняется только указанное поле",
+            body: `{"title":"Квартальный отчет"}`,
+            want: func(req *models.CreateTaskRequest) { req.Title = "Квартальный отчет" },
+        },
+        {
+            name: "null стирает поле",
+            body: `{"assignee":null,"deadline":null}`,
+            want: func(req *models.CreateTaskRequest) { req.Assignee, req.Deadline = "", "" },
+        },
+        {
+            name: "массив заменяется целиком",
+            body: `{"tags":["frontend"]}`,
+            want: func(req *models.CreateTaskRequest) { req.Tags = []string{"frontend"} },
+        },
+        {
+            name: "статус без колонки - колонка по статусу",
+            body: `{"status":"done"}`,
+            want: func(req *models.CreateTaskRequest) { req.Status, req.ColumnID = "done", 0 },
+        },
+        {
+            name: "колонка приоритетнее статуса",
+            body: `{"status":"done","column_id":5}`,
+            want: func(req *models.CreateTaskRequest) { req.Status, req.ColumnID = "done", 5 },
+        },
+        {
+            name: "другая доска - первая колонка",
+            body: `{"board_id":3}`,
+            want: func(req *models.CreateTaskRequest) { req.BoardID, req.Status, req.ColumnID = 3, "", 0 },
+        },
+        {
+            name: "та же доска - колонка остается",
+            body: `{"board_id":1}`,
+        },
+        {name: "не объект", body: `["title"]`, status: http.StatusBadRequest},
+        {name: "null вместо объекта", body: `null`, status: http.StatusBadRequest},
+        {name: "не JSON", body: `title=x`, status: http.StatusBadRequest},
+        {name: "стерт обязательный заголовок", body: `{"title":null}`, status: http.StatusBadRequest},
+        {name: "неверный тип", body: `{"board_id":"main"}`, status: http.StatusBadRequest},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, reqErr := patchTaskRequest(current, []byte(tt.body))
+            if tt.status != 0 {
+                if reqErr == nil || reqErr.status != tt.status {
+                    t.Fatalf("patchTaskRequest(%s) = %+v, %v; ожидался статус %d", tt.body, got, reqErr, tt.status)
+                }
+                return
+            }
+            if reqErr != nil {
+                t.Fatalf("patchTaskRequest(%s): %d %v", tt.body, reqErr.status, reqErr.body)
+            }
+            want := taskRequest(current)
+            if tt.want != nil {
+                tt.want(&want)
+            }
+            if !reflect.DeepEqual(*got, want) {
+                t.Fatalf("patchTaskRequest(%s) = %+v, ожидалось %+v", tt.body, *got, want)
+            }
+        })
+    }
+}
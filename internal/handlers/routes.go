@@ -1,21 +1,27 @@
 package handlers
 
 import (
+    "strings"
     "time"
+    "kanban-calendar/internal/datetime"
     "kanban-calendar/internal/repository"
+    "kanban-calendar/telegram"
     "github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository) {
+// SetupRoutes - маршруты API; bot = nil - отправка в Telegram недоступна,
+// trashRetention - срок хранения удаленных задач в корзине
+func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository, zones *datetime.Zones, bot *telegram.TelegramBot, trashRetention time.Duration) {
     // CORS middleware
     r.Use(func(c *gin.Context) {
         c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
         c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-        c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range")
+        c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-Timezone, X-User, If-Match, If-None-Match")
+        c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Range, ETag")
         c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
         
-        if c.Request.Method == "OPTIONS" {
+        // OPTIONS у CalDAV - не preflight: клиент по нему узнает поддержку calendar-access
+        if c.Request.Method == "OPTIONS" && !strings.HasPrefix(c.Request.URL.Path, "/caldav") {
             c.AbortWithStatus(204)
             return
         }
@@ -23,6 +29,12 @@ func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository) {
         c.Next()
     })
     
+    // Пояс запроса для ввода и вывода дат без смещения
+    r.Use(RequestZone(zones))
+    
+    // Автор изменений для истории задач
+    r.Use(RequestActor())
+    
     // Группа API маршрутов
     api := r.Group("/api")
     {
@@ -38,13 +50,106 @@ func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository) {
             tasks.GET("/status/:status", GetTasksByStatus(repo))
             tasks.GET("/:id", GetTaskByID(repo))
             tasks.PUT("/:id", UpdateTask(repo))
-            tasks.DELETE("/:id", DeleteTask(repo))
+            tasks.PATCH("/:id", PatchTask(repo))
+            tasks.POST("/:id/move", MoveTask(repo))
+            tasks.DELETE("/:id", DeleteTask(repo, trashRetention))
+            tasks.POST("/:id/archive", ArchiveTask(repo))
+            tasks.POST("/:id/restore", RestoreTask(repo))
+            tasks.GET("/:id/history", GetTaskHistory(repo))
+            tasks.GET("/:id/subtasks", GetSubtasks(repo))
+            tasks.GET("/:id/checklist", GetChecklist(repo))
+            tasks.POST("/:id/checklist", CreateChecklistItem(repo))
+            tasks.PATCH("/:id/checklist/:item_id", UpdateChecklistItem(repo))
+            tasks.DELETE("/:id/checklist/:item_id", DeleteChecklistItem(repo))
+            tasks.GET("/:id/links", GetTaskLinks(repo))
+            tasks.POST("/:id/links", CreateLink(repo))
+            tasks.DELETE("/:id/links/:link_id", DeleteLink(repo))
+            tasks.GET("/:id/comments", GetComments(repo))
+            tasks.POST("/:id/comments", CreateComment(repo, bot))
+            tasks.GET("/:id/comments/:comment_id", GetComment(repo))
+            tasks.PUT("/:id/comments/:comment_id", UpdateComment(repo, bot))
+            tasks.DELETE("/:id/comments/:comment_id", DeleteComment(repo))
+            tasks.GET("/:id/comments/:comment_id/history", GetCommentHistory(repo))
+        }
+        
+        // Доски и колонки
+        boards := api.Group("/boards")
+        {
+            boards.GET("", GetBoards(repo))
+            boards.POST("", CreateBoard(repo))
+            boards.GET("/:id", GetBoardByID(repo))
+            boards.PUT("/:id", UpdateBoard(repo))
+            boards.DELETE("/:id", DeleteBoard(repo))
+            boards.GET("/:id/tasks", GetBoardTasks(repo))
+            boards.GET("/:id/columns", GetColumns(repo))
+            boards.POST("/:id/columns", CreateColumn(repo))
+            boards.PUT("/:id/columns/:column_id", UpdateColumn(repo))
+            boards.DELETE("/:id/columns/:column_id", DeleteColumn(repo))
+        }
+        
+        // Теги
+        tags := api.Group("/tags")
+        {
+            tags.GET("", GetTags(repo))
+            tags.PUT("/:id", UpdateTag(repo))
+            tags.DELETE("/:id", DeleteTag(repo))
         }
         
         // Календарь
         calendar := api.Group("/calendar")
         {
             calendar.GET("/events", GetCalendarEvents(repo)) // GET /api/calendar/events
+            calendar.GET("/export.ics", ExportCalendar(repo))
+            calendar.GET("/feeds", GetFeeds(repo))
+            calendar.POST("/feeds", CreateFeed(repo))
+            calendar.DELETE("/feeds/:id", DeleteFeed(repo))
+            calendar.GET("/feed/:token", GetFeed(repo, zones)) // токен с суффиксом .ics или без
+            calendar.GET("/subscriptions", GetSubscriptions(repo))
+            calendar.POST("/subscriptions", CreateSubscription(repo))
+            calendar.GET("/subscriptions/:id", GetSubscriptionByID(repo))
+            calendar.PUT("/subscriptions/:id", UpdateSubscription(repo))
+            calendar.DELETE("/subscriptions/:id", DeleteSubscription(repo))
+            calendar.POST("/subscriptions/:id/sync", SyncSubscription(repo, zones))
+        }
+        
+        // Корзина
+        trash := api.Group("/trash")
+        {
+            trash.GET("", GetTrash(repo, trashRetention))
+            trash.DELETE("/:id", PurgeTask(repo))
+        }
+        
+        // Сохраненные представления
+        views := api.Group("/views")
+        {
+            views.GET("", GetViews(repo))
+            views.POST("", CreateView(repo))
+            views.GET("/:id", GetView(repo))
+            views.PUT("/:id", UpdateView(repo))
+            views.DELETE("/:id", DeleteView(repo))
+            views.POST("/:id/telegram", SendViewToTelegram(repo, bot))
+        }
+        
+        // Журнал изменений задач
+        api.GET("/audit", GetAudit(repo))
+        
+        // Полнотекстовый поиск
+        api.GET("/search", SearchTasks(repo))
+        
+        // Часовые пояса пользователей и чатов
+        timezones := api.Group("/timezones")
+        {
+            timezones.GET("", GetTimeZones(repo, zones))
+            timezones.PUT("/:type/:subject", SetTimeZone(repo, zones))
+            timezones.DELETE("/:type/:subject", DeleteTimeZone(repo, zones))
+        }
+        
+        // Личные чаты Telegram для уведомлений об упоминаниях
+        chats := api.Group("/telegram/chats")
+        {
+            chats.GET("", GetTelegramChats(repo))
+            chats.PUT("/:user", SetTelegramChat(repo))
+            chats.DELETE("/:user", DeleteTelegramChat(repo))
         }
         
         // Системные
@@ -65,6 +170,13 @@ func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository) {
         })
     }
     
+    // CalDAV: доски - календари, задачи - VEVENT/VTODO
+    for _, method := range caldavMethods {
+        r.Handle(method, "/caldav/*path", CalDAV(repo))
+    }
+    r.GET("/.well-known/caldav", CalDAVWellKnown)
+    r.Handle("PROPFIND", "/.well-known/caldav", CalDAVWellKnown)
+    
     // Главная страница
     r.GET("/", func(c *gin.Context) {
         c.JSON(200, gin.H{
@@ -72,13 +184,35 @@ func SetupRoutes(r *gin.Engine, repo *repository.TaskRepository) {
             "version": "1.0.0",
             "docs":    "Доступные эндпоинты:",
             "endpoints": []gin.H{
-                {"method": "GET",    "path": "/api/tasks",           "description": "Получить все задачи"},
+                {"method": "GET",    "path": "/api/tasks",           "description": "Получить задачи (фильтры, sort, cursor)"},
                 {"method": "GET",    "path": "/api/tasks/:id",       "description": "Получить задачу по ID"},
                 {"method": "POST",   "path": "/api/tasks",           "description": "Создать новую задачу"},
-                {"method": "PUT",    "path": "/api/tasks/:id",       "description": "Обновить задачу"},
-                {"method": "DELETE", "path": "/api/tasks/:id",       "description": "Удалить задачу"},
+                {"method": "PUT",    "path": "/api/tasks/:id",       "description": "Заменить задачу целиком"},
+                {"method": "PATCH",  "path": "/api/tasks/:id",       "description": "Изменить поля задачи (JSON Merge Patch)"},
+                {"method": "DELETE", "path": "/api/tasks/:id",       "description": "Переместить задачу в корзину"},
+                {"method": "POST",   "path": "/api/tasks/:id/restore", "description": "Восстановить задачу из корзины или архива"},
+                {"method": "GET",    "path": "/api/trash",           "description": "Корзина"},
+                {"method": "GET",    "path": "/api/tasks/:id/history", "description": "История изменений задачи"},
+                {"method": "GET",    "path": "/api/tasks/:id/subtasks", "description": "Подзадачи"},
+                {"method": "GET",    "path": "/api/tasks/:id/checklist", "description": "Чек-лист задачи"},
+                {"method": "GET",    "path": "/api/tasks/:id/links", "description": "Связи задачи: блокирующие, зависящие, дубликаты"},
+                {"method": "GET",    "path": "/api/tasks/:id/comments", "description": "Комментарии к задаче"},
+                {"method": "POST",   "path": "/api/tasks/:id/comments", "description": "Добавить комментарий (Markdown, @упоминания)"},
+                {"method": "GET",    "path": "/api/audit",           "description": "Журнал изменений всех задач"},
+                {"method": "POST",   "path": "/api/tasks/:id/move",  "description": "Переместить карточку"},
                 {"method": "GET",    "path": "/api/tasks/status/:status", "description": "Получить задачи по статусу"},
+                {"method": "GET",    "path": "/api/views",           "description": "Сохраненные представления пользователя"},
+                {"method": "GET",    "path": "/api/search",          "description": "Полнотекстовый поиск задач"},
+                {"method": "GET",    "path": "/api/boards",          "description": "Список досок"},
+                {"method": "GET",    "path": "/api/boards/:id/columns", "description": "Колонки доски"},
+                {"method": "GET",    "path": "/api/tags",            "description": "Теги с количеством задач"},
                 {"method": "GET",    "path": "/api/calendar/events", "description": "Получить события календаря"},
+                {"method": "GET",    "path": "/api/calendar/export.ics", "description": "Выгрузить задачи в .ics"},
+                {"method": "POST",   "path": "/api/calendar/feeds",  "description": "Создать ленту для подписки"},
+                {"method": "GET",    "path": "/api/calendar/subscriptions", "description": "Подписки на внешние календари"},
+                {"method": "GET",    "path": "/api/timezones",       "description": "Часовые пояса сервера, пользователей и чатов"},
+                {"method": "GET",    "path": "/api/telegram/chats",  "description": "Чаты Telegram пользователей для упоминаний"},
+                {"method": "PROPFIND", "path": "/caldav/",           "description": "CalDAV: календари досок"},
                 {"method": "GET",    "path": "/api/health",          "description": "Проверка здоровья сервиса"},
             },
         })
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "strconv"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// SearchTasks - полнотекстовый поиск: ?q= (слова ищутся как префиксы), фильтры как у GET /api/tasks,
+// ?limit= и ?offset= для следующих страниц
+func SearchTasks(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        q := c.Query("q")
+        if len(repository.SearchTerms(q)) == 0 {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Параметр q должен содержать хотя бы одно слово"})
+            return
+        }
+
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+        filter.Text = "" // q здесь - поисковый запрос, а не поиск подстроки
+        if c.Query("archived") == "" {
+            filter.Archived = true // архив скрыт с доски, но ищется
+        }
+
+        var limit, offset int
+        for _, param := range []struct {
+            name   string
+            target *int
+        }{{"limit", &limit}, {"offset", &offset}} {
+            value := c.Query(param.name)
+            if value == "" {
+                continue
+            }
+            n, err := strconv.Atoi(value)
+            if err != nil || n < 0 {
+                c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s - неотрицательное целое число", param.name)})
+                return
+            }
+            *param.target = n
+        }
+
+        results, err := repo.SearchTasks(c.Request.Context(), q, filter, limit, offset)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка поиска",
+                "details": err.Error(),
+            })
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "query":   q,
+            "results": results,
+            "count":   len(results),
+        })
+    }
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+    "fmt"
+    "net/http"
+    "net/url"
+    "strconv"
+    "strings"
+    "kanban-calendar/internal/datetime"
+    "kanban-calendar/internal/ical"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// minRefreshMinutes - чаще внешний календарь не опрашиваем
+const minRefreshMinutes = 5
+
+// GetSubscriptions - подписки вместе со статусом и ошибкой последней синхронизации
+func GetSubscriptions(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        subs, err := repo.GetSubscriptions(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"subscriptions": subs, "count": len(subs)})
+    }
+}
+
+// GetSubscriptionByID - одна подписка
+func GetSubscriptionByID(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        sub, ok := loadSubscription(c, repo)
+        if !ok {
+            return
+        }
+        c.JSON(http.StatusOK, sub)
+    }
+}
+
+// CreateSubscription - добавляет подписку; первая синхронизация - в ближайшую минуту
+func CreateSubscription(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        var req models.CreateSubscriptionRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+
+        sub := &models.CalendarSubscription{
+            Name:            req.Name,
+            URL:             strings.TrimSpace(req.URL),
+            RefreshMinutes:  req.RefreshMinutes,
+            BoardID:         req.BoardID,
+            DefaultAssignee: req.DefaultAssignee,
+            ArchiveMissing:  true,
+            Enabled:         true,
+        }
+        if sub.RefreshMinutes == 0 {
+            sub.RefreshMinutes = 60
+        }
+        if req.ArchiveMissing != nil {
+            sub.ArchiveMissing = *req.ArchiveMissing
+        }
+        if !validateSubscription(c, repo, sub) {
+            return
+        }
+
+        if err := repo.CreateSubscription(c.Request.Context(), sub); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusCreated, sub)
+    }
+}
+
+// UpdateSubscription - меняет настройки подписки
+func UpdateSubscription(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        sub, ok := loadSubscription(c, repo)
+        if !ok {
+            return
+        }
+
+        var req models.UpdateSubscriptionRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+
+        if req.Name != "" {
+            sub.Name = req.Name
+        }
+        if req.URL != "" {
+            sub.URL = strings.TrimSpace(req.URL)
+        }
+        if req.RefreshMinutes != nil {
+            sub.RefreshMinutes = *req.RefreshMinutes
+        }
+        if req.BoardID != nil {
+            sub.BoardID = *req.BoardID
+        }
+        if req.DefaultAssignee != nil {
+            sub.DefaultAssignee = *req.DefaultAssignee
+        }
+        if req.ArchiveMissing != nil {
+            sub.ArchiveMissing = *req.ArchiveMissing
+        }
+        if req.Enabled != nil {
+            sub.Enabled = *req.Enabled
+        }
+        if !validateSubscription(c, repo, sub) {
+            return
+        }
+
+        if err := repo.UpdateSubscription(c.Request.Context(), sub); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, sub)
+    }
+}
+
+// DeleteSubscription - удаляет подписку (задачи остаются на доске)
+func DeleteSubscription(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID подписки"})
+            return
+        }
+        if err := repo.DeleteSubscription(c.Request.Context(), id); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Подписка удалена"})
+    }
+}
+
+// SyncSubscription - синхронизирует подписку немедленно, не дожидаясь планировщика.
+// Плавающее время понимается в поясе сервера, как и при синхронизации по расписанию
+func SyncSubscription(repo *repository.TaskRepository, zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        sub, ok := loadSubscription(c, repo)
+        if !ok {
+            return
+        }
+
+        res := ical.NewImporter(repo, zones.Default()).SyncSubscription(c.Request.Context(), sub)
+
+        sub, err := repo.GetSubscriptionByID(c.Request.Context(), sub.ID)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        status := http.StatusOK
+        if res.Status == models.SyncStatusError {
+            status = http.StatusBadGateway
+        }
+        c.JSON(status, sub)
+    }
+}
+
+func loadSubscription(c *gin.Context, repo *repository.TaskRepository) (*models.CalendarSubscription, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID подписки"})
+        return nil, false
+    }
+    sub, err := repo.GetSubscriptionByID(c.Request.Context(), id)
+    if err != nil {
+        c.JSON(errorStatus(err), gin.H{"error": "Подписка не найдена", "details": err.Error()})
+        return nil, false
+    }
+    return sub, true
+}
+
+// validateSubscription - проверяет URL, интервал и доску (0 - доска по умолчанию)
+func validateSubscription(c *gin.Context, repo *repository.TaskRepository, sub *models.CalendarSubscription) bool {
+    u, err := url.Parse(ical.FeedURL(sub.URL))
+    if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "url должен начинаться с http://, https:// или webcal://"})
+        return false
+    }
+    if sub.RefreshMinutes < minRefreshMinutes {
+        c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("refresh_minutes не может быть меньше %d", minRefreshMinutes)})
+        return false
+    }
+
+    col, err := repo.ImportColumn(c.Request.Context(), sub.BoardID)
+    if err != nil {
+        c.JSON(errorStatus(err), gin.H{"error": "Доска для импорта не найдена", "details": err.Error()})
+        return false
+    }
+    sub.BoardID = col.BoardID
+    return true
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// GetTags - все теги с количеством задач
+func GetTags(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        tags, err := repo.GetTags(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка получения тегов",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if tags == nil {
+            tags = []models.Tag{}
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "tags":  tags,
+            "count": len(tags),
+        })
+    }
+}
+
+// UpdateTag - переименовывает тег или меняет его цвет
+func UpdateTag(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID тега"})
+            return
+        }
+        
+        tag, err := repo.GetTagByID(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Тег не найден",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        var req models.UpdateTagRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        if name := strings.TrimSpace(req.Name); name != "" {
+            tag.Name = name
+        }
+        if req.Color != nil {
+            tag.Color = *req.Color
+        }
+        
+        if err := repo.UpdateTag(c.Request.Context(), tag); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка обновления тега",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, tag)
+    }
+}
+
+// DeleteTag - удаляет тег со всех задач
+func DeleteTag(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID тега"})
+            return
+        }
+        
+        if err := repo.DeleteTag(c.Request.Context(), id); err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка удаления тега",
+                "details": err.Error(),
+            })
+            return
+        }
+        
+        c.JSON(http.StatusOK, gin.H{
+            "message": "Тег успешно удален",
+            "id":      id,
+        })
+    }
+}
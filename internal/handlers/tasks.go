@@ -1,22 +1,128 @@
 package handlers
 
 import (
+    "context"
+    "errors"
     "fmt"
     "github.com/arran4/golang-ical"
     "net/http"
     "strconv"
+    "strings"
     "time"
+    "kanban-calendar/internal/datetime"
+    "kanban-calendar/internal/ical"
     "kanban-calendar/internal/models"
+    "kanban-calendar/internal/recurrence"
     "kanban-calendar/internal/repository"
     "github.com/gin-gonic/gin"
 )
 
-// GetTasks - получает все задачи
+// taskFilterFromQuery - фильтр задач из query-параметров. status, assignee, priority и tag
+// принимают несколько значений через запятую или повтором параметра; q - поиск подстроки;
+// deadline_from/deadline_to, created_from/created_to, updated_from/updated_to - даты в поясе запроса;
+// view - ID сохраненного представления, query - запрос языка представлений (см. applyTaskQuery)
+func taskFilterFromQuery(c *gin.Context, repo *repository.TaskRepository) (repository.TaskFilter, *requestError) {
+    boardID, _ := strconv.Atoi(c.Query("board_id"))
+    archived, _ := strconv.ParseBool(c.Query("archived"))
+    filter := repository.TaskFilter{
+        BoardID:    boardID,
+        Tags:       queryList(c, "tag"),
+        Assignees:  queryList(c, "assignee"),
+        Priorities: queryList(c, "priority"),
+        Text:       c.Query("q"),
+        Archived:   archived,
+    }
+    for _, status := range queryList(c, "status") {
+        filter.Statuses = append(filter.Statuses, models.TaskStatus(status))
+    }
+
+    ranges := []struct {
+        name   string
+        target **time.Time
+    }{
+        {"deadline_from", &filter.DeadlineFrom}, {"deadline_to", &filter.DeadlineTo},
+        {"created_from", &filter.CreatedFrom}, {"created_to", &filter.CreatedTo},
+        {"updated_from", &filter.UpdatedFrom}, {"updated_to", &filter.UpdatedTo},
+    }
+    for _, r := range ranges {
+        value := c.Query(r.name)
+        if value == "" {
+            continue
+        }
+        t, err := datetime.ParseTime(value, zoneOf(c))
+        if err != nil {
+            return filter, &requestError{http.StatusBadRequest, gin.H{
+                "error":   fmt.Sprintf("Неверный параметр %s", r.name),
+                "details": err.Error(),
+            }}
+        }
+        *r.target = &t
+    }
+
+    var view *models.SavedView
+    if value := c.Query("view"); value != "" {
+        id, err := strconv.Atoi(value)
+        if err != nil {
+            return filter, &requestError{http.StatusBadRequest, gin.H{"error": "Неверный формат ID представления"}}
+        }
+        view, err = repo.GetView(c.Request.Context(), id)
+        if err != nil {
+            return filter, &requestError{errorStatus(err), gin.H{"error": "Представление не найдено", "details": err.Error()}}
+        }
+        c.Set(viewKey, view)
+    }
+    if reqErr := applyTaskQuery(c, &filter, view); reqErr != nil {
+        return filter, reqErr
+    }
+    return filter, nil
+}
+
+// queryList - значения параметра: ?tag=a,b и ?tag=a&tag=b равнозначны
+func queryList(c *gin.Context, name string) []string {
+    var values []string
+    for _, raw := range c.QueryArray(name) {
+        for _, value := range strings.Split(raw, ",") {
+            if value = strings.TrimSpace(value); value != "" {
+                values = append(values, value)
+            }
+        }
+    }
+    return values
+}
+
+// GetTasks - страница задач по фильтру (см. taskFilterFromQuery), ?sort=-deadline,title
+// (по умолчанию - сортировка представления из ?view=),
+// ?limit= и ?cursor= - next_cursor предыдущей страницы
 func GetTasks(repo *repository.TaskRepository) gin.HandlerFunc {
     return func(c *gin.Context) {
-        tasks, err := repo.GetAllTasks(c.Request.Context())
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+        sort := c.Query("sort")
+        if view := viewOf(c); view != nil && sort == "" {
+            sort = view.Sort
+        }
+        sorts, err := repository.ParseTaskSort(sort)
         if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        page := repository.TaskPage{Sort: sorts, Cursor: c.Query("cursor")}
+        if value := c.Query("limit"); value != "" {
+            page.Limit, err = strconv.Atoi(value)
+            if err != nil || page.Limit < 1 {
+                c.JSON(http.StatusBadRequest, gin.H{
+                    "error": fmt.Sprintf("limit - целое число от 1 до %d", repository.MaxPageLimit),
+                })
+                return
+            }
+        }
+
+        tasks, next, err := repo.ListTasksPage(c.Request.Context(), filter, page)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
                 "error":   "Ошибка получения задач",
                 "details": err.Error(),
             })
@@ -27,9 +133,14 @@ func GetTasks(repo *repository.TaskRepository) gin.HandlerFunc {
             tasks = []models.Task{} // Пустой массив вместо nil
         }
         
+        var nextCursor interface{} // null на последней странице
+        if next != "" {
+            nextCursor = next
+        }
         c.JSON(http.StatusOK, gin.H{
-            "tasks": tasks,
-            "count": len(tasks),
+            "tasks":       tasks,
+            "count":       len(tasks),
+            "next_cursor": nextCursor,
         })
     }
 }
@@ -37,23 +148,16 @@ func GetTasks(repo *repository.TaskRepository) gin.HandlerFunc {
 // GetTaskByID - получает задачу по ID
 func GetTaskByID(repo *repository.TaskRepository) gin.HandlerFunc {
     return func(c *gin.Context) {
-        id, err := strconv.Atoi(c.Param("id"))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": "Неверный формат ID задачи",
-            })
+        task, ok := loadTask(c, repo)
+        if !ok {
             return
         }
         
-        task, err := repo.GetTaskByID(c.Request.Context(), id)
-        if err != nil {
-            c.JSON(http.StatusNotFound, gin.H{
-                "error":   "Задача не найдена",
-                "details": err.Error(),
-            })
+        c.Header("ETag", taskETag(task))
+        if match := c.GetHeader("If-None-Match"); match != "" && match == taskETag(task) {
+            c.Status(http.StatusNotModified)
             return
         }
-        
         c.JSON(http.StatusOK, task)
     }
 }
@@ -67,65 +171,83 @@ func CreateTask(repo *repository.TaskRepository) gin.HandlerFunc {
 			return
 		}
 
-		// Функция для перевода локальных цифр в UTC для базы
-		parseToUTC := func(s string) *time.Time {
-			if s == "" { return nil }
-			var y, m, d, h, min, sec int
-			_, err := fmt.Sscanf(s, "%d-%d-%dT%d:%d:%d", &y, &m, &d, &h, &min, &sec)
-			if err != nil { return nil }
-			
-			// Создаем время в поясе +5
-			loc := time.FixedZone("UTC+5", 5*60*60)
-			localTime := time.Date(y, time.Month(m), d, h, min, sec, 0, loc)
-			
-			// ПРЕОБРАЗУЕМ В UTC
-			utcTime := localTime.UTC()
-			return &utcTime
-		}
-
-		task := &models.Task{
-			Title:             req.Title,
-			Description:       req.Description,
-			Status:            req.Status,
-			Priority:          req.Priority,
-			Assignee:          req.Assignee,
-			Deadline:          parseToUTC(req.Deadline),
-			StartDate:         parseToUTC(req.StartDate),
-			EndDate:           parseToUTC(req.EndDate),
-			LastNotifiedHours: 999,
+		task, reqErr := newTask(c.Request.Context(), repo, req, zoneOf(c))
+		if reqErr != nil {
+			c.JSON(reqErr.status, reqErr.body)
+			return
 		}
 
 		if err := repo.CreateTask(c.Request.Context(), task); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			if respondWIPLimit(c, err) {
+				return
+			}
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
+		c.Header("ETag", taskETag(task))
 		c.JSON(http.StatusCreated, task)
 	}
 }
 
-// UpdateTask - обновляет задачу
+// newTask - задача из запроса создания: даты, правило повторения и колонка (без сохранения).
+// Даты без смещения понимаются в поясе loc
+func newTask(ctx context.Context, repo *repository.TaskRepository, req models.CreateTaskRequest, loc *time.Location) (*models.Task, *requestError) {
+	task := &models.Task{
+		Title:             req.Title,
+		Description:       req.Description,
+		Priority:          req.Priority,
+		Assignee:          req.Assignee,
+		Tags:              req.Tags,
+		LastNotifiedHours: 999,
+		RRule:             req.RRule,
+		AllDay:            req.AllDay,
+		ParentID:          req.ParentID,
+	}
+	dateOnly, reqErr := parseTaskDates([]taskDate{
+		{req.Deadline, &task.Deadline, "Неверный формат даты дедлайна"},
+		{req.StartDate, &task.StartDate, "Неверный формат даты начала"},
+		{req.EndDate, &task.EndDate, "Неверный формат даты окончания"},
+	}, loc)
+	if reqErr != nil {
+		return nil, reqErr
+	}
+	if dateOnly {
+		task.AllDay = true
+	}
+	for _, ex := range req.ExDates {
+		exdate, err := datetime.ParseTime(ex, loc)
+		if err != nil {
+			return nil, &requestError{http.StatusBadRequest, gin.H{"error": "Неверный формат исключенной даты", "details": err.Error()}}
+		}
+		task.ExDates = append(task.ExDates, exdate)
+	}
+	if err := normalizeRecurrence(task); err != nil {
+		return nil, &requestError{http.StatusBadRequest, gin.H{"error": "Неверное правило повторения", "details": err.Error()}}
+	}
+
+	// Колонка: явно по column_id, иначе по статусу, иначе первая колонка доски
+	col, err := repo.ResolveColumn(ctx, req.BoardID, req.ColumnID, req.Status)
+	if err != nil {
+		return nil, &requestError{errorStatus(err), gin.H{"error": "Колонка не найдена", "details": err.Error()}}
+	}
+	task.SetColumn(col)
+	return task, nil
+}
+
+// UpdateTask - PUT: полная замена задачи. Тело - как при создании; пропущенные поля
+// сбрасываются (описание, исполнитель, даты), без board_id задача остается на своей доске
 func UpdateTask(repo *repository.TaskRepository) gin.HandlerFunc {
     return func(c *gin.Context) {
-        id, err := strconv.Atoi(c.Param("id"))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": "Неверный формат ID задачи",
-            })
+        current, ok := loadTask(c, repo)
+        if !ok {
             return
         }
-        
-        // Получаем существующую задачу
-        task, err := repo.GetTaskByID(c.Request.Context(), id)
-        if err != nil {
-            c.JSON(http.StatusNotFound, gin.H{
-                "error":   "Задача не найдена",
-                "details": err.Error(),
-            })
+        version, ok := ifMatchVersion(c, current)
+        if !ok {
             return
         }
         
-        // Парсим обновления
-        var req models.UpdateTaskRequest
+        var req models.CreateTaskRequest
         if err := c.ShouldBindJSON(&req); err != nil {
             c.JSON(http.StatusBadRequest, gin.H{
                 "error":   "Неверный формат данных",
@@ -134,133 +256,388 @@ func UpdateTask(repo *repository.TaskRepository) gin.HandlerFunc {
             return
         }
         
-        // Обновляем поля если они переданы
-        if req.Title != "" {
-            task.Title = req.Title
+        saveReplacement(c, repo, current, req, version)
+    }
+}
+
+// PatchTask - PATCH: JSON Merge Patch (RFC 7386) поверх текущей задачи. Переданные поля
+// заменяются, null очищает поле, остальные не меняются; результат проверяется как при создании
+func PatchTask(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        current, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        version, ok := ifMatchVersion(c, current)
+        if !ok {
+            return
+        }
+        
+        body, err := c.GetRawData()
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Не удалось прочитать тело запроса", "details": err.Error()})
+            return
+        }
+        req, reqErr := patchTaskRequest(current, body)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
+        
+        saveReplacement(c, repo, current, *req, version)
+    }
+}
+
+// loadTask - задача по :id; при ошибке ответ уже отправлен
+func loadTask(c *gin.Context, repo *repository.TaskRepository) (*models.Task, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "error": "Неверный формат ID задачи",
+        })
+        return nil, false
+    }
+    
+    task, err := repo.GetTaskByID(c.Request.Context(), id)
+    if err != nil {
+        message := "Задача не найдена"
+        if !errors.Is(err, repository.ErrNotFound) {
+            message = "Ошибка получения задачи"
+        }
+        c.JSON(errorStatus(err), gin.H{
+            "error":   message,
+            "details": err.Error(),
+        })
+        return nil, false
+    }
+    return task, true
+}
+
+// saveReplacement - заменяет задачу содержимым запроса и сохраняет ее;
+// version - версия из If-Match (0 - без проверки), ?force=true - начать заблокированную задачу,
+// ?cascade=true - сдвинуть зависимые задачи (см. cascadeOptions)
+func saveReplacement(c *gin.Context, repo *repository.TaskRepository, current *models.Task, req models.CreateTaskRequest, version int) {
+    task, reqErr := replaceTask(c.Request.Context(), repo, current, req, zoneOf(c))
+    if reqErr != nil {
+        c.JSON(reqErr.status, reqErr.body)
+        return
+    }
+    task.Version = version
+    task.Force, _ = strconv.ParseBool(c.Query("force"))
+    cascade, reqErr := cascadeOptions(c)
+    if reqErr != nil {
+        c.JSON(reqErr.status, reqErr.body)
+        return
+    }
+    
+    var err error
+    if cascade != nil {
+        err = repo.UpdateTaskCascade(c.Request.Context(), task, *cascade)
+    } else {
+        err = repo.UpdateTask(c.Request.Context(), task)
+    }
+    if err != nil {
+        if respondWIPLimit(c, err) || respondBlocked(c, err) || respondVersionMismatch(c, repo, current.ID, err) {
+            return
+        }
+        c.JSON(errorStatus(err), gin.H{
+            "error":   "Ошибка обновления задачи",
+            "details": err.Error(),
+        })
+        return
+    }
+    
+    if cascade != nil && cascade.Preview {
+        // Ничего не сохранено: у задачи остаются прежние версия и время изменения, ETag нет
+        task.Version, task.UpdatedAt = current.Version, current.UpdatedAt
+        c.JSON(http.StatusOK, gin.H{
+            "preview":     true,
+            "task":        task,
+            "rescheduled": task.Rescheduled,
+            "count":       len(task.Rescheduled),
+        })
+        return
+    }
+    c.Header("ETag", taskETag(task))
+    c.JSON(http.StatusOK, task)
+}
+
+// cascadeOptions - параметры каскадного сдвига из запроса: cascade=true, lag_days - зазор в днях,
+// working_days=true - зазор в рабочих днях без выходных, preview=true - только показать сдвиги
+// (preview включает cascade). nil - каскад не запрошен
+func cascadeOptions(c *gin.Context) (*repository.CascadeOptions, *requestError) {
+    cascade, _ := strconv.ParseBool(c.Query("cascade"))
+    preview, _ := strconv.ParseBool(c.Query("preview"))
+    if !cascade && !preview {
+        return nil, nil
+    }
+    opts := &repository.CascadeOptions{Preview: preview}
+    opts.WorkingDays, _ = strconv.ParseBool(c.Query("working_days"))
+    if value := c.Query("lag_days"); value != "" {
+        lag, err := strconv.Atoi(value)
+        if err != nil || lag < 0 || lag > repository.MaxLagDays {
+            return nil, &requestError{http.StatusBadRequest, gin.H{
+                "error": fmt.Sprintf("lag_days - целое число от 0 до %d", repository.MaxLagDays),
+            }}
         }
-        if req.Description != "" {
-            task.Description = req.Description
+        opts.LagDays = lag
+    }
+    return opts, nil
+}
+
+// taskETag - ETag задачи: версия строки
+func taskETag(task *models.Task) string {
+    return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// ifMatchVersion - версия, с которой клиент правит задачу (If-Match); 0 - заголовка нет или "*".
+// Если ETag клиента устарел, отвечает 412 с текущей задачей и возвращает false
+func ifMatchVersion(c *gin.Context, current *models.Task) (int, bool) {
+    header := strings.TrimSpace(c.GetHeader("If-Match"))
+    if header == "" || header == "*" {
+        return 0, true
+    }
+    etag := taskETag(current)
+    for _, candidate := range strings.Split(header, ",") {
+        if strings.TrimSpace(candidate) == etag {
+            return current.Version, true
         }
-        if req.Status != "" {
-            task.Status = req.Status
+    }
+    respondStale(c, current)
+    return 0, false
+}
+
+// respondVersionMismatch - 412, если задачу изменили между проверкой If-Match и записью
+func respondVersionMismatch(c *gin.Context, repo *repository.TaskRepository, id int, err error) bool {
+    if !errors.Is(err, repository.ErrVersionMismatch) {
+        return false
+    }
+    current, loadErr := repo.GetTaskByID(c.Request.Context(), id)
+    if loadErr != nil {
+        c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error(), "code": "version_mismatch"})
+        return true
+    }
+    respondStale(c, current)
+    return true
+}
+
+// respondStale - 412: клиент правил устаревшую версию; в ответе - текущая, чтобы он мог слить правки
+func respondStale(c *gin.Context, current *models.Task) {
+    c.Header("ETag", taskETag(current))
+    c.JSON(http.StatusPreconditionFailed, gin.H{
+        "error":   "Задача изменилась с момента чтения",
+        "code":    "version_mismatch",
+        "current": current,
+    })
+}
+
+// replaceTask - новое содержимое задачи по тем же правилам, что и создание (без сохранения).
+// От текущей задачи остаются только идентичность и служебные поля: ID, связь с внешним
+// календарем и серией повторений, архив, счетчик напоминаний
+func replaceTask(ctx context.Context, repo *repository.TaskRepository, current *models.Task, req models.CreateTaskRequest, loc *time.Location) (*models.Task, *requestError) {
+    if req.BoardID == 0 {
+        req.BoardID = current.BoardID
+    }
+    task, reqErr := newTask(ctx, repo, req, loc)
+    if reqErr != nil {
+        return nil, reqErr
+    }
+    
+    task.ID = current.ID
+    task.CreatedAt = current.CreatedAt
+    task.Position = current.Position
+    task.LastNotifiedHours = current.LastNotifiedHours
+    task.SeriesID = current.SeriesID
+    task.ArchivedAt = current.ArchivedAt
+    task.ExternalUID = current.ExternalUID
+    task.ExternalSource = current.ExternalSource
+    task.ExternalSequence = current.ExternalSequence
+    task.ExternalModified = current.ExternalModified
+    task.ExternalPriority = current.ExternalPriority
+    task.ExternalPercent = current.ExternalPercent
+    task.CalDAVName = current.CalDAVName
+    task.CalDAVComponent = current.CalDAVComponent
+    return task, nil
+}
+
+// taskDate - дата из запроса и поле задачи, куда она записывается
+type taskDate struct {
+    value  string
+    target **time.Time
+    what   string // текст ошибки
+}
+
+// parseTaskDates - разбирает переданные даты общим парсером (пустые пропускаются).
+// dateOnly - все переданные даты введены без времени, то есть задача на весь день
+func parseTaskDates(dates []taskDate, loc *time.Location) (dateOnly bool, reqErr *requestError) {
+    now := time.Now()
+    given, days := 0, 0
+    for _, date := range dates {
+        if strings.TrimSpace(date.value) == "" {
+            continue
         }
-        if req.Priority != "" {
-            task.Priority = req.Priority
+        v, err := datetime.Parse(date.value, loc, now)
+        if err != nil {
+            return false, &requestError{http.StatusBadRequest, gin.H{
+                "error":   date.what,
+                "details": err.Error(),
+            }}
         }
-        if req.Assignee != "" {
-            task.Assignee = req.Assignee
+        t := v.Time
+        *date.target = &t
+        given++
+        if v.AllDay {
+            days++
         }
-        if req.Tags != nil {
-            task.Tags = req.Tags
+    }
+    return given > 0 && days == given, nil
+}
+
+// normalizeRecurrence - проверяет правило повторения и приводит его к каноничному виду
+func normalizeRecurrence(task *models.Task) error {
+	if task.RRule == "" {
+		task.ExDates = nil
+		return nil
+	}
+	rule, err := recurrence.Parse(task.RRule)
+	if err != nil {
+		return err
+	}
+	if task.StartDate == nil && task.Deadline == nil {
+		return fmt.Errorf("для повторяющейся задачи нужна дата начала или дедлайн")
+	}
+	task.RRule = rule.String()
+	return nil
+}
+
+// MoveTask - переносит карточку в колонку и между соседями (drag-and-drop)
+func MoveTask(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error": "Неверный формат ID задачи",
+            })
+            return
         }
         
-        // Парсим даты если они переданы
-        parseTime := func(timeStr string) (*time.Time, error) {
-            if timeStr == "" {
-                return nil, nil
-            }
-            t, err := time.Parse(time.RFC3339, timeStr)
-            if err != nil {
-                return nil, err
-            }
-            return &t, nil
+        var req models.MoveTaskRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error":   "Неверный формат данных",
+                "details": err.Error(),
+            })
+            return
+        }
+        if req.BeforeID == id || req.AfterID == id {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "error": "Задача не может быть соседом самой себя",
+            })
+            return
         }
         
-        if req.Deadline != "" {
-            if deadline, err := parseTime(req.Deadline); err != nil {
-                c.JSON(http.StatusBadRequest, gin.H{
-                    "error":   "Неверный формат даты дедлайна",
-                    "details": err.Error(),
-                })
-                return
-            } else {
-                task.Deadline = deadline
-            }
+        task, ok := loadTask(c, repo)
+        if !ok {
+            return
         }
         
-        if req.StartDate != "" {
-            if startDate, err := parseTime(req.StartDate); err != nil {
-                c.JSON(http.StatusBadRequest, gin.H{
-                    "error":   "Неверный формат даты начала",
-                    "details": err.Error(),
-                })
-                return
-            } else {
-                task.StartDate = startDate
+        // Без колонки в запросе задача остается в своей колонке
+        boardID := req.BoardID
+        columnID := req.ColumnID
+        if boardID == 0 && columnID == 0 {
+            boardID = task.BoardID
+            if req.Status == "" {
+                columnID = task.ColumnID
             }
         }
         
-        if req.EndDate != "" {
-            if endDate, err := parseTime(req.EndDate); err != nil {
-                c.JSON(http.StatusBadRequest, gin.H{
-                    "error":   "Неверный формат даты окончания",
-                    "details": err.Error(),
-                })
-                return
-            } else {
-                task.EndDate = endDate
-            }
+        col, err := repo.ResolveColumn(c.Request.Context(), boardID, columnID, req.Status)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Колонка не найдена",
+                "details": err.Error(),
+            })
+            return
         }
         
-        // Сохраняем изменения
-        if err := repo.UpdateTask(c.Request.Context(), task); err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{
-                "error":   "Ошибка обновления задачи",
+        moved, err := repo.MoveTask(c.Request.Context(), id, col, req.AfterID, req.BeforeID, req.Force)
+        if err != nil {
+            if respondWIPLimit(c, err) || respondBlocked(c, err) {
+                return
+            }
+            c.JSON(errorStatus(err), gin.H{
+                "error":   "Ошибка перемещения задачи",
                 "details": err.Error(),
             })
             return
         }
         
-        c.JSON(http.StatusOK, task)
+        c.JSON(http.StatusOK, moved)
     }
 }
 
-// DeleteTask - удаляет задачу
-func DeleteTask(repo *repository.TaskRepository) gin.HandlerFunc {
+// DeleteTask - перемещает задачу в корзину; retention - срок хранения в корзине (0 - бессрочно)
+func DeleteTask(repo *repository.TaskRepository, retention time.Duration) gin.HandlerFunc {
     return func(c *gin.Context) {
-        id, err := strconv.Atoi(c.Param("id"))
-        if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{
-                "error": "Неверный формат ID задачи",
-            })
+        current, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        version, ok := ifMatchVersion(c, current)
+        if !ok {
             return
         }
         
-        if err := repo.DeleteTask(c.Request.Context(), id); err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{
+        if err := repo.DeleteTask(c.Request.Context(), current.ID, version); err != nil {
+            if respondVersionMismatch(c, repo, current.ID, err) {
+                return
+            }
+            c.JSON(errorStatus(err), gin.H{
                 "error":   "Ошибка удаления задачи",
                 "details": err.Error(),
             })
             return
         }
         
-        c.JSON(http.StatusOK, gin.H{
-            "message": "Задача успешно удалена",
-            "id":      id,
-        })
+        resp := gin.H{
+            "message": "Задача перемещена в корзину",
+            "id":      current.ID,
+        }
+        if retention > 0 {
+            resp["purge_at"] = time.Now().Add(retention).UTC()
+        }
+        c.JSON(http.StatusOK, resp)
     }
 }
 
 // GetTasksByStatus - получает задачи по статусу
 func GetTasksByStatus(repo *repository.TaskRepository) gin.HandlerFunc {
     return func(c *gin.Context) {
+        filter, reqErr := taskFilterFromQuery(c, repo)
+        if reqErr != nil {
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        }
         status := models.TaskStatus(c.Param("status"))
+        filter.Statuses = []models.TaskStatus{status}
         
-        // Проверяем валидность статуса
-        validStatuses := map[models.TaskStatus]bool{
-            models.StatusTodo:       true,
-            models.StatusInProgress: true,
-            models.StatusDone:       true,
+        // Статус должен совпадать со slug одной из колонок
+        exists, err := repo.StatusExists(c.Request.Context(), status, filter.BoardID)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{
+                "error":   "Ошибка проверки статуса",
+                "details": err.Error(),
+            })
+            return
         }
-        
-        if !validStatuses[status] {
+        if !exists {
             c.JSON(http.StatusBadRequest, gin.H{
-                "error": "Неверный статус задачи. Допустимые значения: todo, in_progress, done",
+                "error": "Неверный статус задачи: такой колонки нет на доске",
             })
             return
         }
         
-        tasks, err := repo.GetTasksByStatus(c.Request.Context(), status)
+        tasks, err := repo.ListTasks(c.Request.Context(), filter)
         if err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{
                 "error":   "Ошибка получения задач",
@@ -281,6 +658,8 @@ func GetTasksByStatus(repo *repository.TaskRepository) gin.HandlerFunc {
     }
 }
 
+// ImportCalendar - синхронизирует задачи с .ics: новые события создаются, измененные
+// (по SEQUENCE/LAST-MODIFIED) обновляются, с archive_missing=true пропавшие уходят в архив
 func ImportCalendar(repo *repository.TaskRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 1. Читаем файл из формы (ключ должен быть "calendar")
@@ -304,62 +683,36 @@ func ImportCalendar(repo *repository.TaskRepository) gin.HandlerFunc {
 			return
 		}
 
-		imported := 0
-		skipped := 0
-
-		// 3. Проходим по всем событиям в файле
-		for _, event := range cal.Events() {
-			uid := event.Id()
-			summary := ""
-			if prop := event.GetProperty(ics.ComponentPropertySummary); prop != nil {
-				summary = prop.Value
-			}
-
-			description := ""
-			if prop := event.GetProperty(ics.ComponentPropertyDescription); prop != nil {
-				description = prop.Value
-			}
-
-			// Простейший парсинг дат (формат 20260102T150405Z)
-			var start, end *time.Time
-			if prop := event.GetProperty(ics.ComponentPropertyDtStart); prop != nil {
-				t, _ := time.Parse("20060102T150405Z", prop.Value)
-				if !t.IsZero() {
-					start = &t
-				}
-			}
-			if prop := event.GetProperty(ics.ComponentPropertyDtEnd); prop != nil {
-				t, _ := time.Parse("20060102T150405Z", prop.Value)
-				if !t.IsZero() {
-					end = &t
-				}
-			}
+		// Импортируем в первую колонку выбранной доски (по умолчанию - основной)
+		boardID, _ := strconv.Atoi(c.PostForm("board_id"))
+		col, err := repo.ImportColumn(c.Request.Context(), boardID)
+		if err != nil {
+			c.JSON(errorStatus(err), gin.H{"error": "Доска для импорта не найдена", "details": err.Error()})
+			return
+		}
 
-			// Создаем объект задачи для базы
-			task := &models.Task{
-				ExternalUID:       uid,
-				Title:             summary,
-				Description:       description,
-				Status:            models.StatusTodo,
-				StartDate:         start,
-				EndDate:           end,
-				Deadline:          end,
-				LastNotifiedHours: 100, // Чтобы бот начал отсчет заново
-			}
+		source := strings.TrimSpace(c.PostForm("source"))
+		if source == "" {
+			source = fileHeader.Filename
+		}
+		archiveMissing, _ := strconv.ParseBool(c.PostForm("archive_missing"))
 
-			// 4. Пробуем сохранить в базу
-			if err := repo.CreateTask(c.Request.Context(), task); err != nil {
-				// Если ошибка (например, такой UID уже есть), пропускаем
-				skipped++
-				continue
-			}
-			imported++
+		importer := ical.NewImporter(repo, zoneOf(c))
+		report, err := importer.Import(c.Request.Context(), cal, ical.ImportOptions{
+			Source:         source,
+			Column:         col,
+			ArchiveMissing: archiveMissing,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка импорта", "details": err.Error(), "report": report})
+			return
 		}
 
 		c.JSON(200, gin.H{
 			"status":   "success",
-			"imported": imported,
-			"skipped":  skipped,
+			"imported": report.Created,
+			"skipped":  report.Unchanged + report.Failed,
+			"report":   report,
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+    "net/http"
+    "strings"
+    "time"
+    "kanban-calendar/internal/datetime"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// zoneKey - ключ пояса запроса в gin.Context
+const zoneKey = "zone"
+
+// RequestZone - пояс, в котором запрос вводит и видит даты:
+// явный ?tz= или X-Timezone, иначе пояс пользователя из X-User, иначе пояс сервера
+func RequestZone(zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        loc, err := resolveZone(c, zones, c.GetHeader("X-User"))
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error(), "example": "Europe/Moscow"})
+            return
+        }
+        c.Set(zoneKey, loc)
+        c.Next()
+    }
+}
+
+// resolveZone - явно запрошенный пояс или пояс пользователя user
+func resolveZone(c *gin.Context, zones *datetime.Zones, user string) (*time.Location, error) {
+    name := c.Query("tz")
+    if name == "" {
+        name = c.GetHeader("X-Timezone")
+    }
+    if name != "" {
+        return datetime.LoadZone(name)
+    }
+    return zones.User(strings.TrimSpace(user)), nil
+}
+
+// zoneOf - пояс текущего запроса (см. RequestZone)
+func zoneOf(c *gin.Context) *time.Location {
+    if loc, ok := c.Get(zoneKey); ok {
+        return loc.(*time.Location)
+    }
+    return time.UTC
+}
+
+// GetTimeZones - пояс сервера и переопределения пользователей и чатов
+func GetTimeZones(repo *repository.TaskRepository, zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        overrides, err := repo.GetTimeZones(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "default":   zones.Default().String(),
+            "current":   zoneOf(c).String(),
+            "overrides": overrides,
+        })
+    }
+}
+
+// SetTimeZone - назначает пояс: PUT /api/timezones/user/<исполнитель> или /chat/<ID чата>
+func SetTimeZone(repo *repository.TaskRepository, zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        subjectType, subject, ok := zoneSubject(c)
+        if !ok {
+            return
+        }
+
+        var req models.SetTimeZoneRequest
+        if err := c.ShouldBindJSON(&req); err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+            return
+        }
+        loc, err := datetime.LoadZone(req.Zone)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "example": "Europe/Berlin"})
+            return
+        }
+
+        tz := &models.TimeZone{SubjectType: subjectType, Subject: subject, Zone: loc.String()}
+        if err := repo.SetTimeZone(c.Request.Context(), tz); err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        zones.Set(subjectType, subject, loc)
+        c.JSON(http.StatusOK, tz)
+    }
+}
+
+// DeleteTimeZone - возвращает пользователю или чату пояс сервера
+func DeleteTimeZone(repo *repository.TaskRepository, zones *datetime.Zones) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        subjectType, subject, ok := zoneSubject(c)
+        if !ok {
+            return
+        }
+        if err := repo.DeleteTimeZone(c.Request.Context(), subjectType, subject); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+            return
+        }
+        zones.Set(subjectType, subject, nil)
+        c.JSON(http.StatusOK, gin.H{"message": "Пояс сброшен", "zone": zones.Default().String()})
+    }
+}
+
+func zoneSubject(c *gin.Context) (string, string, bool) {
+    subjectType := c.Param("type")
+    subject := strings.TrimSpace(c.Param("subject"))
+    if subjectType != models.ZoneSubjectUser && subjectType != models.ZoneSubjectChat {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Тип должен быть user или chat"})
+        return "", "", false
+    }
+    if subject == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Не указан пользователь или чат"})
+        return "", "", false
+    }
+    return subjectType, subject, true
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "github.com/gin-gonic/gin"
+)
+
+// trashedTask - задача в корзине и момент, когда планировщик удалит ее навсегда
+type trashedTask struct {
+    models.Task
+    PurgeAt *time.Time `json:"purge_at,omitempty"`
+}
+
+// ArchiveTask - убирает задачу в архив (If-Match - как у PUT)
+func ArchiveTask(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        current, ok := loadTask(c, repo)
+        if !ok {
+            return
+        }
+        version, ok := ifMatchVersion(c, current)
+        if !ok {
+            return
+        }
+
+        if err := repo.ArchiveTask(c.Request.Context(), current.ID, version); err != nil {
+            if respondVersionMismatch(c, repo, current.ID, err) {
+                return
+            }
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка архивации задачи", "details": err.Error()})
+            return
+        }
+        respondTask(c, repo, current.ID, nil)
+    }
+}
+
+// RestoreTask - достает задачу из корзины (туда, где она была) или из архива на доску
+func RestoreTask(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+
+        wip, err := repo.RestoreTask(c.Request.Context(), id)
+        if err != nil {
+            if respondWIPLimit(c, err) {
+                return
+            }
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка восстановления задачи", "details": err.Error()})
+            return
+        }
+        respondTask(c, repo, id, wip)
+    }
+}
+
+// respondTask - задача после изменения вместе с ETag (и предупреждением о WIP-лимите, если есть)
+func respondTask(c *gin.Context, repo *repository.TaskRepository, id int, wip *models.WIPViolation) {
+    task, err := repo.GetTaskByID(c.Request.Context(), id)
+    if err != nil {
+        c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+        return
+    }
+    task.WIPWarning = wip
+    c.Header("ETag", taskETag(task))
+    c.JSON(http.StatusOK, task)
+}
+
+// GetTrash - корзина; retention - срок хранения (0 - задачи не удаляются сами)
+func GetTrash(repo *repository.TaskRepository, retention time.Duration) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        tasks, err := repo.GetTrash(c.Request.Context())
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения корзины", "details": err.Error()})
+            return
+        }
+
+        trashed := make([]trashedTask, len(tasks))
+        for i, task := range tasks {
+            trashed[i].Task = task
+            if retention > 0 && task.DeletedAt != nil {
+                purgeAt := task.DeletedAt.Add(retention)
+                trashed[i].PurgeAt = &purgeAt
+            }
+        }
+        c.JSON(http.StatusOK, gin.H{
+            "tasks":          trashed,
+            "count":          len(trashed),
+            "retention_days": int(retention.Hours() / 24),
+        })
+    }
+}
+
+// PurgeTask - удаляет задачу из корзины навсегда
+func PurgeTask(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, err := strconv.Atoi(c.Param("id"))
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID задачи"})
+            return
+        }
+        if err := repo.PurgeTask(c.Request.Context(), id); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Задача не найдена в корзине", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Задача удалена навсегда", "id": id})
+    }
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    "kanban-calendar/telegram"
+    "github.com/gin-gonic/gin"
+)
+
+// viewKey - ключ представления из ?view= в gin.Context
+const viewKey = "view"
+
+// viewOf - представление текущего запроса, если оно указано в ?view=
+func viewOf(c *gin.Context) *models.SavedView {
+    if view, ok := c.Get(viewKey); ok {
+        return view.(*models.SavedView)
+    }
+    return nil
+}
+
+// requestUser - пользователь из заголовка X-User
+func requestUser(c *gin.Context) string {
+    return strings.TrimSpace(c.GetHeader("X-User"))
+}
+
+// queryEnv - контекст запроса языка представлений: "me" - пользователь запроса, а без него - владелец представления
+func queryEnv(c *gin.Context, owner string) repository.QueryEnv {
+    user := requestUser(c)
+    if user == "" {
+        user = owner
+    }
+    return repository.QueryEnv{User: user, Zone: zoneOf(c), Now: time.Now()}
+}
+
+// applyTaskQuery - добавляет к фильтру запрос представления view и ?query=; условия обоих складываются
+func applyTaskQuery(c *gin.Context, filter *repository.TaskFilter, view *models.SavedView) *requestError {
+    var parts []string
+    owner := ""
+    if view != nil {
+        parts = append(parts, view.Query)
+        owner = view.Owner
+    }
+    parts = append(parts, c.Query("query"))
+    source := strings.TrimSpace(strings.Join(parts, " "))
+    if source == "" {
+        return nil
+    }
+
+    query, err := repository.ParseTaskQuery(source, queryEnv(c, owner))
+    if err != nil {
+        return queryError(err)
+    }
+    filter.Query = query
+    return nil
+}
+
+// queryError - 400 с условием, на котором сломался разбор запроса
+func queryError(err error) *requestError {
+    body := gin.H{"error": "Неверный запрос", "details": err.Error()}
+    var qErr *repository.QueryError
+    if errors.As(err, &qErr) && qErr.Token != "" {
+        body["token"] = qErr.Token
+    }
+    return &requestError{http.StatusBadRequest, body}
+}
+
+// viewFromRequest - проверенное тело создания или замены представления владельца X-User
+func viewFromRequest(c *gin.Context) (*models.SavedView, bool) {
+    owner := requestUser(c)
+    if owner == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Представления принадлежат пользователю: укажите заголовок X-User"})
+        return nil, false
+    }
+
+    var req models.SavedViewRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных", "details": err.Error()})
+        return nil, false
+    }
+    view := &models.SavedView{
+        Owner: owner,
+        Name:  strings.TrimSpace(req.Name),
+        Query: strings.TrimSpace(req.Query),
+        Sort:  strings.TrimSpace(req.Sort),
+    }
+    if view.Name == "" {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Не указано имя представления"})
+        return nil, false
+    }
+
+    // Запрос и сортировка проверяются при сохранении, чтобы сломанное представление не всплыло в календаре
+    if _, err := repository.ParseTaskQuery(view.Query, queryEnv(c, owner)); err != nil {
+        reqErr := queryError(err)
+        c.JSON(reqErr.status, reqErr.body)
+        return nil, false
+    }
+    if _, err := repository.ParseTaskSort(view.Sort); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return nil, false
+    }
+    return view, true
+}
+
+// viewID - ID представления из пути
+func viewID(c *gin.Context) (int, bool) {
+    id, err := strconv.Atoi(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID представления"})
+        return 0, false
+    }
+    return id, true
+}
+
+// GetViews - представления пользователя X-User
+func GetViews(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        owner := requestUser(c)
+        if owner == "" {
+            c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите заголовок X-User"})
+            return
+        }
+        views, err := repo.GetViews(c.Request.Context(), owner)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"views": views, "count": len(views)})
+    }
+}
+
+// GetView - представление по ID
+func GetView(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := viewID(c)
+        if !ok {
+            return
+        }
+        view, err := repo.GetView(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Представление не найдено", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, view)
+    }
+}
+
+// CreateView - сохраняет представление пользователя X-User
+func CreateView(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        view, ok := viewFromRequest(c)
+        if !ok {
+            return
+        }
+        if err := repo.CreateView(c.Request.Context(), view); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка сохранения представления", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusCreated, view)
+    }
+}
+
+// UpdateView - заменяет имя, запрос и сортировку; менять можно только свое представление
+func UpdateView(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := viewID(c)
+        if !ok {
+            return
+        }
+        view, ok := viewFromRequest(c)
+        if !ok {
+            return
+        }
+        view.ID = id
+        if err := repo.UpdateView(c.Request.Context(), view); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка обновления представления", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, view)
+    }
+}
+
+// DeleteView - удаляет представление пользователя X-User
+func DeleteView(repo *repository.TaskRepository) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        id, ok := viewID(c)
+        if !ok {
+            return
+        }
+        if err := repo.DeleteView(c.Request.Context(), id, requestUser(c)); err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Ошибка удаления представления", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Представление удалено", "id": id})
+    }
+}
+
+// SendViewToTelegram - отправляет задачи представления в чат бота
+func SendViewToTelegram(repo *repository.TaskRepository, bot *telegram.TelegramBot) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if bot == nil {
+            c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Telegram бот не настроен"})
+            return
+        }
+        id, ok := viewID(c)
+        if !ok {
+            return
+        }
+        view, err := repo.GetView(c.Request.Context(), id)
+        if err != nil {
+            c.JSON(errorStatus(err), gin.H{"error": "Представление не найдено", "details": err.Error()})
+            return
+        }
+
+        tasks, more, err := repo.RunView(c.Request.Context(), view, queryEnv(c, view.Owner), telegram.ViewLimit)
+        var qErr *repository.QueryError
+        switch {
+        case errors.As(err, &qErr):
+            reqErr := queryError(err)
+            c.JSON(reqErr.status, reqErr.body)
+            return
+        case err != nil:
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения задач", "details": err.Error()})
+            return
+        }
+
+        if err := bot.SendView(*view, tasks, more); err != nil {
+            c.JSON(http.StatusBadGateway, gin.H{"error": "Ошибка отправки в Telegram", "details": err.Error()})
+            return
+        }
+        c.JSON(http.StatusOK, gin.H{"message": "Представление отправлено", "count": len(tasks)})
+    }
+}
@@ -0,0 +1,198 @@
+// Package ical - разбор значений iCalendar (RFC 5545), которых нет в golang-ical:
+// даты с TZID и встроенными VTIMEZONE, даты без времени (VALUE=DATE), плавающее время и DURATION.
+package ical
+
+import (
+    "fmt"
+    "strings"
+    "time"
+    "kanban-calendar/internal/datetime"
+
+    ics "github.com/arran4/golang-ical"
+)
+
+const (
+    layoutUTC      = "20060102T150405Z"
+    layoutFloating = "20060102T150405"
+    layoutDate     = "20060102"
+)
+
+// DateTime - разобранное значение DATE или DATE-TIME
+type DateTime struct {
+    Time     time.Time
+    AllDay   bool // VALUE=DATE: время суток не задано
+    Floating bool // без Z и без TZID - интерпретировано в поясе по умолчанию
+}
+
+// Parser - разбирает даты одного календаря с учетом его VTIMEZONE
+type Parser struct {
+    zones    map[string]*vtimezone
+    location *time.Location // пояс для плавающего времени и дат без времени
+}
+
+// NewParser - конструктор; cal может быть nil, loc - пояс по умолчанию (nil - UTC)
+func NewParser(cal *ics.Calendar, loc *time.Location) *Parser {
+    if loc == nil {
+        loc = time.UTC
+    }
+    p := &Parser{zones: make(map[string]*vtimezone), location: loc}
+    if cal != nil {
+        for _, tz := range cal.Timezones() {
+            if zone := parseVTimezone(tz); zone != nil {
+                p.zones[zone.id] = zone
+            }
+        }
+    }
+    return p
+}
+
+// Parse - значение свойства DTSTART/DTEND/DUE/EXDATE... (для списков - первое)
+func (p *Parser) Parse(prop *ics.IANAProperty) (DateTime, error) {
+    values, err := p.ParseList(prop)
+    if err != nil {
+        return DateTime{}, err
+    }
+    return values[0], nil
+}
+
+// ParseList - все значения свойства, перечисленные через запятую (EXDATE, RDATE)
+func (p *Parser) ParseList(prop *ics.IANAProperty) ([]DateTime, error) {
+    if prop == nil {
+        return nil, fmt.Errorf("свойство не задано")
+    }
+
+    valueType := strings.ToUpper(param(prop, "VALUE"))
+    if valueType == "PERIOD" {
+        return nil, fmt.Errorf("%s: значения PERIOD не поддерживаются", prop.IANAToken)
+    }
+    tzid := param(prop, "TZID")
+
+    var result []DateTime
+    for _, value := range strings.Split(prop.Value, ",") {
+        dt, err := p.ParseValue(strings.TrimSpace(value), valueType == "DATE", tzid)
+        if err != nil {
+            return nil, fmt.Errorf("%s: %w", prop.IANAToken, err)
+        }
+        result = append(result, dt)
+    }
+    return result, nil
+}
+
+// ParseValue - одно значение: 20260102, 20260102T150405Z или 20260102T150405 (с tzid или плавающее)
+func (p *Parser) ParseValue(value string, isDate bool, tzid string) (DateTime, error) {
+    if isDate || len(value) == len(layoutDate) {
+        d, err := time.Parse(layoutDate, value)
+        if err != nil {
+            return DateTime{}, fmt.Errorf("неверная дата %q", value)
+        }
+        // Дата без времени - полночь в поясе по умолчанию, чтобы день не съехал при показе
+        t := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, p.location)
+        return DateTime{Time: t, AllDay: true}, nil
+    }
+
+    if strings.HasSuffix(value, "Z") {
+        t, err := time.Parse(layoutUTC, value)
+        if err != nil {
+            return DateTime{}, fmt.Errorf("неверное время %q", value)
+        }
+        return DateTime{Time: t}, nil
+    }
+
+    wall, err := time.Parse(layoutFloating, value)
+    if err != nil {
+        return DateTime{}, fmt.Errorf("неверное время %q", value)
+    }
+    if tzid == "" {
+        return DateTime{Time: inLocation(wall, p.location), Floating: true}, nil
+    }
+
+    t, err := p.inZone(wall, tzid)
+    if err != nil {
+        return DateTime{}, err
+    }
+    return DateTime{Time: t}, nil
+}
+
+// inZone - переводит настенное время в пояс TZID: сначала база IANA, затем VTIMEZONE календаря
+func (p *Parser) inZone(wall time.Time, tzid string) (time.Time, error) {
+    if loc := loadLocation(tzid); loc != nil {
+        return inLocation(wall, loc), nil
+    }
+    if zone, ok := p.zones[tzid]; ok {
+        return zone.at(wall), nil
+    }
+    return time.Time{}, fmt.Errorf("неизвестный часовой пояс %q", tzid)
+}
+
+// loadLocation - IANA-пояс по TZID; понимает префиксы вида /mozilla.org/20050126_1/Europe/Moscow
+func loadLocation(tzid string) *time.Location {
+    name := strings.Trim(tzid, "/")
+    for name != "" {
+        if loc, err := datetime.LoadZone(name); err == nil {
+            return loc
+        }
+        i := strings.Index(name, "/")
+        if i < 0 {
+            break
+        }
+        name = name[i+1:]
+    }
+    return nil
+}
+
+// inLocation - те же часы и минуты, но в поясе loc
+func inLocation(wall time.Time, loc *time.Location) time.Time {
+    return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// param - первое значение параметра свойства
+func param(prop *ics.IANAProperty, name string) string {
+    for key, values := range prop.ICalParameters {
+        if strings.EqualFold(key, name) && len(values) > 0 {
+            return values[0]
+        }
+    }
+    return ""
+}
+
+// Span - начало и конец компонента: DTEND (или DUE), иначе DTSTART + DURATION,
+// иначе по правилам RFC 5545 - один день для даты и нулевая длительность для времени
+func (p *Parser) Span(comp *ics.ComponentBase) (start, end *DateTime, err error) {
+    if prop := comp.GetProperty(ics.ComponentPropertyDtStart); prop != nil {
+        dt, err := p.Parse(prop)
+        if err != nil {
+            return nil, nil, err
+        }
+        start = &dt
+    }
+
+    endProp := comp.GetProperty(ics.ComponentPropertyDtEnd)
+    if endProp == nil {
+        endProp = comp.GetProperty(ics.ComponentPropertyDue)
+    }
+    if endProp != nil {
+        dt, err := p.Parse(endProp)
+        if err != nil {
+            return nil, nil, err
+        }
+        end = &dt
+        return start, end, nil
+    }
+
+    if start == nil {
+        return nil, nil, nil
+    }
+    if prop := comp.GetProperty(ics.ComponentPropertyDuration); prop != nil {
+        d, err := ParseDuration(prop.Value)
+        if err != nil {
+            return nil, nil, err
+        }
+        end = &DateTime{Time: d.AddTo(start.Time), AllDay: start.AllDay, Floating: start.Floating}
+        return start, end, nil
+    }
+    if start.AllDay {
+        end = &DateTime{Time: start.Time.AddDate(0, 0, 1), AllDay: true}
+        return start, end, nil
+    }
+    return start, start, nil
+}
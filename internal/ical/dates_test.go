@@ -0,0 +1,183 @@
+package ical
+
+import (
+    "strings"
+    "testing"
+    "time"
+    _ "time/tzdata"
+    ics "github.com/arran4/golang-ical"
+)
+
+// customZoneCalendar - календарь с собственным VTIMEZONE, которого нет в базе IANA
+const customZoneCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//test//test//RU
+BEGIN:VTIMEZONE
+TZID:Custom Standard Time
+BEGIN:STANDARD
+DTSTART:19700101T000000
+TZOFFSETFROM:+0500
+TZOFFSETTO:+0500
+END:STANDARD
+END:VTIMEZONE
+END:VCALENDAR
+`
+
+func TestParseValue(t *testing.T) {
+    moscow, err := time.LoadLocation("Europe/Moscow")
+    if err != nil {
+        t.Fatal(err)
+    }
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    cal, err := ics.ParseCalendar(strings.NewReader(strings.ReplaceAll(customZoneCalendar, "\n", "\r\n")))
+    if err != nil {
+        t.Fatal(err)
+    }
+    p := NewParser(cal, moscow)
+
+    tests := []struct {
+        name    string
+        value   string
+        isDate  bool
+        tzid    string
+        want    DateTime
+        wantErr bool
+    }{
+        {
+            name:  "UTC",
+            value: "20260120T150000Z",
+            want:  DateTime{Time: time.Date(2026, 1, 20, 15, 0, 0, 0, time.UTC)},
+        },
+        {
+            name:   "VALUE=DATE - полночь в поясе по умолчанию",
+            value:  "20260120",
+            isDate: true,
+            want:   DateTime{Time: time.Date(2026, 1, 20, 0, 0, 0, 0, moscow), AllDay: true},
+        },
+        {
+            name:  "дата без VALUE=DATE",
+            value: "20260120",
+            want:  DateTime{Time: time.Date(2026, 1, 20, 0, 0, 0, 0, moscow), AllDay: true},
+        },
+        {
+            name:  "плавающее время",
+            value: "20260120T090000",
+            want:  DateTime{Time: time.Date(2026, 1, 20, 9, 0, 0, 0, moscow), Floating: true},
+        },
+        {
+            name:  "TZID из базы IANA",
+            value: "20260720T090000",
+            tzid:  "Europe/Berlin",
+            want:  DateTime{Time: time.Date(2026, 7, 20, 9, 0, 0, 0, berlin)},
+        },
+        {
+            name:  "TZID с префиксом mozilla.org",
+            value: "20260120T090000",
+            tzid:  "/mozilla.org/20050126_1/Europe/Berlin",
+            want:  DateTime{Time: time.Date(2026, 1, 20, 9, 0, 0, 0, berlin)},
+        },
+        {
+            name:  "TZID из VTIMEZONE календаря",
+            value: "20260120T090000",
+            tzid:  "Custom Standard Time",
+            want:  DateTime{Time: time.Date(2026, 1, 20, 4, 0, 0, 0, time.UTC)},
+        },
+        {name: "неизвестный TZID", value: "20260120T090000", tzid: "Nowhere/Atlantis", wantErr: true},
+        {name: "неверная дата", value: "20261320", isDate: true, wantErr: true},
+        {name: "неверное время UTC", value: "20260120T250000Z", wantErr: true},
+        {name: "мусор", value: "tomorrow", wantErr: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := p.ParseValue(tt.value, tt.isDate, tt.tzid)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("ParseValue(%q) = %+v, ожидалась ошибка", tt.value, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("ParseValue(%q): %v", tt.value, err)
+            }
+            if !got.Time.Equal(tt.want.Time) || got.AllDay != tt.want.AllDay || got.Floating != tt.want.Floating {
+                t.Fatalf("ParseValue(%q) = %+v, ожидалось %+v", tt.value, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestParseDuration(t *testing.T) {
+    tests := []struct {
+        in   string
+        want Duration
+        str  string // "" - ожидается ошибка
+    }{
+        {"P1W", Duration{Weeks: 1}, "P1W"},
+        {"P2D", Duration{Days: 2}, "P2D"},
+        {"PT1H30M", Duration{Clock: 90 * time.Minute}, "PT1H30M"},
+        {"-P1DT12H", Duration{Negative: true, Days: 1, Clock: 12 * time.Hour}, "-P1DT12H"},
+        {"+PT15M", Duration{Clock: 15 * time.Minute}, "PT15M"},
+        {"PT0S", Duration{}, "PT0S"},
+        {"PT90M", Duration{Clock: 90 * time.Minute}, "PT1H30M"},
+        {"", Duration{}, ""},
+        {"P", Duration{}, ""},
+        {"1D", Duration{}, ""},
+        {"PT", Duration{}, ""},
+        {"P1H", Duration{}, ""},
+        {"PT1D", Duration{}, ""},
+        {"P1DTT1H", Duration{}, ""},
+        {"P1", Duration{}, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.in, func(t *testing.T) {
+            got, err := ParseDuration(tt.in)
+            if tt.str == "" {
+                if err == nil {
+                    t.Fatalf("ParseDuration(%q) = %+v, ожидалась ошибка", tt.in, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("ParseDuration(%q): %v", tt.in, err)
+            }
+            if got != tt.want {
+                t.Fatalf("ParseDuration(%q) = %+v, ожидалось %+v", tt.in, got, tt.want)
+            }
+            if s := got.String(); s != tt.str {
+                t.Fatalf("String() = %q, ожидалось %q", s, tt.str)
+            }
+        })
+    }
+}
+
+func TestDurationAddTo(t *testing.T) {
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    // 29.03.2026 в Берлине переводят часы: сутки короче 24 часов
+    start := time.Date(2026, 3, 28, 10, 0, 0, 0, berlin)
+    tests := []struct {
+        in   string
+        want time.Time
+    }{
+        {"P1D", time.Date(2026, 3, 29, 10, 0, 0, 0, berlin)},
+        {"PT24H", time.Date(2026, 3, 29, 11, 0, 0, 0, berlin)},
+        {"P1W", time.Date(2026, 4, 4, 10, 0, 0, 0, berlin)},
+        {"-P1DT2H", time.Date(2026, 3, 27, 8, 0, 0, 0, berlin)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.in, func(t *testing.T) {
+            d, err := ParseDuration(tt.in)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got := d.AddTo(start); !got.Equal(tt.want) {
+                t.Fatalf("%s.AddTo(%v) = %v, ожидалось %v", tt.in, start, got, tt.want)
+            }
+        })
+    }
+}
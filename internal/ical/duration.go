@@ -0,0 +1,118 @@
+package ical
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// Duration - значение DURATION (RFC 5545, 3.3.6). Недели и дни - номинальные:
+// при переходе на летнее время сохраняется время суток, а не число часов
+type Duration struct {
+    Negative bool
+    Weeks    int
+    Days     int
+    Clock    time.Duration // часы, минуты и секунды
+}
+
+// ParseDuration - разбирает строки вида P1W, P2D, PT1H30M, -P1DT12H
+func ParseDuration(s string) (Duration, error) {
+    var d Duration
+    rest := s
+    if rest != "" && (rest[0] == '+' || rest[0] == '-') {
+        d.Negative = rest[0] == '-'
+        rest = rest[1:]
+    }
+    if len(rest) < 2 || rest[0] != 'P' {
+        return Duration{}, fmt.Errorf("неверная длительность %q", s)
+    }
+    rest = rest[1:]
+
+    inTime := false
+    seen := false
+    for rest != "" {
+        if rest[0] == 'T' {
+            if inTime {
+                return Duration{}, fmt.Errorf("неверная длительность %q", s)
+            }
+            inTime = true
+            rest = rest[1:]
+            continue
+        }
+
+        i := 0
+        for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+            i++
+        }
+        if i == 0 || i == len(rest) {
+            return Duration{}, fmt.Errorf("неверная длительность %q", s)
+        }
+        n, err := strconv.Atoi(rest[:i])
+        if err != nil {
+            return Duration{}, fmt.Errorf("неверная длительность %q", s)
+        }
+
+        switch unit := rest[i]; {
+        case unit == 'W' && !inTime:
+            d.Weeks = n
+        case unit == 'D' && !inTime:
+            d.Days = n
+        case unit == 'H' && inTime:
+            d.Clock += time.Duration(n) * time.Hour
+        case unit == 'M' && inTime:
+            d.Clock += time.Duration(n) * time.Minute
+        case unit == 'S' && inTime:
+            d.Clock += time.Duration(n) * time.Second
+        default:
+            return Duration{}, fmt.Errorf("неверная длительность %q", s)
+        }
+        seen = true
+        rest = rest[i+1:]
+    }
+    if !seen {
+        return Duration{}, fmt.Errorf("неверная длительность %q", s)
+    }
+    return d, nil
+}
+
+// AddTo - прибавляет длительность к t (дни - по календарю пояса t)
+func (d Duration) AddTo(t time.Time) time.Time {
+    days, clock := d.Weeks*7+d.Days, d.Clock
+    if d.Negative {
+        days, clock = -days, -clock
+    }
+    return t.AddDate(0, 0, days).Add(clock)
+}
+
+// String - каноничная запись длительности
+func (d Duration) String() string {
+    s := "P"
+    if d.Negative {
+        s = "-P"
+    }
+    if d.Weeks > 0 {
+        s += strconv.Itoa(d.Weeks) + "W"
+    }
+    if d.Days > 0 {
+        s += strconv.Itoa(d.Days) + "D"
+    }
+    if d.Clock > 0 {
+        s += "T"
+        h := int(d.Clock / time.Hour)
+        m := int(d.Clock % time.Hour / time.Minute)
+        sec := int(d.Clock % time.Minute / time.Second)
+        if h > 0 {
+            s += strconv.Itoa(h) + "H"
+        }
+        if m > 0 {
+            s += strconv.Itoa(m) + "M"
+        }
+        if sec > 0 {
+            s += strconv.Itoa(sec) + "S"
+        }
+    }
+    if s == "P" || s == "-P" {
+        s += "T0S"
+    }
+    return s
+}
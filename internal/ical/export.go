@@ -0,0 +1,292 @@
+package ical
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/models"
+)
+
+// Во что превращается задача при экспорте
+const (
+    ComponentAuto  = "auto"  // с датой начала - VEVENT, иначе VTODO (или как задачу сохранил CalDAV-клиент)
+    ComponentEvent = "event" // всегда VEVENT
+    ComponentTodo  = "todo"  // всегда VTODO
+)
+
+// uidDomain - правая часть UID задач, созданных в канбане
+const uidDomain = "kanban-calendar"
+
+// DefaultAlarms - за сколько до дедлайна напоминает календарь
+var DefaultAlarms = []time.Duration{24 * time.Hour, time.Hour}
+
+// ExportOptions - параметры выгрузки задач в .ics
+type ExportOptions struct {
+    Name      string          // X-WR-CALNAME
+    Component string          // ComponentAuto, ComponentEvent или ComponentTodo
+    Location  *time.Location  // пояс для дат на весь день
+    Alarms    []time.Duration // напоминания до дедлайна для незавершенных задач
+    Resource  bool            // ресурс CalDAV: без METHOD (RFC 4791, 4.1)
+}
+
+// ValidComponent - проверка значения ?type=
+func ValidComponent(component string) bool {
+    switch component {
+    case ComponentAuto, ComponentEvent, ComponentTodo:
+        return true
+    }
+    return false
+}
+
+// TaskUID - стабильный UID задачи: исходный UID импортированного события или task-<id>@kanban-calendar
+func TaskUID(task *models.Task) string {
+    if task.ExternalUID != "" {
+        return task.ExternalUID
+    }
+    return fmt.Sprintf("task-%d@%s", task.ID, uidDomain)
+}
+
+// TaskIDFromUID - ID задачи из UID, выданного сервером (task-<id>@kanban-calendar); 0 - UID чужой
+func TaskIDFromUID(uid string) int {
+    rest, ok := strings.CutPrefix(uid, "task-")
+    if !ok {
+        return 0
+    }
+    rest, ok = strings.CutSuffix(rest, "@"+uidDomain)
+    if !ok {
+        return 0
+    }
+    id, err := strconv.Atoi(rest)
+    if err != nil || id <= 0 || strconv.Itoa(id) != rest {
+        return 0
+    }
+    return id
+}
+
+// Export - календарь из задач
+func Export(tasks []models.Task, opts ExportOptions) *ics.Calendar {
+    if opts.Location == nil {
+        opts.Location = time.UTC
+    }
+    if opts.Component == "" {
+        opts.Component = ComponentAuto
+    }
+
+    cal := ics.NewCalendar()
+    cal.SetProductId("-//kanban-calendar//Kanban Calendar API//RU")
+    if !opts.Resource {
+        cal.SetMethod(ics.MethodPublish)
+    }
+    if opts.Name != "" {
+        cal.SetXWRCalName(opts.Name)
+    }
+
+    for i := range tasks {
+        task := &tasks[i]
+        if asTodo(task, opts.Component) {
+            addTodo(cal, task, opts)
+        } else {
+            addEvent(cal, task, opts)
+        }
+    }
+    return cal
+}
+
+// ComponentOf - компонент задачи при выгрузке с ComponentAuto: VEVENT или VTODO
+func ComponentOf(task *models.Task) string {
+    if asTodo(task, ComponentAuto) {
+        return string(ics.ComponentVTodo)
+    }
+    return string(ics.ComponentVEvent)
+}
+
+func asTodo(task *models.Task, component string) bool {
+    switch component {
+    case ComponentTodo:
+        return true
+    case ComponentEvent:
+        return false
+    }
+    if task.CalDAVComponent != "" {
+        return task.CalDAVComponent == ComponentTodo // как ее сохранил CalDAV-клиент
+    }
+    return task.StartDate == nil
+}
+
+// addEvent - задача как VEVENT; без дат событие ставится на дедлайн или дату создания
+func addEvent(cal *ics.Calendar, task *models.Task, opts ExportOptions) {
+    event := cal.AddEvent(TaskUID(task))
+    setCommon(&event.ComponentBase, task)
+    event.SetStatus(ics.ObjectStatusConfirmed)
+    if p := exportPriority(task); p != 0 {
+        event.SetPriority(p)
+    }
+
+    start := task.StartDate
+    if start == nil {
+        start = task.Deadline
+    }
+    if start == nil {
+        start = &task.CreatedAt
+    }
+    end := task.EndDate
+    if end == nil {
+        end = task.Deadline
+    }
+
+    if task.AllDay {
+        event.SetAllDayStartAt(start.In(opts.Location))
+        if end != nil && end.After(*start) {
+            event.SetAllDayEndAt(end.In(opts.Location))
+        }
+    } else {
+        event.SetStartAt(*start)
+        if end != nil && !end.Before(*start) {
+            event.SetEndAt(*end)
+        }
+    }
+
+    setRecurrence(&event.ComponentBase, task)
+    if task.Deadline != nil && !task.IsDone {
+        for _, before := range opts.Alarms {
+            addAlarm(event.AddAlarm(), task, before)
+        }
+    }
+}
+
+// addTodo - задача как VTODO: DUE - дедлайн, STATUS и PERCENT-COMPLETE - по колонке
+// (незавершенная сохраняет процент из календаря)
+func addTodo(cal *ics.Calendar, task *models.Task, opts ExportOptions) {
+    todo := cal.AddTodo(TaskUID(task))
+    setCommon(&todo.ComponentBase, task)
+    if p := exportPriority(task); p != 0 {
+        todo.SetPriority(p)
+    }
+
+    if task.StartDate != nil {
+        if task.AllDay {
+            todo.SetAllDayStartAt(task.StartDate.In(opts.Location))
+        } else {
+            todo.SetStartAt(*task.StartDate)
+        }
+    }
+    if task.Deadline != nil {
+        if task.AllDay {
+            todo.SetAllDayDueAt(task.Deadline.In(opts.Location))
+        } else {
+            todo.SetDueAt(*task.Deadline)
+        }
+    }
+
+    switch {
+    case task.IsDone:
+        todo.SetStatus(ics.ObjectStatusCompleted)
+        todo.SetCompletedAt(task.UpdatedAt)
+        todo.SetPercentComplete(100)
+    case task.Status == models.StatusInProgress:
+        todo.SetStatus(ics.ObjectStatusInProcess)
+    default:
+        todo.SetStatus(ics.ObjectStatusNeedsAction)
+    }
+    if !task.IsDone && task.ExternalPercent != nil && *task.ExternalPercent < 100 {
+        todo.SetPercentComplete(*task.ExternalPercent)
+    }
+
+    if task.StartDate != nil || task.Deadline != nil {
+        setRecurrence(&todo.ComponentBase, task)
+    }
+    if task.Deadline != nil && !task.IsDone {
+        for _, before := range opts.Alarms {
+            addAlarm(todo.AddAlarm(), task, before)
+        }
+    }
+}
+
+// setCommon - свойства, общие для VEVENT и VTODO
+func setCommon(comp *ics.ComponentBase, task *models.Task) {
+    comp.SetDtStampTime(task.UpdatedAt)
+    comp.SetCreatedTime(task.CreatedAt)
+    comp.SetModifiedAt(task.UpdatedAt)
+    if task.ExternalSequence != nil {
+        comp.SetSequence(*task.ExternalSequence)
+    }
+    comp.SetSummary(task.Title)
+    if task.Description != "" {
+        comp.SetDescription(task.Description)
+    }
+    for _, tag := range task.Tags {
+        comp.AddCategory(tag)
+    }
+    comp.SetProperty(ics.ComponentProperty("X-KANBAN-STATUS"), string(task.Status))
+    if task.Assignee != "" {
+        comp.SetProperty(ics.ComponentProperty("X-KANBAN-ASSIGNEE"), task.Assignee)
+    }
+}
+
+// setRecurrence - RRULE и EXDATE повторяющейся задачи. У выполненного вхождения правила нет:
+// серию продолжает следующее вхождение под своим UID (как в GetCalendarEvents)
+func setRecurrence(comp *ics.ComponentBase, task *models.Task) {
+    if task.RRule == "" || task.IsDone {
+        return
+    }
+    comp.AddRrule(task.RRule)
+    if len(task.ExDates) == 0 {
+        return
+    }
+    values := make([]string, 0, len(task.ExDates))
+    for _, ex := range task.ExDates {
+        values = append(values, ex.UTC().Format(layoutUTC))
+    }
+    comp.AddExdate(strings.Join(values, ","))
+}
+
+// addAlarm - напоминание за before до дедлайна. У разовой задачи время абсолютное, чтобы не зависеть
+// от DTEND; у повторяющейся - относительно конца (DUE), чтобы срабатывало в каждом вхождении
+func addAlarm(alarm *ics.VAlarm, task *models.Task, before time.Duration) {
+    alarm.SetAction(ics.ActionDisplay)
+    if task.RRule != "" {
+        trigger := Duration{Negative: true, Clock: before}
+        alarm.SetTrigger(trigger.String(), &ics.KeyValues{Key: string(ics.ParameterRelated), Value: []string{"END"}})
+    } else {
+        at := task.Deadline.Add(-before).UTC().Format(layoutUTC)
+        alarm.SetTrigger(at, &ics.KeyValues{Key: string(ics.ParameterValue), Value: []string{"DATE-TIME"}})
+    }
+    alarm.SetProperty(ics.ComponentPropertyDescription, "Дедлайн: "+task.Title)
+}
+
+// exportPriority - PRIORITY из календаря, пока приоритет задачи ему соответствует, иначе по приоритету
+func exportPriority(task *models.Task) int {
+    if task.ExternalPriority != 0 && taskPriority(task.ExternalPriority) == task.Priority {
+        return task.ExternalPriority
+    }
+    return icalPriority(task.Priority)
+}
+
+// icalPriority - приоритет задачи в шкале RFC 5545 (1 - высший, 9 - низший, 0 - не задан)
+func icalPriority(priority string) int {
+    switch priority {
+    case "high":
+        return 1
+    case "medium":
+        return 5
+    case "low":
+        return 9
+    }
+    return 0
+}
+
+// taskPriority - приоритет задачи по PRIORITY: 1-4 - high, 5 - medium, 6-9 - low
+func taskPriority(priority int) string {
+    switch {
+    case priority >= 1 && priority <= 4:
+        return "high"
+    case priority == 5:
+        return "medium"
+    case priority >= 6 && priority <= 9:
+        return "low"
+    }
+    return ""
+}
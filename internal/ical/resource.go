@@ -0,0 +1,130 @@
+package ical
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/models"
+)
+
+// Resource - задача из ресурса CalDAV: один UID, основной компонент без RECURRENCE-ID
+type Resource struct {
+    Task        *models.Task // Status - X-KANBAN-STATUS, если клиент его сохранил
+    Component   string       // ComponentEvent или ComponentTodo
+    Status      string       // STATUS задачи VTODO (см. TodoStatus); у VEVENT пусто
+    HasAssignee bool         // клиент сохранил X-KANBAN-ASSIGNEE; иначе исполнитель остается прежним
+}
+
+// ParseResource - разбирает тело PUT. Измененные вхождения серии (RECURRENCE-ID) не поддерживаются
+// и пропускаются; другой UID в том же ресурсе - ошибка
+func ParseResource(cal *ics.Calendar, loc *time.Location) (*Resource, error) {
+    var res *Resource
+    parser := NewParser(cal, loc)
+    for _, comp := range cal.Components {
+        var base *ics.ComponentBase
+        component := ComponentEvent
+        switch c := comp.(type) {
+        case *ics.VEvent:
+            base = &c.ComponentBase
+        case *ics.VTodo:
+            base, component = &c.ComponentBase, ComponentTodo
+        default:
+            continue
+        }
+
+        if res != nil {
+            if base.Id() != TaskUID(res.Task) {
+                return nil, fmt.Errorf("в ресурсе больше одного UID")
+            }
+            continue
+        }
+        if base.GetProperty(ics.ComponentPropertyRecurrenceId) != nil {
+            continue
+        }
+
+        task, err := componentTask(parser, base, component == ComponentTodo)
+        if err != nil {
+            return nil, err
+        }
+        res = &Resource{Task: task, Component: component}
+        resourceFields(res, base)
+    }
+
+    if res == nil {
+        return nil, fmt.Errorf("в ресурсе нет VEVENT или VTODO")
+    }
+    return res, nil
+}
+
+// resourceFields - свойства, которые канбан сам выгружает: теги, статус и исполнитель
+func resourceFields(res *Resource, comp *ics.ComponentBase) {
+    task := res.Task
+    for _, prop := range comp.GetProperties(ics.ComponentPropertyCategories) {
+        for _, tag := range strings.Split(prop.Value, ",") {
+            if tag = strings.TrimSpace(tag); tag != "" {
+                task.Tags = append(task.Tags, tag)
+            }
+        }
+    }
+    if task.Tags == nil {
+        task.Tags = []string{}
+    }
+    if res.Component == ComponentTodo {
+        res.Status = TodoStatus(comp)
+    }
+    if prop := comp.GetProperty(ics.ComponentProperty("X-KANBAN-STATUS")); prop != nil {
+        task.Status = models.TaskStatus(prop.Value)
+    }
+    if prop := comp.GetProperty(ics.ComponentProperty("X-KANBAN-ASSIGNEE")); prop != nil {
+        task.Assignee = prop.Value
+        res.HasAssignee = true
+    }
+}
+
+// StatusColumn - колонка доски для задачи из календаря. slug (X-KANBAN-STATUS или текущая колонка)
+// сохраняется, если согласуется со STATUS: выполненная (или отмененная) задача остается в своей
+// "готовой" колонке, открытая - в своей рабочей. Иначе берется колонка по умолчанию для STATUS.
+// Пустой результат - колонку не менять
+func StatusColumn(columns []models.BoardColumn, slug models.TaskStatus, status string) models.TaskStatus {
+    var current *models.BoardColumn
+    for i := range columns {
+        if columns[i].Slug == slug {
+            current = &columns[i]
+        }
+    }
+    find := func(match func(col *models.BoardColumn) bool) models.TaskStatus {
+        for i := range columns {
+            if match(&columns[i]) {
+                return columns[i].Slug
+            }
+        }
+        return ""
+    }
+
+    switch status {
+    case string(ics.ObjectStatusCompleted), string(ics.ObjectStatusCancelled):
+        if current != nil && current.IsDone {
+            return current.Slug
+        }
+        return find(func(col *models.BoardColumn) bool { return col.IsDone })
+    case string(ics.ObjectStatusInProcess), string(ics.ObjectStatusNeedsAction):
+        if current != nil && !current.IsDone {
+            return current.Slug
+        }
+        preferred := models.StatusTodo
+        if status == string(ics.ObjectStatusInProcess) {
+            preferred = models.StatusInProgress
+        }
+        if s := find(func(col *models.BoardColumn) bool { return col.Slug == preferred && !col.IsDone }); s != "" {
+            return s
+        }
+        return find(func(col *models.BoardColumn) bool { return !col.IsDone })
+    }
+
+    if current != nil {
+        return current.Slug
+    }
+    return ""
+}
@@ -0,0 +1,48 @@
+package ical
+
+import (
+    "testing"
+    "kanban-calendar/internal/models"
+)
+
+func TestStatusColumn(t *testing.T) {
+    columns := []models.BoardColumn{
+        {Slug: models.StatusTodo},
+        {Slug: models.StatusInProgress},
+        {Slug: "review"},
+        {Slug: models.StatusDone, IsDone: true},
+        {Slug: "rejected", IsDone: true},
+    }
+    tests := []struct {
+        name   string
+        slug   models.TaskStatus
+        status string
+        want   models.TaskStatus
+    }{
+        {"выполнена - готовая колонка", models.StatusTodo, "COMPLETED", models.StatusDone},
+        {"выполнена и уже готова", "rejected", "COMPLETED", "rejected"},
+        {"отменена - готовая колонка", "review", "CANCELLED", models.StatusDone},
+        {"в работе", models.StatusTodo, "IN-PROCESS", models.StatusTodo},
+        {"переоткрыта в работу", models.StatusDone, "IN-PROCESS", models.StatusInProgress},
+        {"переоткрыта", "rejected", "NEEDS-ACTION", models.StatusTodo},
+        {"открытая колонка остается", "review", "NEEDS-ACTION", "review"},
+        {"новая задача без статуса", "", "", ""},
+        {"новая выполненная", "", "COMPLETED", models.StatusDone},
+        {"без статуса колонка не меняется", "review", "", "review"},
+        {"неизвестный статус", models.StatusDone, "TENTATIVE", models.StatusDone},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := StatusColumn(columns, tt.slug, tt.status); got != tt.want {
+                t.Fatalf("StatusColumn(%q, %q) = %q, ожидалось %q", tt.slug, tt.status, got, tt.want)
+            }
+        })
+    }
+
+    t.Run("на доске нет колонки по умолчанию", func(t *testing.T) {
+        custom := []models.BoardColumn{{Slug: "backlog"}, {Slug: "shipped", IsDone: true}}
+        if got := StatusColumn(custom, "shipped", "IN-PROCESS"); got != "backlog" {
+            t.Fatalf("StatusColumn = %q, ожидалось backlog", got)
+        }
+    })
+}
@@ -0,0 +1,105 @@
+package ical
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/models"
+)
+
+// maxFeedSize - больше этого календарь подписки не скачиваем
+const maxFeedSize = 20 << 20
+
+// SubscriptionSource - ключ источника задач подписки (UID уникален внутри него)
+func SubscriptionSource(id int) string {
+    return fmt.Sprintf("subscription:%d", id)
+}
+
+// SyncSubscription - скачивает календарь подписки условным запросом (ETag / If-Modified-Since),
+// прогоняет через Import и записывает итог в подписку
+func (im *Importer) SyncSubscription(ctx context.Context, sub *models.CalendarSubscription) models.SubscriptionSync {
+    res := im.syncSubscription(ctx, sub)
+    if err := im.repo.RecordSubscriptionSync(ctx, sub.ID, res); err != nil {
+        res.Status, res.Error = models.SyncStatusError, "не удалось сохранить итог: "+err.Error()
+    }
+    return res
+}
+
+func (im *Importer) syncSubscription(ctx context.Context, sub *models.CalendarSubscription) models.SubscriptionSync {
+    failed := func(err error) models.SubscriptionSync {
+        return models.SubscriptionSync{Status: models.SyncStatusError, Error: err.Error()}
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, FeedURL(sub.URL), nil)
+    if err != nil {
+        return failed(err)
+    }
+    req.Header.Set("Accept", "text/calendar")
+    if sub.ETag != "" {
+        req.Header.Set("If-None-Match", sub.ETag)
+    }
+    if sub.LastModified != "" {
+        req.Header.Set("If-Modified-Since", sub.LastModified)
+    }
+
+    resp, err := im.client.Do(req)
+    if err != nil {
+        return failed(err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotModified {
+        return models.SubscriptionSync{Status: models.SyncStatusNotModified}
+    }
+    if resp.StatusCode != http.StatusOK {
+        return failed(fmt.Errorf("сервер календаря ответил %s", resp.Status))
+    }
+
+    body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+    if err != nil {
+        return failed(err)
+    }
+    if len(body) > maxFeedSize {
+        return failed(fmt.Errorf("календарь больше %d МБ", maxFeedSize>>20))
+    }
+
+    cal, err := ics.ParseCalendar(bytes.NewReader(body))
+    if err != nil {
+        return failed(fmt.Errorf("ошибка формата .ics: %w", err))
+    }
+
+    col, err := im.repo.ImportColumn(ctx, sub.BoardID)
+    if err != nil {
+        return failed(err)
+    }
+
+    report, err := im.Import(ctx, cal, ImportOptions{
+        Source:         SubscriptionSource(sub.ID),
+        Column:         col,
+        Assignee:       sub.DefaultAssignee,
+        ArchiveMissing: sub.ArchiveMissing,
+    })
+    if err != nil {
+        return failed(err)
+    }
+
+    return models.SubscriptionSync{
+        Status:       models.SyncStatusOK,
+        ETag:         resp.Header.Get("ETag"),
+        LastModified: resp.Header.Get("Last-Modified"),
+        Report:       report.Summary(),
+    }
+}
+
+// FeedURL - webcal:// - это тот же https
+func FeedURL(url string) string {
+    if rest, ok := strings.CutPrefix(url, "webcal://"); ok {
+        return "https://" + rest
+    }
+    return url
+}
@@ -0,0 +1,407 @@
+package ical
+
+import (
+    "context"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/recurrence"
+    "kanban-calendar/internal/repository"
+)
+
+// Итог синхронизации одного события
+const (
+    ResultCreated   = "created"
+    ResultUpdated   = "updated"
+    ResultUnchanged = "unchanged"
+    ResultFailed    = "failed"
+    ResultArchived  = "archived"
+)
+
+// ImportOptions - параметры синхронизации календаря с задачами
+type ImportOptions struct {
+    Source         string              // ключ источника: имя файла, подписка; UID уникален внутри него
+    Column         *models.BoardColumn // куда кладутся новые задачи
+    Assignee       string              // исполнитель новых задач
+    ArchiveMissing bool                // убирать в архив задачи, чьих событий больше нет в календаре
+}
+
+// EventResult - что произошло с одним событием
+type EventResult struct {
+    UID    string `json:"uid"`
+    Title  string `json:"title,omitempty"`
+    Result string `json:"result"`
+    TaskID int    `json:"task_id,omitempty"`
+    Reason string `json:"reason,omitempty"`
+}
+
+// ImportReport - отчет о синхронизации
+type ImportReport struct {
+    Source    string        `json:"source"`
+    Created   int           `json:"created"`
+    Updated   int           `json:"updated"`
+    Unchanged int           `json:"unchanged"`
+    Failed    int           `json:"failed"`
+    Archived  int           `json:"archived"`
+    Events    []EventResult `json:"events"`
+}
+
+func (r *ImportReport) add(res EventResult) {
+    switch res.Result {
+    case ResultCreated:
+        r.Created++
+    case ResultUpdated:
+        r.Updated++
+    case ResultUnchanged:
+        r.Unchanged++
+    case ResultFailed:
+        r.Failed++
+    case ResultArchived:
+        r.Archived++
+    }
+    r.Events = append(r.Events, res)
+}
+
+// Summary - отчет без неизмененных, созданных и обновленных событий (для хранения в БД)
+func (r *ImportReport) Summary() *ImportReport {
+    summary := *r
+    summary.Events = []EventResult{}
+    for _, res := range r.Events {
+        if res.Result == ResultFailed || res.Result == ResultArchived {
+            summary.Events = append(summary.Events, res)
+        }
+    }
+    return &summary
+}
+
+// Importer - синхронизирует события календаря с задачами по UID, SEQUENCE и LAST-MODIFIED
+type Importer struct {
+    repo     *repository.TaskRepository
+    location *time.Location
+    client   *http.Client // для подписок
+}
+
+// NewImporter - конструктор; loc - пояс для плавающего времени
+func NewImporter(repo *repository.TaskRepository, loc *time.Location) *Importer {
+    return &Importer{repo: repo, location: loc, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Import - создает новые задачи, обновляет измененные и (по опции) архивирует пропавшие.
+// Ошибка одного события попадает в отчет и не прерывает остальные
+func (im *Importer) Import(ctx context.Context, cal *ics.Calendar, opts ImportOptions) (*ImportReport, error) {
+    if opts.Source == "" {
+        return nil, fmt.Errorf("не задан источник импорта")
+    }
+
+    existing, err := im.repo.GetExternalTasks(ctx, opts.Source)
+    if err != nil {
+        return nil, err
+    }
+
+    run := &importRun{
+        im:        im,
+        parser:    NewParser(cal, im.location),
+        opts:      opts,
+        existing:  existing,
+        overrides: make(map[string][]time.Time),
+        seen:      make(map[string]bool),
+        broken:    make(map[string]bool),
+        columns:   make(map[int][]models.BoardColumn),
+    }
+    report := &ImportReport{Source: opts.Source, Events: []EventResult{}}
+
+    // VEVENT и VTODO синхронизируются одинаково, у VTODO STATUS еще и выбирает колонку
+    var comps []importComponent
+    for _, event := range cal.Events() {
+        comps = append(comps, importComponent{base: &event.ComponentBase})
+    }
+    for _, todo := range cal.Todos() {
+        comps = append(comps, importComponent{base: &todo.ComponentBase, todo: true})
+    }
+
+    // Измененные вхождения (RECURRENCE-ID) живут отдельными задачами и вырезаются из серии
+    for _, comp := range comps {
+        if prop := comp.base.GetProperty(ics.ComponentPropertyRecurrenceId); prop != nil {
+            if dt, err := run.parser.Parse(prop); err == nil {
+                run.overrides[comp.base.Id()] = append(run.overrides[comp.base.Id()], dt.Time.UTC())
+            }
+        }
+    }
+
+    for _, comp := range comps {
+        report.add(run.sync(ctx, comp))
+    }
+
+    if opts.ArchiveMissing {
+        var ids []int
+        var missing []EventResult
+        for uid, task := range existing {
+            if run.seen[uid] || run.broken[uid] || task.ExternalSource != opts.Source || task.ArchivedAt != nil || task.DeletedAt != nil {
+                continue
+            }
+            ids = append(ids, task.ID)
+            missing = append(missing, EventResult{UID: uid, Title: task.Title, Result: ResultArchived, TaskID: task.ID})
+        }
+        if _, err := im.repo.ArchiveTasks(ctx, ids); err != nil {
+            return report, fmt.Errorf("не удалось архивировать пропавшие события: %w", err)
+        }
+        for _, res := range missing {
+            report.add(res)
+        }
+    }
+
+    return report, nil
+}
+
+// importComponent - VEVENT или VTODO календаря
+type importComponent struct {
+    base *ics.ComponentBase
+    todo bool
+}
+
+// importRun - состояние одного прохода Import
+type importRun struct {
+    im        *Importer
+    parser    *Parser
+    opts      ImportOptions
+    existing  map[string]*models.Task
+    overrides map[string][]time.Time
+    seen      map[string]bool
+    broken    map[string]bool // UID есть в календаре, но событие не разобрано: задачу не архивируем
+    columns   map[int][]models.BoardColumn // колонки досок, загруженные для STATUS задач
+}
+
+// sync - создает или обновляет задачу одного компонента
+func (run *importRun) sync(ctx context.Context, comp importComponent) EventResult {
+    task, err := componentTask(run.parser, comp.base, comp.todo)
+    res := EventResult{UID: comp.base.Id()}
+    if task != nil {
+        res.UID, res.Title = task.ExternalUID, task.Title
+    }
+    if err != nil {
+        if res.UID != "" {
+            run.broken[res.UID] = true
+        }
+        res.Result, res.Reason = ResultFailed, err.Error()
+        return res
+    }
+    if run.seen[task.ExternalUID] {
+        res.Result, res.Reason = ResultFailed, "UID повторяется в календаре"
+        return res
+    }
+    run.seen[task.ExternalUID] = true
+
+    if task.RRule != "" {
+        task.ExDates = append(task.ExDates, run.overrides[task.ExternalUID]...)
+    }
+    task.ExternalSource = run.opts.Source
+
+    current, ok := run.existing[task.ExternalUID]
+    if !ok {
+        task.LastNotifiedHours = 100 // Чтобы бот начал отсчет заново
+        task.Assignee = run.opts.Assignee
+        task.SetColumn(run.opts.Column)
+        if comp.todo {
+            col, err := run.statusColumn(ctx, run.opts.Column.BoardID, "", TodoStatus(comp.base))
+            if err != nil {
+                res.Result, res.Reason = ResultFailed, err.Error()
+                return res
+            }
+            if col != nil {
+                task.SetColumn(col)
+            }
+        }
+        if err := run.im.repo.CreateTask(ctx, task); err != nil {
+            res.Result, res.Reason = ResultFailed, err.Error()
+            return res
+        }
+        res.Result, res.TaskID = ResultCreated, task.ID
+        return res
+    }
+
+    res.TaskID = current.ID
+    if current.DeletedAt != nil {
+        // Удаленную в канбане задачу календарь не воскрешает и не создает заново
+        res.Result, res.Reason = ResultUnchanged, "задача в корзине"
+        return res
+    }
+    if !eventChanged(current, task) {
+        res.Result = ResultUnchanged
+        return res
+    }
+
+    task.ID = current.ID
+    if err := run.im.repo.UpdateExternalTask(ctx, task); err != nil {
+        res.Result, res.Reason = ResultFailed, err.Error()
+        return res
+    }
+    res.Result = ResultUpdated
+
+    // Задачу закрыли или переоткрыли в календаре - переносим в подходящую колонку
+    if comp.todo {
+        col, err := run.statusColumn(ctx, current.BoardID, current.Status, TodoStatus(comp.base))
+        if err == nil && col != nil && col.Slug != current.Status {
+            // Задачу уже начали в календаре: блокировку только отмечаем, а не отклоняем перенос
+            _, err = run.im.repo.MoveTask(ctx, current.ID, col, 0, 0, true)
+        }
+        if err != nil {
+            res.Reason = "статус не перенесен: " + err.Error()
+        }
+    }
+    return res
+}
+
+// statusColumn - колонка доски для STATUS задачи (см. StatusColumn); nil - колонку не менять
+func (run *importRun) statusColumn(ctx context.Context, boardID int, slug models.TaskStatus, status string) (*models.BoardColumn, error) {
+    columns, ok := run.columns[boardID]
+    if !ok {
+        var err error
+        if columns, err = run.im.repo.GetColumns(ctx, boardID); err != nil {
+            return nil, err
+        }
+        run.columns[boardID] = columns
+    }
+
+    target := StatusColumn(columns, slug, status)
+    for i := range columns {
+        if columns[i].Slug == target {
+            return &columns[i], nil
+        }
+    }
+    return nil, nil
+}
+
+// eventChanged - новее ли событие той версии, из которой задача импортирована
+func eventChanged(current, incoming *models.Task) bool {
+    if current.ExternalSequence == nil || current.ArchivedAt != nil {
+        return true // импортирована до синхронизации или вернулась в календарь
+    }
+
+    seq := 0
+    if incoming.ExternalSequence != nil {
+        seq = *incoming.ExternalSequence
+    }
+    if seq != *current.ExternalSequence {
+        return seq > *current.ExternalSequence
+    }
+
+    if incoming.ExternalModified == nil {
+        return false
+    }
+    return current.ExternalModified == nil || incoming.ExternalModified.After(*current.ExternalModified)
+}
+
+// componentTask - задача из VEVENT или VTODO (todo) без доски и колонки. У события конец -
+// и окончание, и дедлайн; у задачи дедлайн - только DUE
+func componentTask(parser *Parser, comp *ics.ComponentBase, todo bool) (*models.Task, error) {
+    task := &models.Task{ExternalUID: comp.Id()}
+    if prop := comp.GetProperty(ics.ComponentPropertySummary); prop != nil {
+        task.Title = prop.Value
+    }
+    if prop := comp.GetProperty(ics.ComponentPropertyDescription); prop != nil {
+        task.Description = prop.Value
+    }
+    if task.ExternalUID == "" {
+        return task, fmt.Errorf("у события нет UID")
+    }
+
+    // Измененное вхождение серии получает собственный ключ
+    if prop := comp.GetProperty(ics.ComponentPropertyRecurrenceId); prop != nil {
+        dt, err := parser.Parse(prop)
+        if err != nil {
+            return task, err
+        }
+        task.ExternalUID += "@" + dt.Time.UTC().Format(layoutUTC)
+    }
+
+    start, end, err := parser.Span(comp)
+    if err != nil {
+        return task, err
+    }
+    if todo && comp.GetProperty(ics.ComponentPropertyDue) == nil {
+        end = nil
+    }
+    if start != nil {
+        t := start.Time.UTC()
+        task.StartDate = &t
+        task.AllDay = start.AllDay
+    }
+    if end != nil {
+        t := end.Time.UTC()
+        if !todo {
+            task.EndDate = &t
+        }
+        task.Deadline = &t
+        task.AllDay = task.AllDay || (start == nil && end.AllDay)
+    }
+
+    // Повторение: правило берем, только если умеем его разворачивать
+    if prop := comp.GetProperty(ics.ComponentPropertyRrule); prop != nil && (start != nil || end != nil) {
+        if rule, err := recurrence.Parse(prop.Value); err == nil {
+            task.RRule = rule.String()
+        }
+    }
+    if task.RRule != "" {
+        for _, prop := range comp.GetProperties(ics.ComponentPropertyExdate) {
+            exdates, err := parser.ParseList(prop)
+            if err != nil {
+                continue
+            }
+            for _, ex := range exdates {
+                task.ExDates = append(task.ExDates, ex.Time.UTC())
+            }
+        }
+    }
+
+    seq, _ := intProperty(comp, ics.ComponentPropertySequence)
+    task.ExternalSequence = &seq
+
+    if prop := comp.GetProperty(ics.ComponentPropertyLastModified); prop != nil {
+        if dt, err := parser.Parse(prop); err == nil {
+            t := dt.Time.UTC()
+            task.ExternalModified = &t
+        }
+    }
+
+    if n, ok := intProperty(comp, ics.ComponentPropertyPriority); ok && n >= 1 && n <= 9 {
+        task.Priority = taskPriority(n)
+        task.ExternalPriority = n
+    }
+    if n, ok := intProperty(comp, ics.ComponentPropertyPercentComplete); ok && n >= 0 && n <= 100 {
+        task.ExternalPercent = &n
+    }
+
+    return task, nil
+}
+
+// intProperty - целое значение свойства (PRIORITY, PERCENT-COMPLETE, SEQUENCE)
+func intProperty(comp *ics.ComponentBase, name ics.ComponentProperty) (int, bool) {
+    prop := comp.GetProperty(name)
+    if prop == nil {
+        return 0, false
+    }
+    n, err := strconv.Atoi(strings.TrimSpace(prop.Value))
+    return n, err == nil
+}
+
+// TodoStatus - STATUS задачи; без него статус выводится из PERCENT-COMPLETE
+func TodoStatus(comp *ics.ComponentBase) string {
+    if prop := comp.GetProperty(ics.ComponentPropertyStatus); prop != nil {
+        return strings.ToUpper(strings.TrimSpace(prop.Value))
+    }
+    n, ok := intProperty(comp, ics.ComponentPropertyPercentComplete)
+    switch {
+    case !ok:
+        return ""
+    case n >= 100:
+        return string(ics.ObjectStatusCompleted)
+    case n > 0:
+        return string(ics.ObjectStatusInProcess)
+    }
+    return string(ics.ObjectStatusNeedsAction)
+}
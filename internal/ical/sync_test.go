@@ -0,0 +1,82 @@
+package ical
+
+import (
+    "testing"
+    "time"
+    "kanban-calendar/internal/models"
+)
+
+func TestEventChanged(t *testing.T) {
+    seq := func(n int) *int { return &n }
+    at := func(h int) *time.Time {
+        tm := time.Date(2026, 1, 20, h, 0, 0, 0, time.UTC)
+        return &tm
+    }
+
+    tests := []struct {
+        name              string
+        current, incoming models.Task
+        want              bool
+    }{
+        {
+            name:     "импортирована до синхронизации",
+            incoming: models.Task{ExternalSequence: seq(0)},
+            want:     true,
+        },
+        {
+            name:     "вернулась в календарь из архива",
+            current:  models.Task{ExternalSequence: seq(2), ArchivedAt: at(9)},
+            incoming: models.Task{ExternalSequence: seq(2)},
+            want:     true,
+        },
+        {
+            name:     "SEQUENCE выросла",
+            current:  models.Task{ExternalSequence: seq(1), ExternalModified: at(12)},
+            incoming: models.Task{ExternalSequence: seq(2), ExternalModified: at(10)},
+            want:     true,
+        },
+        {
+            name:     "SEQUENCE старее",
+            current:  models.Task{ExternalSequence: seq(3), ExternalModified: at(10)},
+            incoming: models.Task{ExternalSequence: seq(2), ExternalModified: at(12)},
+            want:     false,
+        },
+        {
+            name:     "без SEQUENCE считается 0",
+            current:  models.Task{ExternalSequence: seq(0), ExternalModified: at(10)},
+            incoming: models.Task{ExternalModified: at(11)},
+            want:     true,
+        },
+        {
+            name:     "та же SEQUENCE, LAST-MODIFIED новее",
+            current:  models.Task{ExternalSequence: seq(1), ExternalModified: at(10)},
+            incoming: models.Task{ExternalSequence: seq(1), ExternalModified: at(11)},
+            want:     true,
+        },
+        {
+            name:     "та же SEQUENCE, LAST-MODIFIED тот же",
+            current:  models.Task{ExternalSequence: seq(1), ExternalModified: at(10)},
+            incoming: models.Task{ExternalSequence: seq(1), ExternalModified: at(10)},
+            want:     false,
+        },
+        {
+            name:     "та же SEQUENCE, LAST-MODIFIED появился",
+            current:  models.Task{ExternalSequence: seq(1)},
+            incoming: models.Task{ExternalSequence: seq(1), ExternalModified: at(10)},
+            want:     true,
+        },
+        {
+            name:     "та же SEQUENCE без LAST-MODIFIED",
+            current:  models.Task{ExternalSequence: seq(1), ExternalModified: at(10)},
+            incoming: models.Task{ExternalSequence: seq(1)},
+            want:     false,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := eventChanged(&tt.current, &tt.incoming); got != tt.want {
+                t.Fatalf("eventChanged = %v, ожидалось %v", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,181 @@
+package ical
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    ics "github.com/arran4/golang-ical"
+    "kanban-calendar/internal/recurrence"
+)
+
+// vtimezone - пояс, описанный в самом календаре (для TZID, которых нет в базе IANA)
+type vtimezone struct {
+    id          string
+    observances []observance
+}
+
+// observance - блок STANDARD или DAYLIGHT
+type observance struct {
+    name       string
+    start      time.Time // DTSTART как настенное время (в UTC)
+    offsetFrom int       // секунды
+    offsetTo   int
+    rule       *recurrence.Rule
+    rdates     []time.Time
+}
+
+// parseVTimezone - разбирает VTIMEZONE; блоки с ошибками пропускаются
+func parseVTimezone(tz *ics.VTimezone) *vtimezone {
+    prop := tz.GetProperty(ics.ComponentPropertyTzid)
+    if prop == nil || prop.Value == "" {
+        return nil
+    }
+    zone := &vtimezone{id: prop.Value}
+
+    for _, sub := range tz.Components {
+        var base *ics.ComponentBase
+        switch c := sub.(type) {
+        case *ics.Standard:
+            base = &c.ComponentBase
+        case *ics.Daylight:
+            base = &c.ComponentBase
+        default:
+            continue
+        }
+        if obs, err := parseObservance(base); err == nil {
+            zone.observances = append(zone.observances, obs)
+        }
+    }
+
+    if len(zone.observances) == 0 {
+        return nil
+    }
+    return zone
+}
+
+func parseObservance(base *ics.ComponentBase) (observance, error) {
+    var obs observance
+
+    start := base.GetProperty(ics.ComponentPropertyDtStart)
+    to := base.GetProperty(ics.ComponentProperty(ics.PropertyTzoffsetto))
+    from := base.GetProperty(ics.ComponentProperty(ics.PropertyTzoffsetfrom))
+    if start == nil || to == nil || from == nil {
+        return obs, fmt.Errorf("в блоке пояса нет DTSTART или TZOFFSET")
+    }
+
+    var err error
+    if obs.start, err = time.Parse(layoutFloating, start.Value); err != nil {
+        return obs, err
+    }
+    if obs.offsetTo, err = parseOffset(to.Value); err != nil {
+        return obs, err
+    }
+    if obs.offsetFrom, err = parseOffset(from.Value); err != nil {
+        return obs, err
+    }
+    if name := base.GetProperty(ics.ComponentProperty(ics.PropertyTzname)); name != nil {
+        obs.name = name.Value
+    }
+
+    if prop := base.GetProperty(ics.ComponentPropertyRrule); prop != nil {
+        rule, err := recurrence.Parse(prop.Value)
+        if err != nil {
+            return obs, err
+        }
+        // UNTIL в VTIMEZONE задается в UTC, а развертка идет в настенном времени - сдвигаем
+        if rule.Until != nil {
+            until := rule.Until.Add(time.Duration(obs.offsetFrom) * time.Second)
+            rule.Until = &until
+        }
+        obs.rule = rule
+    }
+    for _, prop := range base.GetProperties(ics.ComponentPropertyRdate) {
+        for _, value := range strings.Split(prop.Value, ",") {
+            if t, err := time.Parse(layoutFloating, value); err == nil {
+                obs.rdates = append(obs.rdates, t)
+            }
+        }
+    }
+    return obs, nil
+}
+
+// at - переводит настенное время в пояс: действует блок с последним началом не позже wall
+func (z *vtimezone) at(wall time.Time) time.Time {
+    wall = time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, time.UTC)
+
+    var current *observance
+    var currentOnset time.Time
+    for i := range z.observances {
+        obs := &z.observances[i]
+        onset, ok := obs.lastOnset(wall)
+        if ok && (current == nil || onset.After(currentOnset)) {
+            current, currentOnset = obs, onset
+        }
+    }
+
+    offset := 0
+    name := z.id
+    if current != nil {
+        offset, name = current.offsetTo, current.name
+    } else {
+        // Время раньше всех блоков - действует смещение, от которого отсчитан самый ранний
+        earliest := &z.observances[0]
+        for i := range z.observances {
+            if z.observances[i].start.Before(earliest.start) {
+                earliest = &z.observances[i]
+            }
+        }
+        offset = earliest.offsetFrom
+    }
+    if name == "" {
+        name = z.id
+    }
+
+    loc := time.FixedZone(name, offset)
+    return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc)
+}
+
+// lastOnset - последнее начало действия блока не позже wall
+func (o *observance) lastOnset(wall time.Time) (time.Time, bool) {
+    if o.start.After(wall) {
+        return time.Time{}, false
+    }
+
+    last := o.start
+    if o.rule != nil {
+        if onsets := o.rule.Between(o.start, o.start, wall, nil); len(onsets) > 0 {
+            last = onsets[len(onsets)-1]
+        }
+    }
+    for _, r := range o.rdates {
+        if !r.After(wall) && r.After(last) {
+            last = r
+        }
+    }
+    return last, true
+}
+
+// parseOffset - смещение вида +0300, -0500 или +053000 в секундах
+func parseOffset(s string) (int, error) {
+    if len(s) != 5 && len(s) != 7 || (s[0] != '+' && s[0] != '-') {
+        return 0, fmt.Errorf("неверное смещение пояса %q", s)
+    }
+    h, err1 := strconv.Atoi(s[1:3])
+    m, err2 := strconv.Atoi(s[3:5])
+    sec := 0
+    var err3 error
+    if len(s) == 7 {
+        sec, err3 = strconv.Atoi(s[5:7])
+    }
+    if err1 != nil || err2 != nil || err3 != nil {
+        return 0, fmt.Errorf("неверное смещение пояса %q", s)
+    }
+
+    offset := h*3600 + m*60 + sec
+    if s[0] == '-' {
+        offset = -offset
+    }
+    return offset, nil
+}
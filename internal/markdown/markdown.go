@@ -0,0 +1,172 @@
+// Package markdown - безопасный HTML для комментариев и поиск @упоминаний.
+// Поддерживается подмножество Markdown: абзацы, заголовки, списки, цитаты, блоки и фрагменты кода,
+// **жирный**, *курсив*, ~~зачеркнутый~~ и ссылки [текст](https://...). Сырой HTML не пропускается:
+// текст экранируется до разметки, ссылки - только http, https и mailto
+package markdown
+
+import (
+    "html"
+    "regexp"
+    "strings"
+)
+
+var (
+    // @имя не внутри слова, адреса почты или пути ссылки
+    mentionRe  = regexp.MustCompile(`(^|[^\p{L}\p{N}_@./:])@([\p{L}\p{N}_](?:[\p{L}\p{N}_.-]{0,62}[\p{L}\p{N}_])?)`)
+    codeSpanRe = regexp.MustCompile("`[^`]*`")
+
+    headingRe = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+    bulletRe  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+    orderedRe = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+    quoteRe   = regexp.MustCompile(`^\s*&gt;\s?(.*)$`)
+
+    linkRe   = regexp.MustCompile(`\[([^\]]+)\]\(((?:https?://|mailto:)[^\s()]+)\)`)
+    boldRe   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+    italicRe = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+    strikeRe = regexp.MustCompile(`~~([^~]+)~~`)
+)
+
+// Mentions - имена из @упоминаний в нижнем регистре, без повторов, в порядке появления.
+// Упоминания внутри кода и в адресах ссылок не считаются
+func Mentions(src string) []string {
+    var names []string
+    seen := map[string]bool{}
+    fenced := false
+    for _, line := range strings.Split(src, "\n") {
+        if isFence(line) {
+            fenced = !fenced
+            continue
+        }
+        if fenced {
+            continue
+        }
+        line = codeSpanRe.ReplaceAllString(line, " ")
+        line = linkRe.ReplaceAllString(line, "$1") // @ в адресе ссылки - не упоминание
+        for _, m := range mentionRe.FindAllStringSubmatch(line, -1) {
+            name := strings.ToLower(m[2])
+            if !seen[name] {
+                seen[name] = true
+                names = append(names, name)
+            }
+        }
+    }
+    return names
+}
+
+// ToHTML - HTML комментария
+func ToHTML(src string) string {
+    var out strings.Builder
+    var para []string
+    list := "" // открытый список: ul или ol
+
+    flushPara := func() {
+        if len(para) > 0 {
+            out.WriteString("<p>" + strings.Join(para, "<br>") + "</p>\n")
+            para = nil
+        }
+    }
+    closeList := func() {
+        if list != "" {
+            out.WriteString("</" + list + ">\n")
+            list = ""
+        }
+    }
+    openList := func(tag string) {
+        if list != tag {
+            closeList()
+            out.WriteString("<" + tag + ">\n")
+            list = tag
+        }
+    }
+
+    lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+    for i := 0; i < len(lines); i++ {
+        line := lines[i]
+        if isFence(line) {
+            flushPara()
+            closeList()
+            var code []string
+            for i++; i < len(lines) && !isFence(lines[i]); i++ {
+                code = append(code, html.EscapeString(lines[i]))
+            }
+            out.WriteString("<pre><code>" + strings.Join(code, "\n") + "</code></pre>\n")
+            continue
+        }
+
+        escaped := html.EscapeString(line)
+        if m := headingRe.FindStringSubmatch(escaped); m != nil {
+            flushPara()
+            closeList()
+            tag := "h" + string(rune('0'+len(m[1])))
+            out.WriteString("<" + tag + ">" + inline(m[2]) + "</" + tag + ">\n")
+            continue
+        }
+        if m := bulletRe.FindStringSubmatch(escaped); m != nil {
+            flushPara()
+            openList("ul")
+            out.WriteString("<li>" + inline(m[1]) + "</li>\n")
+            continue
+        }
+        if m := orderedRe.FindStringSubmatch(escaped); m != nil {
+            flushPara()
+            openList("ol")
+            out.WriteString("<li>" + inline(m[1]) + "</li>\n")
+            continue
+        }
+        if m := quoteRe.FindStringSubmatch(escaped); m != nil {
+            flushPara()
+            closeList()
+            out.WriteString("<blockquote>" + inline(m[1]) + "</blockquote>\n")
+            continue
+        }
+
+        if strings.TrimSpace(line) == "" {
+            flushPara()
+            closeList()
+            continue
+        }
+        closeList()
+        para = append(para, inline(strings.TrimSpace(escaped)))
+    }
+    flushPara()
+    closeList()
+    return strings.TrimSuffix(out.String(), "\n")
+}
+
+// inline - строчная разметка уже экранированного текста; внутри `кода` разметки нет
+func inline(s string) string {
+    var out strings.Builder
+    last := 0
+    for _, loc := range codeSpanRe.FindAllStringIndex(s, -1) {
+        out.WriteString(emphasis(s[last:loc[0]]))
+        out.WriteString("<code>" + s[loc[0]+1:loc[1]-1] + "</code>")
+        last = loc[1]
+    }
+    out.WriteString(emphasis(s[last:]))
+    return out.String()
+}
+
+// emphasis - ссылки, выделение и упоминания. Адрес ссылки в разметку не попадает: выделение
+// и упоминания размечаются только в тексте вокруг ссылок и в их подписях
+func emphasis(s string) string {
+    var out strings.Builder
+    last := 0
+    for _, m := range linkRe.FindAllStringSubmatchIndex(s, -1) {
+        out.WriteString(decorate(s[last:m[0]]))
+        out.WriteString(`<a href="` + s[m[4]:m[5]] + `" rel="nofollow noopener noreferrer">` + decorate(s[m[2]:m[3]]) + "</a>")
+        last = m[1]
+    }
+    out.WriteString(decorate(s[last:]))
+    return out.String()
+}
+
+func decorate(s string) string {
+    s = boldRe.ReplaceAllString(s, "<strong>$1</strong>")
+    s = italicRe.ReplaceAllString(s, "<em>$1</em>")
+    s = strikeRe.ReplaceAllString(s, "<del>$1</del>")
+    return mentionRe.ReplaceAllString(s, `$1<span class="mention">@$2</span>`)
+}
+
+func isFence(line string) bool {
+    return strings.HasPrefix(strings.TrimSpace(line), "```")
+}
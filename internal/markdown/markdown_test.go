@@ -0,0 +1,88 @@
+package markdown
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestMentions(t *testing.T) {
+    tests := []struct {
+        src  string
+        want []string
+    }{
+        {"", nil},
+        {"@anna, посмотри", []string{"anna"}},
+        {"@Anna и @boris, и снова @ANNA", []string{"anna", "boris"}},
+        {"(@ivan.petrov) @maria-k.", []string{"ivan.petrov", "maria-k"}},
+        {"Привет, @Андрей!", []string{"андрей"}},
+        {"почта anna@example.com", nil},
+        {"в `@code` не считается", nil},
+        {"```\n@fenced\n```\nпосле @after", []string{"after"}},
+        {"ссылка https://example.com/@user", nil},
+        {"[профиль](https://example.com/?u=@user) от @anna", []string{"anna"}},
+        {"[@boris](https://example.com/boris)", []string{"boris"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.src, func(t *testing.T) {
+            if got := Mentions(tt.src); !reflect.DeepEqual(got, tt.want) {
+                t.Fatalf("Mentions(%q) = %q, ожидалось %q", tt.src, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestToHTML(t *testing.T) {
+    tests := []struct {
+        name string
+        src  string
+        want string
+    }{
+        {"абзацы и перенос строки", "первая\nвторая\n\nтретья", "<p>первая<br>вторая</p>\n<p>третья</p>"},
+        {"заголовок", "## План", "<h2>План</h2>"},
+        {"список", "- раз\n- **два**", "<ul>\n<li>раз</li>\n<li><strong>два</strong></li>\n</ul>"},
+        {"нумерованный список", "1. раз\n2) два", "<ol>\n<li>раз</li>\n<li>два</li>\n</ol>"},
+        {"цитата", "> важно", "<blockquote>важно</blockquote>"},
+        {"выделение", "*курсив* и ~~старое~~", "<p><em>курсив</em> и <del>старое</del></p>"},
+        {"упоминание", "@anna, глянь", `<p><span class="mention">@anna</span>, глянь</p>`},
+        {"код без разметки", "`**x** @anna`", "<p><code>**x** @anna</code></p>"},
+        {"блок кода", "```\n<b>\n```", "<pre><code>&lt;b&gt;</code></pre>"},
+        {
+            "сырой HTML экранируется",
+            `<script>alert("x")</script><img src=x onerror=alert(1)>`,
+            "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;&lt;img src=x onerror=alert(1)&gt;</p>",
+        },
+        {"javascript: не становится ссылкой", "[клик](javascript:alert(1))", "<p>[клик](javascript:alert(1))</p>"},
+        {
+            "кавычка не выходит из href",
+            `[x](https://example.com/"onmouseover="steal)`,
+            `<p><a href="https://example.com/&#34;onmouseover=&#34;steal" rel="nofollow noopener noreferrer">x</a></p>`,
+        },
+        {
+            "ссылка",
+            "[документ](https://example.com/doc)",
+            `<p><a href="https://example.com/doc" rel="nofollow noopener noreferrer">документ</a></p>`,
+        },
+        {
+            "разметка не попадает в адрес ссылки",
+            "[a](https://example.com/*x*/~~y~~/_@bob) и *b*",
+            `<p><a href="https://example.com/*x*/~~y~~/_@bob" rel="nofollow noopener noreferrer">a</a> и <em>b</em></p>`,
+        },
+        {
+            "упоминание в адресе после =",
+            "[поиск](https://example.com/?q=@bob)",
+            `<p><a href="https://example.com/?q=@bob" rel="nofollow noopener noreferrer">поиск</a></p>`,
+        },
+        {
+            "разметка в подписи ссылки",
+            "[**важно**](https://example.com)",
+            `<p><a href="https://example.com" rel="nofollow noopener noreferrer"><strong>важно</strong></a></p>`,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := ToHTML(tt.src); got != tt.want {
+                t.Fatalf("ToHTML(%q):\n%s\nожидалось:\n%s", tt.src, got, tt.want)
+            }
+        })
+    }
+}
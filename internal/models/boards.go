@@ -0,0 +1,115 @@
+package models
+
+import (
+    "fmt"
+    "time"
+)
+
+// Board - доска с собственным набором колонок
+type Board struct {
+    ID          int           `json:"id"`
+    Name        string        `json:"name"`
+    Description string        `json:"description,omitempty"`
+    CreatedAt   time.Time     `json:"created_at"`
+    UpdatedAt   time.Time     `json:"updated_at"`
+    Columns     []BoardColumn `json:"columns,omitempty"`
+}
+
+// BoardColumn - колонка доски. Slug используется как статус задачи
+type BoardColumn struct {
+    ID        int        `json:"id"`
+    BoardID   int        `json:"board_id"`
+    Slug      TaskStatus `json:"slug"`
+    Name      string     `json:"name"`
+    Position  int        `json:"position"`
+    IsDone    bool       `json:"is_done"` // Задачи в колонке считаются выполненными
+    Color     string     `json:"color,omitempty"`
+    WIPLimit  int        `json:"wip_limit"` // 0 - без лимита
+    WIPMode   string     `json:"wip_mode"`  // reject или warn
+    CreatedAt time.Time  `json:"created_at"`
+    UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// CreateBoardRequest - запрос создания доски. Без колонок создаются todo/in_progress/done
+type CreateBoardRequest struct {
+    Name        string                `json:"name" binding:"required"`
+    Description string                `json:"description"`
+    Columns     []CreateColumnRequest `json:"columns"`
+}
+
+// UpdateBoardRequest - запрос обновления доски
+type UpdateBoardRequest struct {
+    Name        string  `json:"name"`
+    Description *string `json:"description"`
+}
+
+// CreateColumnRequest - запрос создания колонки
+type CreateColumnRequest struct {
+    Name     string `json:"name" binding:"required"`
+    Slug     string `json:"slug"` // По умолчанию строится из name
+    Position *int   `json:"position"`
+    IsDone   bool   `json:"is_done"`
+    Color    string `json:"color"`
+    WIPLimit int    `json:"wip_limit"`
+    WIPMode  string `json:"wip_mode"`
+}
+
+// UpdateColumnRequest - запрос обновления колонки (nil - не менять)
+type UpdateColumnRequest struct {
+    Name     string  `json:"name"`
+    Slug     string  `json:"slug"`
+    Position *int    `json:"position"`
+    IsDone   *bool   `json:"is_done"`
+    Color    *string `json:"color"`
+    WIPLimit *int    `json:"wip_limit"`
+    WIPMode  string  `json:"wip_mode"`
+}
+
+// Режимы WIP-лимита
+const (
+    WIPModeReject = "reject"
+    WIPModeWarn   = "warn"
+)
+
+// WIPViolation - превышение WIP-лимита колонки
+type WIPViolation struct {
+    ColumnID int    `json:"column_id"`
+    Column   string `json:"column"`
+    Limit    int    `json:"limit"`
+    Count    int    `json:"count"`   // Карточек в колонке после операции
+    OverBy   int    `json:"over_by"` // На сколько превышен лимит
+    Mode     string `json:"mode"`
+}
+
+// WIPLimitError - операция отклонена из-за WIP-лимита
+type WIPLimitError struct {
+    WIPViolation
+}
+
+func (e *WIPLimitError) Error() string {
+    return fmt.Sprintf("колонка %q переполнена: лимит %d, будет %d (превышение на %d)",
+        e.Column, e.Limit, e.Count, e.OverBy)
+}
+
+// ValidWIPMode - допустимое значение режима лимита
+func ValidWIPMode(mode string) bool {
+    return mode == WIPModeReject || mode == WIPModeWarn
+}
+
+// DefaultColumns - колонки новой доски по умолчанию
+func DefaultColumns() []CreateColumnRequest {
+    return []CreateColumnRequest{
+        {Name: "К выполнению", Slug: string(StatusTodo)},
+        {Name: "В работе", Slug: string(StatusInProgress)},
+        {Name: "Готово", Slug: string(StatusDone), IsDone: true},
+    }
+}
+
+// SetColumn - переносит задачу в колонку (статус = slug колонки)
+func (t *Task) SetColumn(col *BoardColumn) {
+    t.BoardID = col.BoardID
+    t.ColumnID = col.ID
+    t.Status = col.Slug
+    t.IsDone = col.IsDone
+    t.ColumnColor = col.Color
+}
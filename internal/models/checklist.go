@@ -0,0 +1,57 @@
+package models
+
+import (
+    "fmt"
+    "time"
+)
+
+// ChecklistItem - пункт чек-листа задачи
+type ChecklistItem struct {
+    ID        int       `json:"id"`
+    TaskID    int       `json:"task_id"`
+    Text      string    `json:"text"`
+    Done      bool      `json:"done"`
+    Position  int       `json:"position"` // порядок в чек-листе с 0
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistItemRequest - создание или изменение пункта; незаданные поля при изменении не меняются
+type ChecklistItemRequest struct {
+    Text     *string `json:"text"`
+    Done     *bool   `json:"done"`
+    Position *int    `json:"position"` // без position новый пункт встает в конец
+}
+
+// Progress - выполнение задачи по подзадачам (в колонке "выполнено") и пунктам чек-листа
+type Progress struct {
+    Done           int `json:"done"`
+    Total          int `json:"total"`
+    Percent        int `json:"percent"`
+    SubtasksDone   int `json:"subtasks_done"`
+    SubtasksTotal  int `json:"subtasks_total"`
+    ChecklistDone  int `json:"checklist_done"`
+    ChecklistTotal int `json:"checklist_total"`
+}
+
+// NewProgress - прогресс по счетчикам; nil, если у задачи нет ни подзадач, ни чек-листа
+func NewProgress(subtasksDone, subtasksTotal, checklistDone, checklistTotal int) *Progress {
+    p := &Progress{
+        Done:           subtasksDone + checklistDone,
+        Total:          subtasksTotal + checklistTotal,
+        SubtasksDone:   subtasksDone,
+        SubtasksTotal:  subtasksTotal,
+        ChecklistDone:  checklistDone,
+        ChecklistTotal: checklistTotal,
+    }
+    if p.Total == 0 {
+        return nil
+    }
+    p.Percent = p.Done * 100 / p.Total
+    return p
+}
+
+// String - "3/5 (60%)"
+func (p Progress) String() string {
+    return fmt.Sprintf("%d/%d (%d%%)", p.Done, p.Total, p.Percent)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Comment - комментарий к задаче в Markdown
+type Comment struct {
+    ID        int        `json:"id"`
+    TaskID    int        `json:"task_id"`
+    Author    string     `json:"author"`
+    Body      string     `json:"body"`      // исходный Markdown
+    BodyHTML  string     `json:"body_html"` // безопасный HTML для вывода
+    Mentions  []string   `json:"mentions"`  // упомянутые пользователи
+    CreatedAt time.Time  `json:"created_at"`
+    UpdatedAt time.Time  `json:"updated_at"`
+    EditedAt  *time.Time `json:"edited_at,omitempty"` // nil - не редактировался
+    Revisions int        `json:"revisions"`           // сколько раз правился
+    Notified  []string   `json:"notified,omitempty"`  // кому ушло уведомление об упоминании (в ответе на создание и правку)
+}
+
+// CommentRevision - прежний текст комментария до правки
+type CommentRevision struct {
+    ID       int       `json:"id"`
+    Body     string    `json:"body"`
+    EditedBy string    `json:"edited_by"`
+    EditedAt time.Time `json:"edited_at"` // когда этот текст заменили
+}
+
+// CommentRequest - создание или правка комментария
+type CommentRequest struct {
+    Body string `json:"body" binding:"required"`
+}
+
+// TelegramChat - личный чат пользователя с ботом: туда приходят упоминания
+type TelegramChat struct {
+    User      string    `json:"user"`
+    ChatID    string    `json:"chat_id"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetTelegramChatRequest - привязка чата к пользователю
+type SetTelegramChatRequest struct {
+    ChatID string `json:"chat_id" binding:"required"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Действия в истории задачи
+const (
+    EventCreate  = "create"
+    EventUpdate  = "update"
+    EventMove    = "move"
+    EventArchive = "archive"
+    EventRestore = "restore"
+    EventDelete  = "delete" // в корзину
+    EventPurge   = "purge"  // из корзины навсегда
+)
+
+// FieldChange - значение поля до и после изменения (null - поле было или стало пустым)
+type FieldChange struct {
+    Before interface{} `json:"before"`
+    After  interface{} `json:"after"`
+}
+
+// TaskEvent - запись истории задачи
+type TaskEvent struct {
+    ID        int64                  `json:"id"`
+    TaskID    int                    `json:"task_id"`
+    BoardID   int                    `json:"board_id,omitempty"`
+    TaskTitle string                 `json:"task_title"` // заголовок на момент события
+    Action    string                 `json:"action"`
+    Actor     string                 `json:"actor"`   // X-User запроса, "system" - планировщик и синхронизация
+    Changes   map[string]FieldChange `json:"changes"` // по полям; у create - все заполненные поля
+    CreatedAt time.Time              `json:"created_at"`
+}
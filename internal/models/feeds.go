@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// CalendarFeed - подписываемая лента .ics; доступ по секретному токену в URL
+type CalendarFeed struct {
+    ID             int        `json:"id"`
+    Token          string     `json:"token"`
+    Name           string     `json:"name"`
+    Assignee       string     `json:"assignee,omitempty"` // Пусто - задачи всех исполнителей
+    URL            string     `json:"url,omitempty"`      // Заполняется в ответах API
+    CreatedAt      time.Time  `json:"created_at"`
+    LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+}
+
+// CreateFeedRequest - создание ленты
+type CreateFeedRequest struct {
+    Name     string `json:"name" binding:"required"`
+    Assignee string `json:"assignee"`
+}
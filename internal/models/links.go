@@ -0,0 +1,73 @@
+package models
+
+import (
+    "fmt"
+    "time"
+)
+
+// Типы связей между задачами (хранятся в направлении source -> target)
+const (
+    LinkBlocks     = "blocks"     // source нужно закончить до того, как начнется target
+    LinkRelatesTo  = "relates_to" // без направления
+    LinkDuplicates = "duplicates" // source - дубликат target
+)
+
+// Обратные направления: так связь можно создать со стороны target
+const (
+    LinkBlockedBy    = "blocked_by"
+    LinkDuplicatedBy = "duplicated_by"
+)
+
+// TaskLink - связь между задачами
+type TaskLink struct {
+    ID        int       `json:"id"`
+    SourceID  int       `json:"source_id"`
+    TargetID  int       `json:"target_id"`
+    Type      string    `json:"type"`
+    CreatedBy string    `json:"created_by"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateLinkRequest - связь со стороны задачи из пути: type - blocks, blocked_by, relates_to, duplicates или duplicated_by
+type CreateLinkRequest struct {
+    Type   string `json:"type" binding:"required"`
+    TaskID int    `json:"task_id" binding:"required"`
+}
+
+// TaskRef - связанная задача в ответе
+type TaskRef struct {
+    LinkID   int        `json:"link_id"`
+    ID       int        `json:"id"`
+    Title    string     `json:"title"`
+    Status   TaskStatus `json:"status"`
+    IsDone   bool       `json:"is_done"`
+    Deadline *time.Time `json:"deadline,omitempty"`
+    EndDate  *time.Time `json:"end_date,omitempty"`
+}
+
+// TaskLinks - все связи задачи, разложенные по смыслу
+type TaskLinks struct {
+    Blockers     []TaskRef `json:"blockers"`      // блокируют эту задачу
+    Dependents   []TaskRef `json:"dependents"`    // ждут эту задачу
+    Related      []TaskRef `json:"related"`
+    Duplicates   []TaskRef `json:"duplicates"`    // эта задача - их дубликат
+    DuplicatedBy []TaskRef `json:"duplicated_by"` // дубликаты этой задачи
+}
+
+// Dependency - ребро "from блокирует to" для диаграммы Ганта
+type Dependency struct {
+    LinkID int    `json:"link_id"`
+    From   int    `json:"from"`
+    To     int    `json:"to"`
+    Type   string `json:"type"`
+}
+
+// BlockedError - задачу нельзя начать: ее блокируют незавершенные задачи
+type BlockedError struct {
+    TaskID   int
+    Blockers []TaskRef
+}
+
+func (e *BlockedError) Error() string {
+    return fmt.Sprintf("задачу %d блокируют незавершенные задачи (%d)", e.TaskID, len(e.Blockers))
+}
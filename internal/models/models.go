@@ -4,10 +4,10 @@ import (
     "time"
 )
 
-// TaskStatus - тип для статуса задачи
+// TaskStatus - тип для статуса задачи (slug колонки доски)
 type TaskStatus string
 
-// Енум статусов
+// Статусы колонок доски по умолчанию
 const (
     StatusTodo       TaskStatus = "todo"
     StatusInProgress TaskStatus = "in_progress"
@@ -30,6 +30,35 @@ type Task struct {
     Assignee    string      `json:"assignee,omitempty"`   // Исполнитель
     Tags        []string    `json:"tags,omitempty"`       // Теги (массив строк)
     LastNotifiedHours int    `json:"last_notified_hours"`
+    BoardID     int         `json:"board_id"`             // Доска
+    ColumnID    int         `json:"column_id"`            // Колонка доски (Status = ее slug)
+    Position    string      `json:"position"`             // Ранг карточки внутри колонки
+    IsDone      bool        `json:"is_done"`              // Колонка помечена как "выполнено"
+    ColumnColor string      `json:"-"`                    // Цвет колонки для календаря
+    WIPWarning  *WIPViolation `json:"wip_warning,omitempty"` // Лимит колонки превышен в режиме warn
+    RRule       string      `json:"rrule,omitempty"`      // Правило повторения RFC 5545 (FREQ=WEEKLY;BYDAY=MO)
+    ExDates     []time.Time `json:"exdates,omitempty"`    // Пропущенные вхождения
+    SeriesID    int         `json:"series_id,omitempty"`  // Первая задача серии повторений
+    AllDay      bool        `json:"all_day"`              // На весь день: время у дат не значимо
+    ExternalSource   string     `json:"external_source,omitempty"` // Откуда импортирована (файл, подписка)
+    ExternalSequence *int       `json:"-"`                         // SEQUENCE события при последней синхронизации
+    ExternalModified *time.Time `json:"-"`                         // LAST-MODIFIED события при последней синхронизации
+    ExternalPriority int        `json:"-"`                         // PRIORITY 1-9 из календаря (0 - нет), уточняет Priority
+    ExternalPercent  *int       `json:"-"`                         // PERCENT-COMPLETE из календаря (nil - не задан)
+    ArchivedAt       *time.Time `json:"archived_at,omitempty"`     // Скрыта из доски и календаря
+    DeletedAt        *time.Time `json:"deleted_at,omitempty"`      // В корзине: скрыта везде, удаляется после срока хранения
+    CalDAVName       string     `json:"-"`                         // Имя ресурса, под которым задачу создал CalDAV-клиент
+    CalDAVComponent  string     `json:"-"`                         // event или todo - как ее сохранил клиент
+    Version          int        `json:"version"`                   // Версия для If-Match; при сохранении - ожидаемая (0 - без проверки)
+    ParentID         int             `json:"parent_id,omitempty"`  // Родительская задача (0 - нет)
+    Progress         *Progress       `json:"progress,omitempty"`   // По подзадачам и чек-листу; nil - ни того, ни другого
+    Checklist        []ChecklistItem `json:"checklist,omitempty"`  // Заполняется только у одной задачи (GetTaskByID)
+    Blocked          bool            `json:"blocked,omitempty"`    // Есть незавершенные блокирующие задачи
+    Blockers         []TaskRef       `json:"blockers,omitempty"`   // Блокирующие задачи (GetTaskByID)
+    Dependents       []TaskRef       `json:"dependents,omitempty"` // Задачи, которые ждут эту (GetTaskByID)
+    BlockedWarning   []TaskRef       `json:"blocked_warning,omitempty"` // Задачу начали с force, несмотря на эти блокирующие
+    Force            bool            `json:"-"`                    // При сохранении: начать задачу, даже если она заблокирована
+    Rescheduled      []Reschedule    `json:"rescheduled,omitempty"` // Зависимые задачи, сдвинутые каскадом вместе с этой
 }
 
 // CalendarEvent - структура для отображения в календаре
@@ -41,9 +70,14 @@ type CalendarEvent struct {
     End         time.Time   `json:"end"`
     Status      TaskStatus  `json:"status"`
     Color       string      `json:"color,omitempty"` // Цвет события в календаре
+    Tags        []string    `json:"tags,omitempty"`
+    Recurring   bool        `json:"recurring,omitempty"` // Вхождение повторяющейся задачи
+    AllDay      bool        `json:"allDay"`              // Событие на весь день (имя поля как в FullCalendar)
+    ParentID    int         `json:"parent_id,omitempty"`
+    Progress    *Progress   `json:"progress,omitempty"`
 }
 
-// CreateTaskRequest - структура для запроса создания задачи
+// CreateTaskRequest - структура для запроса создания задачи; она же - полное тело PUT и документ для PATCH
 type CreateTaskRequest struct {
     Title       string     `json:"title" binding:"required"`
     Description string     `json:"description"`
@@ -54,30 +88,44 @@ type CreateTaskRequest struct {
     EndDate     string     `json:"end_date"`
     Assignee    string     `json:"assignee"`
     Tags        []string   `json:"tags"`
+    BoardID     int        `json:"board_id"`  // 0 - доска по умолчанию
+    ColumnID    int        `json:"column_id"` // Приоритетнее, чем status
+    RRule       string     `json:"rrule"`     // Требует start_date или deadline
+    ExDates     []string   `json:"exdates"`
+    AllDay      bool       `json:"all_day"`
+    ParentID    int        `json:"parent_id"` // Родительская задача, 0 - нет
 }
 
-// UpdateTaskRequest - структура для запроса обновления задачи
-type UpdateTaskRequest struct {
-    Title       string     `json:"title"`
-    Description string     `json:"description"`
-    Status      TaskStatus `json:"status"`
-    Priority    string     `json:"priority"`
-    Deadline    string     `json:"deadline"`
-    StartDate   string     `json:"start_date"`
-    EndDate     string     `json:"end_date"`
-    Assignee    string     `json:"assignee"`
-    Tags        []string   `json:"tags"`
+// MoveTaskRequest - перенос карточки: в колонку (column_id или status) и между соседями.
+// after_id - карточка, под которой окажется задача, before_id - над которой.
+// Без соседей задача встает в конец колонки. force - начать заблокированную задачу
+type MoveTaskRequest struct {
+    BoardID  int        `json:"board_id"`
+    ColumnID int        `json:"column_id"`
+    Status   TaskStatus `json:"status"`
+    BeforeID int        `json:"before_id"`
+    AfterID  int        `json:"after_id"`
+    Force    bool       `json:"force"`
+}
+
+// StatusColor - цвет события в календаре: цвет колонки, иначе по статусу
+func StatusColor(status TaskStatus, isDone bool, columnColor string) string {
+    if columnColor != "" {
+        return columnColor
+    }
+    if isDone {
+        return "#28a745" // Зеленый для выполненных
+    }
+    if status == StatusInProgress {
+        return "#ffc107" // Желтый для в работе
+    }
+    return "#3174ad" // Синий по умолчанию
 }
 
 // Метод для преобразования Task в CalendarEvent
 func (t *Task) ToCalendarEvent() CalendarEvent {
-    // Выбираем цвет в зависимости от статуса
-    color := "#3174ad" // Синий по умолчанию
-    if t.Status == StatusDone {
-        color = "#28a745" // Зеленый для выполненных
-    } else if t.Status == StatusInProgress {
-        color = "#ffc107" // Желтый для в работе
-    }
+    // Выбираем цвет в зависимости от колонки
+    color := StatusColor(t.Status, t.IsDone, t.ColumnColor)
     
     // Даты начала и окончания
     start := time.Now()
@@ -100,5 +148,8 @@ func (t *Task) ToCalendarEvent() CalendarEvent {
         End:         end,
         Status:      t.Status,
         Color:       color,
+        Tags:        t.Tags,
+        ParentID:    t.ParentID,
+        Progress:    t.Progress,
     }
 }
\ No newline at end of file
@@ -5,7 +5,7 @@ import "time"
 type Notification struct {
     ID        int       `json:"id"`
     TaskID    int       `json:"task_id"`
-    Type      string    `json:"type"` // "deadline", "reminder", "status_change"
+    Type      string    `json:"type"` // "deadline", "reminder", "status_change", "mention"
     Message   string    `json:"message"`
     SentAt    time.Time `json:"sent_at"`
     IsSent    bool      `json:"is_sent"`
@@ -17,4 +17,5 @@ const (
     NotificationTypeReminder    = "reminder"
     NotificationTypeStatusChange = "status_change"
     NotificationTypeDailyReport = "daily_report"
+    NotificationTypeMention     = "mention"
 )
\ No newline at end of file
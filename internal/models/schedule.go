@@ -0,0 +1,10 @@
+package models
+
+// Reschedule - сдвиг дат зависимой задачи вслед за блокирующей
+type Reschedule struct {
+    TaskID    int                    `json:"task_id"`
+    Title     string                 `json:"title"`
+    CausedBy  int                    `json:"caused_by"`  // блокирующая задача, из-за которой пришлось сдвинуть
+    ShiftDays float64                `json:"shift_days"` // на сколько календарных дней сдвинута задача
+    Changes   map[string]FieldChange `json:"changes"`    // start_date, end_date, deadline: до и после
+}
@@ -0,0 +1,10 @@
+package models
+
+// SearchResult - задача, найденная полнотекстовым поиском.
+// Title и Snippet - HTML: текст экранирован, совпадения обернуты в <mark>
+type SearchResult struct {
+    Task    Task    `json:"task"`
+    Rank    float64 `json:"rank"`
+    Title   string  `json:"title"`   // заголовок с подсветкой
+    Snippet string  `json:"snippet"` // фрагменты описания с подсветкой ("" - описания нет)
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+    "encoding/json"
+    "time"
+)
+
+// Итог последней синхронизации подписки
+const (
+    SyncStatusOK          = "ok"
+    SyncStatusNotModified = "not_modified" // сервер ответил 304
+    SyncStatusError       = "error"
+)
+
+// CalendarSubscription - внешний .ics, который планировщик периодически синхронизирует с доской
+type CalendarSubscription struct {
+    ID              int             `json:"id"`
+    Name            string          `json:"name"`
+    URL             string          `json:"url"`
+    RefreshMinutes  int             `json:"refresh_minutes"`
+    BoardID         int             `json:"board_id"`
+    DefaultAssignee string          `json:"default_assignee,omitempty"` // Исполнитель новых задач
+    ArchiveMissing  bool            `json:"archive_missing"`            // Архивировать пропавшие события
+    Enabled         bool            `json:"enabled"`
+    ETag            string          `json:"-"`
+    LastModified    string          `json:"-"`
+    LastSyncAt      *time.Time      `json:"last_sync_at,omitempty"`
+    LastSuccessAt   *time.Time      `json:"last_success_at,omitempty"`
+    LastStatus      string          `json:"last_status,omitempty"` // ok, not_modified, error; пусто - еще не было
+    LastError       string          `json:"last_error,omitempty"`
+    LastReport      json.RawMessage `json:"last_report,omitempty"` // Сводка последнего импорта
+    NextSyncAt      time.Time       `json:"next_sync_at"`
+    CreatedAt       time.Time       `json:"created_at"`
+    UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// SubscriptionSync - результат одного прохода синхронизации
+type SubscriptionSync struct {
+    Status       string
+    Error        string
+    ETag         string
+    LastModified string
+    Report       interface{} // сохраняется в last_report как JSON
+}
+
+// CreateSubscriptionRequest - запрос создания подписки
+type CreateSubscriptionRequest struct {
+    Name            string `json:"name" binding:"required"`
+    URL             string `json:"url" binding:"required"`
+    RefreshMinutes  int    `json:"refresh_minutes"` // По умолчанию 60, не меньше 5
+    BoardID         int    `json:"board_id"`        // 0 - доска по умолчанию
+    DefaultAssignee string `json:"default_assignee"`
+    ArchiveMissing  *bool  `json:"archive_missing"` // По умолчанию true
+}
+
+// UpdateSubscriptionRequest - запрос обновления подписки (nil - не менять)
+type UpdateSubscriptionRequest struct {
+    Name            string  `json:"name"`
+    URL             string  `json:"url"`
+    RefreshMinutes  *int    `json:"refresh_minutes"`
+    BoardID         *int    `json:"board_id"`
+    DefaultAssignee *string `json:"default_assignee"`
+    ArchiveMissing  *bool   `json:"archive_missing"`
+    Enabled         *bool   `json:"enabled"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Tag - тег задачи с цветом и числом использований
+type Tag struct {
+    ID        int       `json:"id"`
+    Name      string    `json:"name"`
+    Color     string    `json:"color,omitempty"`
+    Count     int       `json:"count"` // Сколько задач помечено тегом
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// UpdateTagRequest - переименование тега или смена цвета
+type UpdateTagRequest struct {
+    Name  string  `json:"name"`
+    Color *string `json:"color"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Владельцы переопределенного пояса
+const (
+    ZoneSubjectUser = "user" // исполнитель (assignee)
+    ZoneSubjectChat = "chat" // чат Telegram
+)
+
+// TimeZone - пояс пользователя или чата поверх пояса сервера
+type TimeZone struct {
+    SubjectType string    `json:"subject_type"` // user или chat
+    Subject     string    `json:"subject"`      // имя исполнителя или ID чата
+    Zone        string    `json:"zone"`         // имя IANA: Europe/Moscow
+    UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SetTimeZoneRequest - назначение пояса
+type SetTimeZoneRequest struct {
+    Zone string `json:"zone" binding:"required"`
+}
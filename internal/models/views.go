@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SavedView - сохраненное представление: именованный запрос пользователя.
+// "me" в запросе - тот, кто смотрит представление (X-User), а без него - владелец
+type SavedView struct {
+    ID        int       `json:"id"`
+    Owner     string    `json:"owner"`
+    Name      string    `json:"name"`
+    Query     string    `json:"query"` // assignee:me status:!done deadline:<now
+    Sort      string    `json:"sort"`  // как ?sort= у GET /api/tasks, пусто - порядок доски
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavedViewRequest - создание и замена представления
+type SavedViewRequest struct {
+    Name  string `json:"name" binding:"required"`
+    Query string `json:"query"`
+    Sort  string `json:"sort"`
+}
@@ -0,0 +1,76 @@
+// Package rank - лексикографические ранги для ручной сортировки карточек.
+// Ранг - строка из символов base62, сравниваемая побайтно (COLLATE "C" в Postgres).
+// Между двумя любыми рангами всегда можно вставить новый, не трогая соседей.
+package rank
+
+import (
+    "fmt"
+    "strings"
+)
+
+const alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(alphabet)
+
+// Initial - ранг i-й карточки при первичной расстановке или перебалансировке колонки
+func Initial(i int) string {
+    return fmt.Sprintf("%010dV", i)
+}
+
+// Between - ранг строго между prev и next.
+// Пустой prev - начало колонки, пустой next - конец колонки
+func Between(prev, next string) (string, error) {
+    if next != "" && prev >= next {
+        return "", fmt.Errorf("ранги не упорядочены: %q >= %q", prev, next)
+    }
+    if err := validate(prev); err != nil {
+        return "", err
+    }
+    if err := validate(next); err != nil {
+        return "", err
+    }
+
+    var b strings.Builder
+    upper := next == "" // верхняя граница - "бесконечность"
+    for i := 0; ; i++ {
+        pd := digit(prev, i)
+        nd := base
+        if !upper {
+            nd = digit(next, i)
+        }
+
+        if pd == nd {
+            if i >= len(prev) && i >= len(next) {
+                // next отличается от prev только хвостовыми нулями - места нет
+                return "", fmt.Errorf("между %q и %q нет свободного ранга", prev, next)
+            }
+            b.WriteByte(alphabet[pd])
+            continue
+        }
+
+        if nd-pd > 1 {
+            b.WriteByte(alphabet[(pd+nd)/2])
+            return b.String(), nil
+        }
+
+        // Соседние цифры: берем цифру prev, дальше сверху ничего не ограничивает
+        b.WriteByte(alphabet[pd])
+        upper = true
+    }
+}
+
+func digit(s string, i int) int {
+    if i >= len(s) {
+        return 0
+    }
+    return strings.IndexByte(alphabet, s[i])
+}
+
+func validate(s string) error {
+    for i := 0; i < len(s); i++ {
+        if strings.IndexByte(alphabet, s[i]) < 0 {
+            return fmt.Errorf("недопустимый символ %q в ранге %q", s[i], s)
+        }
+    }
+    return nil
+}
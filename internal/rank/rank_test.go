@@ -0,0 +1,86 @@
+package rank
+
+import "testing"
+
+func TestBetween(t *testing.T) {
+    tests := []struct {
+        name       string
+        prev, next string
+        wantErr    bool
+    }{
+        {name: "пустая колонка", prev: "", next: ""},
+        {name: "в начало", prev: "", next: "V"},
+        {name: "в конец", prev: "V", next: ""},
+        {name: "между далекими", prev: "A", next: "z"},
+        {name: "между соседними цифрами", prev: "A", next: "B"},
+        {name: "после последней цифры", prev: "z", next: ""},
+        {name: "перед нулевым хвостом", prev: "", next: "01"},
+        {name: "ранги перебалансировки", prev: Initial(1), next: Initial(2)},
+        {name: "prev длиннее next", prev: "Azzz", next: "B"},
+        {name: "равные ранги", prev: "V", next: "V", wantErr: true},
+        {name: "неупорядоченные ранги", prev: "b", next: "a", wantErr: true},
+        {name: "нет места", prev: "V", next: "V0", wantErr: true},
+        {name: "недопустимый символ", prev: "a-b", next: "", wantErr: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got, err := Between(tt.prev, tt.next)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("Between(%q, %q) = %q, ожидалась ошибка", tt.prev, tt.next, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Between(%q, %q): %v", tt.prev, tt.next, err)
+            }
+            if got <= tt.prev || (tt.next != "" && got >= tt.next) {
+                t.Fatalf("Between(%q, %q) = %q - не между соседями", tt.prev, tt.next, got)
+            }
+            if err := validate(got); err != nil {
+                t.Fatalf("Between(%q, %q) = %q: %v", tt.prev, tt.next, got, err)
+            }
+        })
+    }
+}
+
+func TestBetweenAppend(t *testing.T) {
+    // Вставки в конец колонки: каждый ранг строго больше предыдущего
+    last := ""
+    for i := 0; i < 1000; i++ {
+        next, err := Between(last, "")
+        if err != nil {
+            t.Fatalf("вставка %d: %v", i, err)
+        }
+        if next <= last {
+            t.Fatalf("вставка %d: %q <= %q", i, next, last)
+        }
+        last = next
+    }
+}
+
+func TestBetweenRepeatedInsert(t *testing.T) {
+    // Вставки все время перед одним и тем же соседом
+    prev, next := "", "V"
+    for i := 0; i < 200; i++ {
+        got, err := Between(prev, next)
+        if err != nil {
+            t.Fatalf("вставка %d: %v", i, err)
+        }
+        if got <= prev || got >= next {
+            t.Fatalf("вставка %d: %q не между %q и %q", i, got, prev, next)
+        }
+        next = got
+    }
+}
+
+func TestInitial(t *testing.T) {
+    for i := 0; i < 1000; i++ {
+        if Initial(i) >= Initial(i+1) {
+            t.Fatalf("Initial(%d) = %q >= Initial(%d) = %q", i, Initial(i), i+1, Initial(i+1))
+        }
+        if err := validate(Initial(i)); err != nil {
+            t.Fatal(err)
+        }
+    }
+}
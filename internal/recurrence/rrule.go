@@ -0,0 +1,502 @@
+// Package recurrence - разбор и развертка правил повторения RFC 5545 (RRULE).
+//
+// Поддерживаются FREQ=DAILY|WEEKLY|MONTHLY|YEARLY, INTERVAL, COUNT, UNTIL,
+// BYDAY (в том числе с номером: 1MO, -1FR), BYMONTHDAY, BYMONTH, BYSETPOS и WKST.
+// Развертка идет в часовом поясе dtstart, поэтому время суток сохраняется при переходе на летнее время.
+package recurrence
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Frequency - частота повторения
+type Frequency string
+
+const (
+    Daily   Frequency = "DAILY"
+    Weekly  Frequency = "WEEKLY"
+    Monthly Frequency = "MONTHLY"
+    Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods - предел итераций, чтобы бесконечное правило не зациклило развертку
+const maxPeriods = 100000
+
+// WeekdayNum - день недели с необязательным номером (0 - любой): внутри месяца, а у YEARLY без BYMONTH - внутри года
+type WeekdayNum struct {
+    Day time.Weekday
+    N   int
+}
+
+// Rule - разобранное правило RRULE
+type Rule struct {
+    Freq       Frequency
+    Interval   int
+    Count      int        // 0 - без ограничения
+    Until      *time.Time // nil - без ограничения
+    ByDay      []WeekdayNum
+    ByMonthDay []int
+    ByMonth    []time.Month
+    BySetPos   []int
+    WeekStart  time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+    "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+    "FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// Parse - разбирает строку вида "FREQ=WEEKLY;BYDAY=MO,WE" (префикс "RRULE:" допускается)
+func Parse(s string) (*Rule, error) {
+    s = strings.TrimSpace(s)
+    s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+    if s == "" {
+        return nil, fmt.Errorf("пустое правило повторения")
+    }
+
+    rule := &Rule{Interval: 1, WeekStart: time.Monday}
+    for _, part := range strings.Split(s, ";") {
+        if part == "" {
+            continue
+        }
+        kv := strings.SplitN(part, "=", 2)
+        if len(kv) != 2 {
+            return nil, fmt.Errorf("неверная часть правила %q", part)
+        }
+        key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+
+        var err error
+        switch key {
+        case "FREQ":
+            rule.Freq = Frequency(value)
+            switch rule.Freq {
+            case Daily, Weekly, Monthly, Yearly:
+            default:
+                return nil, fmt.Errorf("частота %s не поддерживается", value)
+            }
+        case "INTERVAL":
+            rule.Interval, err = strconv.Atoi(value)
+            if err == nil && rule.Interval < 1 {
+                err = fmt.Errorf("должен быть больше 0")
+            }
+        case "COUNT":
+            rule.Count, err = strconv.Atoi(value)
+            if err == nil && rule.Count < 1 {
+                err = fmt.Errorf("должен быть больше 0")
+            }
+        case "UNTIL":
+            var until time.Time
+            until, err = parseUntil(value)
+            rule.Until = &until
+        case "BYDAY":
+            rule.ByDay, err = parseByDay(value)
+        case "BYMONTHDAY":
+            rule.ByMonthDay, err = parseInts(value, -31, 31)
+        case "BYMONTH":
+            var months []int
+            months, err = parseInts(value, 1, 12)
+            for _, m := range months {
+                rule.ByMonth = append(rule.ByMonth, time.Month(m))
+            }
+        case "BYSETPOS":
+            rule.BySetPos, err = parseInts(value, -366, 366)
+        case "WKST":
+            day, ok := weekdays[value]
+            if !ok {
+                err = fmt.Errorf("неизвестный день недели")
+            }
+            rule.WeekStart = day
+        default:
+            return nil, fmt.Errorf("параметр %s не поддерживается", key)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("%s=%s: %w", key, value, err)
+        }
+    }
+
+    if rule.Freq == "" {
+        return nil, fmt.Errorf("в правиле нет FREQ")
+    }
+    if rule.Count > 0 && rule.Until != nil {
+        return nil, fmt.Errorf("COUNT и UNTIL нельзя указывать вместе")
+    }
+    return rule, nil
+}
+
+// String - правило в формате RRULE (без префикса)
+func (r *Rule) String() string {
+    parts := []string{"FREQ=" + string(r.Freq)}
+    if r.Interval > 1 {
+        parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+    }
+    if r.Count > 0 {
+        parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+    }
+    if r.Until != nil {
+        parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+    }
+    if len(r.ByDay) > 0 {
+        var days []string
+        for _, d := range r.ByDay {
+            code := strings.ToUpper(d.Day.String()[:2])
+            if d.N != 0 {
+                code = strconv.Itoa(d.N) + code
+            }
+            days = append(days, code)
+        }
+        parts = append(parts, "BYDAY="+strings.Join(days, ","))
+    }
+    if len(r.ByMonthDay) > 0 {
+        parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+    }
+    if len(r.ByMonth) > 0 {
+        months := make([]int, len(r.ByMonth))
+        for i, m := range r.ByMonth {
+            months[i] = int(m)
+        }
+        parts = append(parts, "BYMONTH="+joinInts(months))
+    }
+    if len(r.BySetPos) > 0 {
+        parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+    }
+    if r.WeekStart != time.Monday {
+        parts = append(parts, "WKST="+strings.ToUpper(r.WeekStart.String()[:2]))
+    }
+    return strings.Join(parts, ";")
+}
+
+// Between - вхождения в интервале [from, to], начиная с dtstart (dtstart - всегда первое вхождение).
+// Даты из exdates исключаются, но учитываются в COUNT
+func (r *Rule) Between(dtstart, from, to time.Time, exdates []time.Time) []time.Time {
+    var result []time.Time
+    r.iterate(dtstart, to, func(t time.Time) bool {
+        if t.After(to) {
+            return false
+        }
+        if !t.Before(from) && !isExcluded(t, exdates) {
+            result = append(result, t)
+        }
+        return true
+    })
+    return result
+}
+
+// After - первое вхождение строго после t (с учетом exdates); false - вхождений больше нет
+func (r *Rule) After(dtstart, t time.Time, exdates []time.Time) (time.Time, bool) {
+    var next time.Time
+    found := false
+    // Дальше ста лет вперед не ищем: правило, не дающее вхождений, не должно крутиться вечно
+    r.iterate(dtstart, t.AddDate(100, 0, 0), func(o time.Time) bool {
+        if o.After(t) && !isExcluded(o, exdates) {
+            next = o
+            found = true
+            return false
+        }
+        return true
+    })
+    return next, found
+}
+
+// iterate - перебирает вхождения по порядку, пока fn возвращает true и не пройдена граница limit
+func (r *Rule) iterate(dtstart, limit time.Time, fn func(time.Time) bool) {
+    emitted := 0
+    emit := func(t time.Time) bool {
+        if r.Until != nil && t.After(*r.Until) {
+            return false
+        }
+        if r.Count > 0 && emitted >= r.Count {
+            return false
+        }
+        emitted++
+        return fn(t)
+    }
+
+    if !emit(dtstart) {
+        return
+    }
+
+    for period := 0; period < maxPeriods; period++ {
+        candidates := r.periodCandidates(dtstart, period)
+        for _, t := range candidates {
+            if !t.After(dtstart) {
+                continue
+            }
+            if !emit(t) {
+                return
+            }
+        }
+        // Следующий период целиком позже UNTIL или границы поиска - дальше искать нечего
+        next := r.periodStart(dtstart, period+1)
+        if next.After(limit) || (r.Until != nil && next.After(*r.Until)) {
+            return
+        }
+    }
+}
+
+// periodStart - начало n-го периода (для проверки границ)
+func (r *Rule) periodStart(dtstart time.Time, n int) time.Time {
+    step := n * r.Interval
+    y, m, d := dtstart.Date()
+    loc := dtstart.Location()
+    switch r.Freq {
+    case Daily:
+        return time.Date(y, m, d+step, 0, 0, 0, 0, loc)
+    case Weekly:
+        return weekStart(dtstart, r.WeekStart).AddDate(0, 0, 7*step)
+    case Monthly:
+        return time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+    default:
+        return time.Date(y+step, 1, 1, 0, 0, 0, 0, loc)
+    }
+}
+
+// periodCandidates - отсортированные вхождения n-го периода (дня, недели, месяца или года)
+func (r *Rule) periodCandidates(dtstart time.Time, n int) []time.Time {
+    step := n * r.Interval
+    y, m, d := dtstart.Date()
+    loc := dtstart.Location()
+    hh, mm, ss := dtstart.Clock()
+    at := func(year int, month time.Month, day int) time.Time {
+        return time.Date(year, month, day, hh, mm, ss, 0, loc)
+    }
+
+    var days []time.Time
+    switch r.Freq {
+    case Daily:
+        t := at(y, m, d+step)
+        if r.matchMonth(t) && r.matchMonthDay(t) && r.matchWeekday(t) {
+            days = append(days, t)
+        }
+    case Weekly:
+        start := weekStart(dtstart, r.WeekStart).AddDate(0, 0, 7*step)
+        for i := 0; i < 7; i++ {
+            sy, sm, sd := start.Date()
+            t := at(sy, sm, sd+i)
+            if len(r.ByDay) == 0 && t.Weekday() != dtstart.Weekday() {
+                continue
+            }
+            if r.matchWeekday(t) && r.matchMonth(t) {
+                days = append(days, t)
+            }
+        }
+    case Monthly:
+        first := time.Date(y, m+time.Month(step), 1, 0, 0, 0, 0, loc)
+        if r.matchMonth(first) {
+            days = r.monthDays(first.Year(), first.Month(), d, at)
+        }
+    case Yearly:
+        // RFC 5545: без BYMONTH правило раскрывается на весь год, а не на месяц dtstart
+        year := y + step
+        var months []time.Month
+        switch {
+        case len(r.ByMonth) > 0:
+            months = r.ByMonth
+        case len(r.ByMonthDay) > 0:
+            for month := time.January; month <= time.December; month++ {
+                months = append(months, month)
+            }
+        case len(r.ByDay) > 0:
+            days = r.yearDays(year, at)
+        default:
+            months = []time.Month{m}
+        }
+        for _, month := range months {
+            days = append(days, r.monthDays(year, month, d, at)...)
+        }
+    }
+
+    sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+    return applySetPos(days, r.BySetPos)
+}
+
+// monthDays - подходящие дни месяца для MONTHLY/YEARLY
+func (r *Rule) monthDays(year int, month time.Month, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+    last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+    var days []time.Time
+
+    switch {
+    case len(r.ByMonthDay) > 0:
+        for _, md := range r.ByMonthDay {
+            day := md
+            if md < 0 {
+                day = last + md + 1
+            }
+            if day < 1 || day > last {
+                continue
+            }
+            t := at(year, month, day)
+            if r.matchWeekday(t) {
+                days = append(days, t)
+            }
+        }
+    case len(r.ByDay) > 0:
+        for day := 1; day <= last; day++ {
+            t := at(year, month, day)
+            for _, wd := range r.ByDay {
+                if t.Weekday() != wd.Day {
+                    continue
+                }
+                if wd.N == 0 || wd.N == (day-1)/7+1 || wd.N == -((last-day)/7+1) {
+                    days = append(days, t)
+                    break
+                }
+            }
+        }
+    default:
+        // 31-е число пропускает короткие месяцы, как требует RFC 5545
+        if defaultDay <= last {
+            days = append(days, at(year, month, defaultDay))
+        }
+    }
+    return days
+}
+
+// yearDays - дни года по BYDAY для YEARLY без BYMONTH и BYMONTHDAY: номер (20MO, -1FR) считается внутри года
+func (r *Rule) yearDays(year int, at func(int, time.Month, int) time.Time) []time.Time {
+    total := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+    var days []time.Time
+    for yday := 1; yday <= total; yday++ {
+        t := at(year, time.January, yday)
+        for _, wd := range r.ByDay {
+            if t.Weekday() != wd.Day {
+                continue
+            }
+            if wd.N == 0 || wd.N == (yday-1)/7+1 || wd.N == -((total-yday)/7+1) {
+                days = append(days, t)
+                break
+            }
+        }
+    }
+    return days
+}
+
+func (r *Rule) matchMonth(t time.Time) bool {
+    if len(r.ByMonth) == 0 {
+        return true
+    }
+    for _, m := range r.ByMonth {
+        if t.Month() == m {
+            return true
+        }
+    }
+    return false
+}
+
+func (r *Rule) matchMonthDay(t time.Time) bool {
+    if len(r.ByMonthDay) == 0 {
+        return true
+    }
+    last := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+    for _, md := range r.ByMonthDay {
+        if md == t.Day() || (md < 0 && last+md+1 == t.Day()) {
+            return true
+        }
+    }
+    return false
+}
+
+// matchWeekday - проверка BYDAY без номера (номера учитываются в monthDays)
+func (r *Rule) matchWeekday(t time.Time) bool {
+    if len(r.ByDay) == 0 {
+        return true
+    }
+    for _, wd := range r.ByDay {
+        if wd.Day == t.Weekday() {
+            return true
+        }
+    }
+    return false
+}
+
+func weekStart(t time.Time, wkst time.Weekday) time.Time {
+    y, m, d := t.Date()
+    offset := (int(t.Weekday()) - int(wkst) + 7) % 7
+    return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+func applySetPos(days []time.Time, setPos []int) []time.Time {
+    if len(setPos) == 0 || len(days) == 0 {
+        return days
+    }
+    var result []time.Time
+    for _, pos := range setPos {
+        i := pos - 1
+        if pos < 0 {
+            i = len(days) + pos
+        }
+        if i >= 0 && i < len(days) {
+            result = append(result, days[i])
+        }
+    }
+    sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+    return result
+}
+
+func isExcluded(t time.Time, exdates []time.Time) bool {
+    for _, ex := range exdates {
+        if ex.Equal(t) {
+            return true
+        }
+    }
+    return false
+}
+
+func parseUntil(value string) (time.Time, error) {
+    for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+        if t, err := time.Parse(layout, value); err == nil {
+            if layout == "20060102" {
+                // UNTIL в виде даты включает весь день
+                t = t.Add(24*time.Hour - time.Second)
+            }
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("неверный формат даты")
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+    var result []WeekdayNum
+    for _, item := range strings.Split(value, ",") {
+        if len(item) < 2 {
+            return nil, fmt.Errorf("неверный день %q", item)
+        }
+        day, ok := weekdays[item[len(item)-2:]]
+        if !ok {
+            return nil, fmt.Errorf("неверный день %q", item)
+        }
+        wd := WeekdayNum{Day: day}
+        if prefix := item[:len(item)-2]; prefix != "" {
+            n, err := strconv.Atoi(prefix)
+            if err != nil || n == 0 || n < -53 || n > 53 {
+                return nil, fmt.Errorf("неверный номер дня %q", item)
+            }
+            wd.N = n
+        }
+        result = append(result, wd)
+    }
+    return result, nil
+}
+
+func parseInts(value string, min, max int) ([]int, error) {
+    var result []int
+    for _, item := range strings.Split(value, ",") {
+        n, err := strconv.Atoi(item)
+        if err != nil || n == 0 || n < min || n > max {
+            return nil, fmt.Errorf("неверное значение %q", item)
+        }
+        result = append(result, n)
+    }
+    return result, nil
+}
+
+func joinInts(values []int) string {
+    parts := make([]string, len(values))
+    for i, v := range values {
+        parts[i] = strconv.Itoa(v)
+    }
+    return strings.Join(parts, ",")
+}
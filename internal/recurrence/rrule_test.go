@@ -0,0 +1,242 @@
+package recurrence
+
+import (
+    "testing"
+    "time"
+    _ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string // каноничная запись; "" - ожидается ошибка
+    }{
+        {"FREQ=DAILY", "FREQ=DAILY"},
+        {"RRULE:FREQ=WEEKLY;BYDAY=MO,WE", "FREQ=WEEKLY;BYDAY=MO,WE"},
+        {"freq=monthly;byday=-1fr", "FREQ=MONTHLY;BYDAY=-1FR"},
+        {"FREQ=YEARLY;INTERVAL=2;BYMONTH=3;BYMONTHDAY=1", "FREQ=YEARLY;INTERVAL=2;BYMONTHDAY=1;BYMONTH=3"},
+        {"FREQ=DAILY;COUNT=5", "FREQ=DAILY;COUNT=5"},
+        {"FREQ=DAILY;UNTIL=20260131", "FREQ=DAILY;UNTIL=20260131T235959Z"},
+        {"FREQ=WEEKLY;WKST=SU", "FREQ=WEEKLY;WKST=SU"},
+        {"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"},
+        {"", ""},
+        {"BYDAY=MO", ""},
+        {"FREQ=HOURLY", ""},
+        {"FREQ=DAILY;INTERVAL=0", ""},
+        {"FREQ=DAILY;COUNT=2;UNTIL=20260101", ""},
+        {"FREQ=WEEKLY;BYDAY=XX", ""},
+        {"FREQ=MONTHLY;BYDAY=0MO", ""},
+        {"FREQ=MONTHLY;BYMONTHDAY=32", ""},
+        {"FREQ=YEARLY;BYMONTH=13", ""},
+        {"FREQ=DAILY;BYHOUR=9", ""},
+        {"FREQ", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.in, func(t *testing.T) {
+            rule, err := Parse(tt.in)
+            if tt.want == "" {
+                if err == nil {
+                    t.Fatalf("Parse(%q) = %s, ожидалась ошибка", tt.in, rule)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("Parse(%q): %v", tt.in, err)
+            }
+            if got := rule.String(); got != tt.want {
+                t.Fatalf("Parse(%q).String() = %q, ожидалось %q", tt.in, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestBetween(t *testing.T) {
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    at := func(y int, m time.Month, d, h int) time.Time {
+        return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+    }
+    days := func(y int, m time.Month, h int, list ...int) []time.Time {
+        var result []time.Time
+        for _, d := range list {
+            result = append(result, at(y, m, d, h))
+        }
+        return result
+    }
+
+    tests := []struct {
+        name     string
+        rule     string
+        dtstart  time.Time
+        from, to time.Time
+        exdates  []time.Time
+        want     []time.Time
+    }{
+        {
+            name:    "каждый день, COUNT",
+            rule:    "FREQ=DAILY;COUNT=3",
+            dtstart: at(2026, 1, 1, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 2, 1, 0),
+            want:    days(2026, 1, 9, 1, 2, 3),
+        },
+        {
+            name:    "через день до UNTIL",
+            rule:    "FREQ=DAILY;INTERVAL=2;UNTIL=20260107T090000Z",
+            dtstart: at(2026, 1, 1, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 2, 1, 0),
+            want:    days(2026, 1, 9, 1, 3, 5, 7),
+        },
+        {
+            name:    "по будням, окно в середине серии",
+            rule:    "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+            dtstart: at(2026, 1, 5, 10), // понедельник
+            from:    at(2026, 1, 12, 0), to: at(2026, 1, 18, 23),
+            want:    days(2026, 1, 10, 12, 14, 16),
+        },
+        {
+            name:    "исключенные даты учитываются в COUNT",
+            rule:    "FREQ=DAILY;COUNT=4",
+            dtstart: at(2026, 1, 1, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 2, 1, 0),
+            exdates: days(2026, 1, 9, 2),
+            want:    days(2026, 1, 9, 1, 3, 4),
+        },
+        {
+            name:    "31-е пропускает короткие месяцы",
+            rule:    "FREQ=MONTHLY;COUNT=3",
+            dtstart: at(2026, 1, 31, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 12, 31, 0),
+            want:    []time.Time{at(2026, 1, 31, 9), at(2026, 3, 31, 9), at(2026, 5, 31, 9)},
+        },
+        {
+            name:    "последняя пятница месяца",
+            rule:    "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+            dtstart: at(2026, 1, 30, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 12, 31, 0),
+            want:    []time.Time{at(2026, 1, 30, 9), at(2026, 2, 27, 9), at(2026, 3, 27, 9)},
+        },
+        {
+            name:    "последний рабочий день месяца",
+            rule:    "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1;COUNT=3",
+            dtstart: at(2026, 1, 30, 9),
+            from:    at(2026, 1, 1, 0), to: at(2026, 12, 31, 0),
+            want:    []time.Time{at(2026, 1, 30, 9), at(2026, 2, 27, 9), at(2026, 3, 31, 9)},
+        },
+        {
+            name:    "YEARLY по дате dtstart",
+            rule:    "FREQ=YEARLY;COUNT=3",
+            dtstart: at(2026, 3, 8, 9),
+            from:    at(2026, 1, 1, 0), to: at(2030, 1, 1, 0),
+            want:    []time.Time{at(2026, 3, 8, 9), at(2027, 3, 8, 9), at(2028, 3, 8, 9)},
+        },
+        {
+            name:    "YEARLY с номером дня внутри года",
+            rule:    "FREQ=YEARLY;BYDAY=20MO",
+            dtstart: at(2024, 1, 1, 9),
+            from:    at(2024, 1, 2, 0), to: at(2026, 1, 1, 0),
+            want:    []time.Time{at(2024, 5, 13, 9), at(2025, 5, 19, 9)},
+        },
+        {
+            name:    "YEARLY последняя пятница года",
+            rule:    "FREQ=YEARLY;BYDAY=-1FR",
+            dtstart: at(2024, 1, 1, 9),
+            from:    at(2024, 1, 2, 0), to: at(2026, 1, 1, 0),
+            want:    []time.Time{at(2024, 12, 27, 9), at(2025, 12, 26, 9)},
+        },
+        {
+            name:    "YEARLY с BYMONTH: номер внутри месяца",
+            rule:    "FREQ=YEARLY;BYMONTH=3;BYDAY=-1FR",
+            dtstart: at(2024, 1, 1, 9),
+            from:    at(2024, 1, 2, 0), to: at(2026, 1, 1, 0),
+            want:    []time.Time{at(2024, 3, 29, 9), at(2025, 3, 28, 9)},
+        },
+        {
+            name:    "YEARLY с BYMONTHDAY без BYMONTH - каждый месяц",
+            rule:    "FREQ=YEARLY;BYMONTHDAY=1;COUNT=4",
+            dtstart: at(2024, 1, 1, 9),
+            from:    at(2024, 1, 1, 0), to: at(2026, 1, 1, 0),
+            want:    []time.Time{at(2024, 1, 1, 9), at(2024, 2, 1, 9), at(2024, 3, 1, 9), at(2024, 4, 1, 9)},
+        },
+        {
+            name:    "время суток сохраняется при переходе на летнее время",
+            rule:    "FREQ=WEEKLY;COUNT=2",
+            dtstart: time.Date(2026, 3, 23, 10, 0, 0, 0, berlin),
+            from:    at(2026, 3, 1, 0), to: at(2026, 4, 30, 0),
+            want:    []time.Time{time.Date(2026, 3, 23, 10, 0, 0, 0, berlin), time.Date(2026, 3, 30, 10, 0, 0, 0, berlin)},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rule, err := Parse(tt.rule)
+            if err != nil {
+                t.Fatalf("Parse(%q): %v", tt.rule, err)
+            }
+            got := rule.Between(tt.dtstart, tt.from, tt.to, tt.exdates)
+            if len(got) != len(tt.want) {
+                t.Fatalf("%s: %v, ожидалось %v", tt.rule, got, tt.want)
+            }
+            for i := range got {
+                if !got[i].Equal(tt.want[i]) {
+                    t.Fatalf("%s: вхождение %d = %v, ожидалось %v", tt.rule, i, got[i], tt.want[i])
+                }
+            }
+        })
+    }
+}
+
+func TestAfter(t *testing.T) {
+    dtstart := time.Date(2026, 1, 5, 10, 0, 0, 0, time.UTC) // понедельник
+    tests := []struct {
+        name    string
+        rule    string
+        after   time.Time
+        exdates []time.Time
+        want    time.Time
+        ok      bool
+    }{
+        {
+            name:  "следующее после dtstart",
+            rule:  "FREQ=WEEKLY;BYDAY=MO,TH",
+            after: dtstart,
+            want:  time.Date(2026, 1, 8, 10, 0, 0, 0, time.UTC),
+            ok:    true,
+        },
+        {
+            name:    "исключенное вхождение пропускается",
+            rule:    "FREQ=WEEKLY;BYDAY=MO,TH",
+            after:   dtstart,
+            exdates: []time.Time{time.Date(2026, 1, 8, 10, 0, 0, 0, time.UTC)},
+            want:    time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC),
+            ok:      true,
+        },
+        {
+            name:  "серия закончилась по COUNT",
+            rule:  "FREQ=DAILY;COUNT=2",
+            after: time.Date(2026, 1, 6, 10, 0, 0, 0, time.UTC),
+        },
+        {
+            name:  "серия закончилась по UNTIL",
+            rule:  "FREQ=DAILY;UNTIL=20260107T000000Z",
+            after: time.Date(2026, 1, 6, 10, 0, 0, 0, time.UTC),
+        },
+        {
+            name:  "правило без вхождений не зацикливается",
+            rule:  "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30",
+            after: dtstart,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rule, err := Parse(tt.rule)
+            if err != nil {
+                t.Fatalf("Parse(%q): %v", tt.rule, err)
+            }
+            got, ok := rule.After(dtstart, tt.after, tt.exdates)
+            if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+                t.Fatalf("After(%v) = %v, %v; ожидалось %v, %v", tt.after, got, ok, tt.want, tt.ok)
+            }
+        })
+    }
+}
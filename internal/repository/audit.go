@@ -0,0 +1,232 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "time"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// SystemActor - автор изменений без пользователя: планировщик, синхронизация календарей
+const SystemActor = "system"
+
+// Размер страницы журнала
+const (
+    DefaultAuditLimit = 100
+    MaxAuditLimit     = 500
+)
+
+type actorKey struct{}
+
+// WithActor - контекст, изменения в котором записываются в историю от имени actor
+func WithActor(ctx context.Context, actor string) context.Context {
+    return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFrom - автор изменения из контекста
+func actorFrom(ctx context.Context) string {
+    if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+        return actor
+    }
+    return SystemActor
+}
+
+// auditFields - поля задачи (имена JSON), изменения которых попадают в историю.
+// Служебные (ранг, версия, счетчик напоминаний) не пишутся: их меняет сама система
+var auditFields = []string{
+    "title", "description", "status", "priority", "deadline", "start_date", "end_date",
+    "assignee", "tags", "board_id", "column_id", "rrule", "exdates", "all_day",
+    "archived_at", "deleted_at", "parent_id",
+}
+
+// loadTaskTx - задача внутри транзакции (видит еще не зафиксированные изменения)
+func loadTaskTx(ctx context.Context, tx *sql.Tx, id int) (*models.Task, error) {
+    task, err := scanTask(tx.QueryRowContext(ctx, taskSelect+` WHERE t.id = $1`, id))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("задача с ID %d: %w", id, ErrNotFound)
+    }
+    return task, err
+}
+
+// taskFields - поля задачи для сравнения в том виде, в каком их отдает API
+func taskFields(task *models.Task) (map[string]interface{}, error) {
+    fields := map[string]interface{}{}
+    if task == nil {
+        return fields, nil
+    }
+    raw, err := json.Marshal(task)
+    if err != nil {
+        return nil, err
+    }
+    if err := json.Unmarshal(raw, &fields); err != nil {
+        return nil, err
+    }
+    // Пустые значения приводятся к null, чтобы "" -> "" и null -> [] не считались изменением
+    for key, value := range fields {
+        switch v := value.(type) {
+        case string:
+            if v == "" {
+                fields[key] = nil
+            }
+        case []interface{}:
+            if len(v) == 0 {
+                fields[key] = nil
+            }
+        }
+    }
+    return fields, nil
+}
+
+// taskChanges - различия before и after по полям истории (nil - задачи не было или больше нет)
+func taskChanges(before, after *models.Task) (map[string]models.FieldChange, error) {
+    old, err := taskFields(before)
+    if err != nil {
+        return nil, err
+    }
+    cur, err := taskFields(after)
+    if err != nil {
+        return nil, err
+    }
+
+    changes := map[string]models.FieldChange{}
+    for _, field := range auditFields {
+        if !reflect.DeepEqual(old[field], cur[field]) {
+            changes[field] = models.FieldChange{Before: old[field], After: cur[field]}
+        }
+    }
+    return changes, nil
+}
+
+// recordEvent - пишет событие в историю в транзакции изменения. Правка без изменений
+// в полях истории (например, только перестановка внутри колонки) не записывается
+func recordEvent(ctx context.Context, tx *sql.Tx, action string, before, after *models.Task) error {
+    changes, err := taskChanges(before, after)
+    if err != nil {
+        return err
+    }
+    if len(changes) == 0 && (action == models.EventUpdate || action == models.EventMove) {
+        return nil
+    }
+
+    subject := after
+    if subject == nil {
+        subject = before
+    }
+    return insertEvent(ctx, tx, action, subject, changes)
+}
+
+// insertEvent - запись истории задачи task с уже посчитанными изменениями
+func insertEvent(ctx context.Context, tx *sql.Tx, action string, task *models.Task, changes map[string]models.FieldChange) error {
+    raw, err := json.Marshal(changes)
+    if err != nil {
+        return err
+    }
+    query := `
+        INSERT INTO task_events (task_id, board_id, task_title, action, actor, changes)
+        VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6)
+    `
+    _, err = tx.ExecContext(ctx, query, task.ID, task.BoardID, task.Title, action, actorFrom(ctx), string(raw))
+    return err
+}
+
+// AuditFilter - отбор записей журнала (пустые поля не фильтруют)
+type AuditFilter struct {
+    TaskID  int
+    BoardID int
+    Actors  []string
+    Actions []string
+    Field   string // менялось поле (deadline, status...)
+    From    *time.Time
+    To      *time.Time
+    Cursor  int64 // next_cursor предыдущей страницы: записи старше этой
+    Limit   int
+}
+
+const eventSelect = `
+    SELECT id, task_id, COALESCE(board_id, 0), task_title, action, actor, changes, created_at
+    FROM task_events
+`
+
+// GetTaskHistory - история задачи от создания к последнему изменению (в том числе удаленной навсегда)
+func (r *TaskRepository) GetTaskHistory(ctx context.Context, taskID int) ([]models.TaskEvent, error) {
+    return r.queryEvents(ctx, eventSelect+` WHERE task_id = $1 ORDER BY id`, taskID)
+}
+
+// GetAudit - журнал изменений всех задач, новые первыми; next - курсор следующей страницы (0 - последняя)
+func (r *TaskRepository) GetAudit(ctx context.Context, f AuditFilter) ([]models.TaskEvent, int64, error) {
+    var args []interface{}
+    var conds []string
+    arg := func(v interface{}) string {
+        args = append(args, v)
+        return fmt.Sprintf("$%d", len(args))
+    }
+
+    if f.TaskID != 0 {
+        conds = append(conds, "task_id = "+arg(f.TaskID))
+    }
+    if f.BoardID != 0 {
+        conds = append(conds, "board_id = "+arg(f.BoardID))
+    }
+    if len(f.Actors) > 0 {
+        conds = append(conds, "actor = ANY("+arg(pq.Array(f.Actors))+")")
+    }
+    if len(f.Actions) > 0 {
+        conds = append(conds, "action = ANY("+arg(pq.Array(f.Actions))+")")
+    }
+    if f.Field != "" {
+        conds = append(conds, "changes ? "+arg(f.Field))
+    }
+    if f.From != nil {
+        conds = append(conds, "created_at >= "+arg(*f.From))
+    }
+    if f.To != nil {
+        conds = append(conds, "created_at <= "+arg(*f.To))
+    }
+    if f.Cursor != 0 {
+        conds = append(conds, "id < "+arg(f.Cursor))
+    }
+
+    limit := f.Limit
+    if limit <= 0 {
+        limit = DefaultAuditLimit
+    }
+    if limit > MaxAuditLimit {
+        limit = MaxAuditLimit
+    }
+    query := eventSelect + whereClause(conds) + ` ORDER BY id DESC LIMIT ` + arg(limit+1)
+
+    events, err := r.queryEvents(ctx, query, args...)
+    if err != nil || len(events) <= limit {
+        return events, 0, err
+    }
+    events = events[:limit]
+    return events, events[limit-1].ID, nil
+}
+
+func (r *TaskRepository) queryEvents(ctx context.Context, query string, args ...interface{}) ([]models.TaskEvent, error) {
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    events := []models.TaskEvent{}
+    for rows.Next() {
+        var event models.TaskEvent
+        var changes []byte
+        err := rows.Scan(&event.ID, &event.TaskID, &event.BoardID, &event.TaskTitle,
+            &event.Action, &event.Actor, &changes, &event.CreatedAt)
+        if err != nil {
+            return nil, err
+        }
+        if err := json.Unmarshal(changes, &event.Changes); err != nil {
+            return nil, err
+        }
+        events = append(events, event)
+    }
+    return events, rows.Err()
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+    "unicode"
+    "kanban-calendar/internal/models"
+)
+
+const columnSelect = `
+    SELECT id, board_id, slug, name, position, is_done, COALESCE(color, ''),
+           COALESCE(wip_limit, 0), wip_mode, created_at, updated_at
+    FROM board_columns
+`
+
+func scanColumn(row rowScanner) (*models.BoardColumn, error) {
+    col := &models.BoardColumn{}
+    err := row.Scan(
+        &col.ID, &col.BoardID, &col.Slug, &col.Name, &col.Position,
+        &col.IsDone, &col.Color, &col.WIPLimit, &col.WIPMode, &col.CreatedAt, &col.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    return col, nil
+}
+
+// Slugify - строит slug колонки из названия: "Code Review" -> "code_review"
+func Slugify(name string) string {
+    var b strings.Builder
+    lastUnderscore := false
+    for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            b.WriteRune(r)
+            lastUnderscore = false
+        } else if !lastUnderscore && b.Len() > 0 {
+            b.WriteRune('_')
+            lastUnderscore = true
+        }
+    }
+    return strings.TrimSuffix(b.String(), "_")
+}
+
+// GetBoards - список досок (без колонок)
+func (r *TaskRepository) GetBoards(ctx context.Context) ([]models.Board, error) {
+    query := `
+        SELECT id, name, COALESCE(description, ''), created_at, updated_at
+        FROM boards
+        ORDER BY id
+    `
+    rows, err := r.db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var boards []models.Board
+    for rows.Next() {
+        var b models.Board
+        if err := rows.Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt); err != nil {
+            return nil, err
+        }
+        boards = append(boards, b)
+    }
+    return boards, rows.Err()
+}
+
+// GetBoardByID - доска вместе с колонками
+func (r *TaskRepository) GetBoardByID(ctx context.Context, id int) (*models.Board, error) {
+    query := `
+        SELECT id, name, COALESCE(description, ''), created_at, updated_at
+        FROM boards WHERE id = $1
+    `
+    b := &models.Board{}
+    err := r.db.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.Name, &b.Description, &b.CreatedAt, &b.UpdatedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("доска с ID %d: %w", id, ErrNotFound)
+        }
+        return nil, err
+    }
+
+    b.Columns, err = r.GetColumns(ctx, id)
+    if err != nil {
+        return nil, err
+    }
+    return b, nil
+}
+
+// CreateBoard - создает доску и ее колонки в одной транзакции
+func (r *TaskRepository) CreateBoard(ctx context.Context, board *models.Board, columns []models.CreateColumnRequest) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO boards (name, description)
+        VALUES ($1, $2)
+        RETURNING id, created_at, updated_at
+    `
+    err = tx.QueryRowContext(ctx, query, board.Name, board.Description).
+        Scan(&board.ID, &board.CreatedAt, &board.UpdatedAt)
+    if err != nil {
+        return err
+    }
+
+    board.Columns = nil
+    for i, req := range columns {
+        col := columnFromRequest(board.ID, req, i+1)
+        if err := insertColumn(ctx, tx, col); err != nil {
+            return err
+        }
+        board.Columns = append(board.Columns, *col)
+    }
+
+    return tx.Commit()
+}
+
+// UpdateBoard - обновляет название и описание доски
+func (r *TaskRepository) UpdateBoard(ctx context.Context, board *models.Board) error {
+    query := `
+        UPDATE boards SET name = $1, description = $2
+        WHERE id = $3
+        RETURNING updated_at
+    `
+    err := r.db.QueryRowContext(ctx, query, board.Name, board.Description, board.ID).Scan(&board.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("доска с ID %d: %w", board.ID, ErrNotFound)
+    }
+    return err
+}
+
+// DeleteBoard - удаляет доску, если на ней нет задач
+func (r *TaskRepository) DeleteBoard(ctx context.Context, id int) error {
+    var count int
+    if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE board_id = $1`, id).Scan(&count); err != nil {
+        return err
+    }
+    if count > 0 {
+        return fmt.Errorf("на доске %d задач(и): %w", count, ErrConflict)
+    }
+
+    result, err := r.db.ExecContext(ctx, `DELETE FROM boards WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if rows, _ := result.RowsAffected(); rows == 0 {
+        return fmt.Errorf("доска с ID %d: %w", id, ErrNotFound)
+    }
+    return nil
+}
+
+// GetColumns - колонки доски по порядку
+func (r *TaskRepository) GetColumns(ctx context.Context, boardID int) ([]models.BoardColumn, error) {
+    rows, err := r.db.QueryContext(ctx, columnSelect+` WHERE board_id = $1 ORDER BY position, id`, boardID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var columns []models.BoardColumn
+    for rows.Next() {
+        col, err := scanColumn(rows)
+        if err != nil {
+            return nil, err
+        }
+        columns = append(columns, *col)
+    }
+    return columns, rows.Err()
+}
+
+// GetColumnByID - колонка по ID
+func (r *TaskRepository) GetColumnByID(ctx context.Context, id int) (*models.BoardColumn, error) {
+    col, err := scanColumn(r.db.QueryRowContext(ctx, columnSelect+` WHERE id = $1`, id))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("колонка с ID %d: %w", id, ErrNotFound)
+    }
+    return col, err
+}
+
+// CreateColumn - добавляет колонку на доску (по умолчанию в конец)
+func (r *TaskRepository) CreateColumn(ctx context.Context, boardID int, req models.CreateColumnRequest) (*models.BoardColumn, error) {
+    if _, err := r.GetBoardByID(ctx, boardID); err != nil {
+        return nil, err
+    }
+
+    var next int
+    err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(position), 0) + 1 FROM board_columns WHERE board_id = $1`, boardID).Scan(&next)
+    if err != nil {
+        return nil, err
+    }
+
+    col := columnFromRequest(boardID, req, next)
+    if err := insertColumn(ctx, r.db, col); err != nil {
+        return nil, err
+    }
+    return col, nil
+}
+
+// UpdateColumn - обновляет колонку. При смене slug статус задач колонки меняется вместе с ним
+func (r *TaskRepository) UpdateColumn(ctx context.Context, col *models.BoardColumn) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    query := `
+        UPDATE board_columns
+        SET slug = $1, name = $2, position = $3, is_done = $4, color = NULLIF($5, ''),
+            wip_limit = NULLIF($6, 0), wip_mode = $7
+        WHERE id = $8
+        RETURNING updated_at
+    `
+    err = tx.QueryRowContext(ctx, query,
+        col.Slug, col.Name, col.Position, col.IsDone, col.Color, col.WIPLimit, col.WIPMode, col.ID,
+    ).Scan(&col.UpdatedAt)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return fmt.Errorf("колонка с ID %d: %w", col.ID, ErrNotFound)
+        }
+        return mapUniqueViolation(err, fmt.Sprintf("колонка со slug %q", col.Slug))
+    }
+
+    if _, err := tx.ExecContext(ctx, `UPDATE tasks SET status = $1, version = version + 1 WHERE column_id = $2 AND status <> $1`, col.Slug, col.ID); err != nil {
+        return err
+    }
+
+    return tx.Commit()
+}
+
+// DeleteColumn - удаляет колонку, если в ней нет задач
+func (r *TaskRepository) DeleteColumn(ctx context.Context, id int) error {
+    var count int
+    if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE column_id = $1`, id).Scan(&count); err != nil {
+        return err
+    }
+    if count > 0 {
+        return fmt.Errorf("в колонке %d задач(и): %w", count, ErrConflict)
+    }
+
+    result, err := r.db.ExecContext(ctx, `DELETE FROM board_columns WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if rows, _ := result.RowsAffected(); rows == 0 {
+        return fmt.Errorf("колонка с ID %d: %w", id, ErrNotFound)
+    }
+    return nil
+}
+
+// ResolveColumn - определяет колонку для задачи: по columnID, иначе по статусу на доске,
+// иначе первая колонка доски. boardID = 0 - доска по умолчанию
+func (r *TaskRepository) ResolveColumn(ctx context.Context, boardID, columnID int, status models.TaskStatus) (*models.BoardColumn, error) {
+    if columnID != 0 {
+        col, err := r.GetColumnByID(ctx, columnID)
+        if err != nil {
+            return nil, err
+        }
+        if boardID != 0 && col.BoardID != boardID {
+            return nil, fmt.Errorf("колонка %d не принадлежит доске %d: %w", columnID, boardID, ErrNotFound)
+        }
+        return col, nil
+    }
+
+    if boardID == 0 {
+        err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MIN(id), 0) FROM boards`).Scan(&boardID)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    var row *sql.Row
+    if status != "" {
+        row = r.db.QueryRowContext(ctx, columnSelect+` WHERE board_id = $1 AND slug = $2`, boardID, status)
+    } else {
+        row = r.db.QueryRowContext(ctx, columnSelect+` WHERE board_id = $1 ORDER BY position, id LIMIT 1`, boardID)
+    }
+
+    col, err := scanColumn(row)
+    if err == sql.ErrNoRows {
+        if status != "" {
+            return nil, fmt.Errorf("статус %q отсутствует на доске %d: %w", status, boardID, ErrNotFound)
+        }
+        return nil, fmt.Errorf("у доски %d нет колонок: %w", boardID, ErrNotFound)
+    }
+    return col, err
+}
+
+// ImportColumn - куда кладутся импортированные задачи: колонка todo доски, иначе ее первая колонка
+func (r *TaskRepository) ImportColumn(ctx context.Context, boardID int) (*models.BoardColumn, error) {
+    col, err := r.ResolveColumn(ctx, boardID, 0, models.StatusTodo)
+    if err != nil {
+        col, err = r.ResolveColumn(ctx, boardID, 0, "")
+    }
+    return col, err
+}
+
+// StatusExists - есть ли колонка с таким slug (на доске или на любой доске при boardID = 0)
+func (r *TaskRepository) StatusExists(ctx context.Context, status models.TaskStatus, boardID int) (bool, error) {
+    var exists bool
+    query := `SELECT EXISTS (SELECT 1 FROM board_columns WHERE slug = $1 AND ($2 = 0 OR board_id = $2))`
+    err := r.db.QueryRowContext(ctx, query, status, boardID).Scan(&exists)
+    return exists, err
+}
+
+// execQuerier - общий интерфейс *sql.DB и *sql.Tx
+type execQuerier interface {
+    ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+    QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+    QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func columnFromRequest(boardID int, req models.CreateColumnRequest, defaultPosition int) *models.BoardColumn {
+    slug := req.Slug
+    if slug == "" {
+        slug = Slugify(req.Name)
+    }
+    position := defaultPosition
+    if req.Position != nil {
+        position = *req.Position
+    }
+    mode := req.WIPMode
+    if mode == "" {
+        mode = models.WIPModeReject
+    }
+    return &models.BoardColumn{
+        BoardID:  boardID,
+        Slug:     models.TaskStatus(slug),
+        Name:     req.Name,
+        Position: position,
+        IsDone:   req.IsDone,
+        Color:    req.Color,
+        WIPLimit: req.WIPLimit,
+        WIPMode:  mode,
+    }
+}
+
+func insertColumn(ctx context.Context, q execQuerier, col *models.BoardColumn) error {
+    if col.Slug == "" {
+        return fmt.Errorf("пустой slug колонки %q: %w", col.Name, ErrConflict)
+    }
+    query := `
+        INSERT INTO board_columns (board_id, slug, name, position, is_done, color, wip_limit, wip_mode)
+        VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, 0), $8)
+        RETURNING id, created_at, updated_at
+    `
+    err := q.QueryRowContext(ctx, query,
+        col.BoardID, col.Slug, col.Name, col.Position, col.IsDone, col.Color, col.WIPLimit, col.WIPMode,
+    ).Scan(&col.ID, &col.CreatedAt, &col.UpdatedAt)
+    return mapUniqueViolation(err, fmt.Sprintf("колонка со slug %q", col.Slug))
+}
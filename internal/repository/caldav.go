@@ -0,0 +1,83 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strconv"
+    "strings"
+    "kanban-calendar/internal/models"
+)
+
+// CalDAVResourceName - имя ресурса задачи в коллекции доски: имя, выбранное клиентом, иначе task-<id>.ics
+func CalDAVResourceName(task *models.Task) string {
+    if task.CalDAVName != "" {
+        return task.CalDAVName
+    }
+    return fmt.Sprintf("task-%d.ics", task.ID)
+}
+
+// resourceTaskID - ID задачи из имени ресурса task-<id>.ics; 0 - имя выбрал клиент
+func resourceTaskID(name string) int {
+    rest, ok := strings.CutPrefix(name, "task-")
+    if !ok {
+        return 0
+    }
+    rest, ok = strings.CutSuffix(rest, ".ics")
+    if !ok {
+        return 0
+    }
+    id, err := strconv.Atoi(rest)
+    if err != nil || id <= 0 || strconv.Itoa(id) != rest {
+        return 0
+    }
+    return id
+}
+
+// GetCalDAVTask - неархивная задача доски по имени ресурса (правило как в CalDAVResourceName).
+// Ищется по индексу имени ресурса или по первичному ключу, без перебора задач доски
+func (r *TaskRepository) GetCalDAVTask(ctx context.Context, boardID int, name string) (*models.Task, error) {
+    query := taskSelect + `
+        WHERE t.board_id = $1 AND t.archived_at IS NULL AND t.deleted_at IS NULL
+          AND (t.caldav_name = $2 OR (t.caldav_name IS NULL AND t.id = $3))
+    `
+    task, err := scanTask(r.db.QueryRowContext(ctx, query, boardID, name, resourceTaskID(name)))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("ресурс %s на доске %d: %w", name, boardID, ErrNotFound)
+    }
+    return task, err
+}
+
+// GetCalDAVTaskByUID - неархивная задача доски с UID ресурса: импортированная с этим external_uid
+// или созданная на сервере (taskID из UID вида task-<id>@..., 0 - UID не серверный)
+func (r *TaskRepository) GetCalDAVTaskByUID(ctx context.Context, boardID int, uid string, taskID int) (*models.Task, error) {
+    query := taskSelect + `
+        WHERE t.board_id = $1 AND t.archived_at IS NULL AND t.deleted_at IS NULL
+          AND (t.external_uid = $2 OR (t.external_uid IS NULL AND t.id = $3))
+        LIMIT 1
+    `
+    task, err := scanTask(r.db.QueryRowContext(ctx, query, boardID, uid, taskID))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("UID %s на доске %d: %w", uid, boardID, ErrNotFound)
+    }
+    return task, err
+}
+
+// SetCalDAVResource - запоминает имя ресурса и тип компонента, с которыми задачу сохранил клиент
+func (r *TaskRepository) SetCalDAVResource(ctx context.Context, taskID int, name, component string) error {
+    query := `UPDATE tasks SET caldav_name = NULLIF($2, ''), caldav_component = NULLIF($3, '') WHERE id = $1`
+    _, err := r.db.ExecContext(ctx, query, taskID, name, component)
+    return mapUniqueViolation(err, "ресурс "+name)
+}
+
+// CalendarCTag - версия коллекции доски: меняется при любом изменении, переносе или удалении ее задач
+func (r *TaskRepository) CalendarCTag(ctx context.Context, boardID int) (string, error) {
+    query := `
+        SELECT md5(COALESCE(string_agg(t.id || ':' || t.updated_at || ':' || c.slug || ':' || c.is_done, ',' ORDER BY t.id), ''))
+        FROM tasks t JOIN board_columns c ON c.id = t.column_id
+        WHERE t.board_id = $1 AND t.archived_at IS NULL AND t.deleted_at IS NULL
+    `
+    var ctag string
+    err := r.db.QueryRowContext(ctx, query, boardID).Scan(&ctag)
+    return ctag, err
+}
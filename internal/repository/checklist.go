@@ -0,0 +1,270 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "kanban-calendar/internal/models"
+)
+
+// checkParent - parentID может стать родителем задачи taskID (0 - задача еще не создана):
+// родитель существует, не в корзине и не является самой задачей или ее подзадачей
+func checkParent(ctx context.Context, tx *sql.Tx, taskID, parentID int) error {
+    if parentID == 0 {
+        return nil
+    }
+    if parentID == taskID {
+        return fmt.Errorf("задача %d не может быть своей подзадачей: %w", taskID, ErrInvalidRelation)
+    }
+
+    var deleted bool
+    err := tx.QueryRowContext(ctx, `SELECT deleted_at IS NOT NULL FROM tasks WHERE id = $1`, parentID).Scan(&deleted)
+    if err == sql.ErrNoRows || deleted {
+        return fmt.Errorf("родительская задача %d не найдена: %w", parentID, ErrInvalidRelation)
+    }
+    if err != nil {
+        return err
+    }
+    if taskID == 0 {
+        return nil
+    }
+
+    // Если задача есть среди предков нового родителя, связь замкнет цикл
+    var cycle bool
+    query := `
+        WITH RECURSIVE ancestors(id) AS (
+            SELECT $1::int
+            UNION
+            SELECT t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+        )
+        SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)
+    `
+    if err := tx.QueryRowContext(ctx, query, parentID, taskID).Scan(&cycle); err != nil {
+        return err
+    }
+    if cycle {
+        return fmt.Errorf("задача %d уже входит в задачу %d: %w", parentID, taskID, ErrInvalidRelation)
+    }
+    return nil
+}
+
+// GetSubtasks - подзадачи задачи в порядке досок и колонок
+func (r *TaskRepository) GetSubtasks(ctx context.Context, parentID int) ([]models.Task, error) {
+    query := taskSelect + ` WHERE t.parent_id = $1 AND t.deleted_at IS NULL ORDER BY t.board_id, c.position, t.position, t.id`
+    return r.queryTasks(ctx, query, parentID)
+}
+
+const checklistSelect = `
+    SELECT id, task_id, text, done, position, created_at, updated_at
+    FROM checklist_items
+`
+
+func scanChecklistItem(row rowScanner) (*models.ChecklistItem, error) {
+    item := &models.ChecklistItem{}
+    err := row.Scan(&item.ID, &item.TaskID, &item.Text, &item.Done, &item.Position, &item.CreatedAt, &item.UpdatedAt)
+    return item, err
+}
+
+// GetChecklist - пункты чек-листа задачи по порядку
+func (r *TaskRepository) GetChecklist(ctx context.Context, taskID int) ([]models.ChecklistItem, error) {
+    rows, err := r.db.QueryContext(ctx, checklistSelect+` WHERE task_id = $1 ORDER BY position, id`, taskID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var items []models.ChecklistItem
+    for rows.Next() {
+        item, err := scanChecklistItem(rows)
+        if err != nil {
+            return nil, err
+        }
+        items = append(items, *item)
+    }
+    return items, rows.Err()
+}
+
+// CreateChecklistItem - добавляет пункт в чек-лист (без position - в конец)
+func (r *TaskRepository) CreateChecklistItem(ctx context.Context, taskID int, req models.ChecklistItemRequest) (*models.ChecklistItem, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    task, err := lockChecklistTask(ctx, tx, taskID)
+    if err != nil {
+        return nil, err
+    }
+
+    done := req.Done != nil && *req.Done
+    var id int
+    query := `
+        INSERT INTO checklist_items (task_id, text, done, position)
+        VALUES ($1, $2, $3, (SELECT COUNT(*) FROM checklist_items WHERE task_id = $1))
+        RETURNING id
+    `
+    if err := tx.QueryRowContext(ctx, query, taskID, *req.Text, done).Scan(&id); err != nil {
+        return nil, err
+    }
+    if req.Position != nil {
+        if err := placeChecklistItem(ctx, tx, taskID, id, *req.Position); err != nil {
+            return nil, err
+        }
+    }
+
+    item, err := scanChecklistItem(tx.QueryRowContext(ctx, checklistSelect+` WHERE id = $1`, id))
+    if err != nil {
+        return nil, err
+    }
+    if err := recordChecklistChange(ctx, tx, task, nil, item); err != nil {
+        return nil, err
+    }
+    return item, tx.Commit()
+}
+
+// UpdateChecklistItem - меняет текст, отметку и место пункта (незаданные поля остаются)
+func (r *TaskRepository) UpdateChecklistItem(ctx context.Context, taskID, itemID int, req models.ChecklistItemRequest) (*models.ChecklistItem, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    task, err := lockChecklistTask(ctx, tx, taskID)
+    if err != nil {
+        return nil, err
+    }
+    before, err := scanChecklistItem(tx.QueryRowContext(ctx, checklistSelect+` WHERE id = $1 AND task_id = $2`, itemID, taskID))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("пункт %d чек-листа задачи %d: %w", itemID, taskID, ErrNotFound)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    text, done := before.Text, before.Done
+    if req.Text != nil {
+        text = *req.Text
+    }
+    if req.Done != nil {
+        done = *req.Done
+    }
+    query := `UPDATE checklist_items SET text = $1, done = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3`
+    if _, err := tx.ExecContext(ctx, query, text, done, itemID); err != nil {
+        return nil, err
+    }
+    if req.Position != nil && *req.Position != before.Position {
+        if err := placeChecklistItem(ctx, tx, taskID, itemID, *req.Position); err != nil {
+            return nil, err
+        }
+    }
+
+    item, err := scanChecklistItem(tx.QueryRowContext(ctx, checklistSelect+` WHERE id = $1`, itemID))
+    if err != nil {
+        return nil, err
+    }
+    if err := recordChecklistChange(ctx, tx, task, before, item); err != nil {
+        return nil, err
+    }
+    return item, tx.Commit()
+}
+
+// DeleteChecklistItem - удаляет пункт; остальные сдвигаются, не оставляя пропуска в порядке
+func (r *TaskRepository) DeleteChecklistItem(ctx context.Context, taskID, itemID int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    task, err := lockChecklistTask(ctx, tx, taskID)
+    if err != nil {
+        return err
+    }
+    before, err := scanChecklistItem(tx.QueryRowContext(ctx, checklistSelect+` WHERE id = $1 AND task_id = $2`, itemID, taskID))
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("пункт %d чек-листа задачи %d: %w", itemID, taskID, ErrNotFound)
+    }
+    if err != nil {
+        return err
+    }
+
+    if _, err := tx.ExecContext(ctx, `DELETE FROM checklist_items WHERE id = $1`, itemID); err != nil {
+        return err
+    }
+    query := `UPDATE checklist_items SET position = position - 1 WHERE task_id = $1 AND position > $2`
+    if _, err := tx.ExecContext(ctx, query, taskID, before.Position); err != nil {
+        return err
+    }
+    if err := recordChecklistChange(ctx, tx, task, before, nil); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// lockChecklistTask - блокирует задачу на время правки ее чек-листа. Правка меняет версию задачи:
+// ETag отражает и чек-лист, и прогресс
+func lockChecklistTask(ctx context.Context, tx *sql.Tx, taskID int) (*models.Task, error) {
+    query := `
+        UPDATE tasks SET version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+    res, err := tx.ExecContext(ctx, query, taskID)
+    if err != nil {
+        return nil, err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return nil, fmt.Errorf("задача с ID %d: %w", taskID, ErrNotFound)
+    }
+    return loadTaskTx(ctx, tx, taskID)
+}
+
+// placeChecklistItem - ставит пункт на место position (за пределами списка - в конец), остальные сдвигаются
+func placeChecklistItem(ctx context.Context, tx *sql.Tx, taskID, itemID, position int) error {
+    rows, err := tx.QueryContext(ctx, `SELECT id FROM checklist_items WHERE task_id = $1 AND id <> $2 ORDER BY position, id`, taskID, itemID)
+    if err != nil {
+        return err
+    }
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return err
+        }
+        ids = append(ids, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    if position < 0 {
+        position = 0
+    }
+    if position > len(ids) {
+        position = len(ids)
+    }
+    ids = append(ids[:position], append([]int{itemID}, ids[position:]...)...)
+    for i, id := range ids {
+        if _, err := tx.ExecContext(ctx, `UPDATE checklist_items SET position = $1 WHERE id = $2 AND position <> $1`, i, id); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// recordChecklistChange - правка чек-листа в истории задачи: пункт до и после (nil - пункта не было или больше нет)
+func recordChecklistChange(ctx context.Context, tx *sql.Tx, task *models.Task, before, after *models.ChecklistItem) error {
+    value := func(item *models.ChecklistItem) interface{} {
+        if item == nil {
+            return nil
+        }
+        return map[string]interface{}{"id": item.ID, "text": item.Text, "done": item.Done, "position": item.Position}
+    }
+    changes := map[string]models.FieldChange{
+        "checklist": {Before: value(before), After: value(after)},
+    }
+    return insertEvent(ctx, tx, models.EventUpdate, task, changes)
+}
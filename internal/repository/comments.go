@@ -0,0 +1,321 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+    "kanban-calendar/internal/markdown"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// mentionExcerpt - сколько символов комментария попадает в уведомление об упоминании
+const mentionExcerpt = 300
+
+const commentSelect = `
+    SELECT c.id, c.task_id, c.author, c.body, c.created_at, c.updated_at, c.edited_at,
+           (SELECT COUNT(*) FROM task_comment_revisions r WHERE r.comment_id = c.id)
+    FROM task_comments c
+`
+
+func scanComment(row rowScanner) (*models.Comment, error) {
+    comment := &models.Comment{}
+    var editedAt sql.NullTime
+    err := row.Scan(&comment.ID, &comment.TaskID, &comment.Author, &comment.Body,
+        &comment.CreatedAt, &comment.UpdatedAt, &editedAt, &comment.Revisions)
+    if err != nil {
+        return nil, err
+    }
+    if editedAt.Valid {
+        comment.EditedAt = &editedAt.Time
+    }
+    comment.BodyHTML = markdown.ToHTML(comment.Body)
+    comment.Mentions = markdown.Mentions(comment.Body)
+    if comment.Mentions == nil {
+        comment.Mentions = []string{}
+    }
+    return comment, nil
+}
+
+// GetComments - комментарии задачи от старых к новым
+func (r *TaskRepository) GetComments(ctx context.Context, taskID int) ([]models.Comment, error) {
+    rows, err := r.db.QueryContext(ctx, commentSelect+` WHERE c.task_id = $1 ORDER BY c.id`, taskID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    comments := []models.Comment{}
+    for rows.Next() {
+        comment, err := scanComment(rows)
+        if err != nil {
+            return nil, err
+        }
+        comments = append(comments, *comment)
+    }
+    return comments, rows.Err()
+}
+
+// GetComment - комментарий id задачи taskID
+func (r *TaskRepository) GetComment(ctx context.Context, taskID, id int) (*models.Comment, error) {
+    comment, err := scanComment(r.db.QueryRowContext(ctx, commentSelect+` WHERE c.id = $1 AND c.task_id = $2`, id, taskID))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("комментарий %d задачи %d: %w", id, taskID, ErrNotFound)
+    }
+    return comment, err
+}
+
+// CreateComment - добавляет комментарий от имени author. Упомянутым пользователям с привязанным
+// чатом в той же транзакции ставятся уведомления (см. queueMentions)
+func (r *TaskRepository) CreateComment(ctx context.Context, taskID int, author, body string) (*models.Comment, []models.Notification, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer tx.Rollback()
+
+    title, err := commentTaskTitle(ctx, tx, taskID)
+    if err != nil {
+        return nil, nil, err
+    }
+    var id int
+    query := `INSERT INTO task_comments (task_id, author, body) VALUES ($1, $2, $3) RETURNING id`
+    if err := tx.QueryRowContext(ctx, query, taskID, author, body).Scan(&id); err != nil {
+        return nil, nil, err
+    }
+    comment, err := scanComment(tx.QueryRowContext(ctx, commentSelect+` WHERE c.id = $1`, id))
+    if err != nil {
+        return nil, nil, err
+    }
+
+    notifications, err := queueMentions(ctx, tx, title, comment, comment.Mentions)
+    if err != nil {
+        return nil, nil, err
+    }
+    return comment, notifications, tx.Commit()
+}
+
+// UpdateComment - заменяет текст своего комментария; прежний текст сохраняется в истории правок.
+// Уведомления получают только те, кого упомянули впервые
+func (r *TaskRepository) UpdateComment(ctx context.Context, taskID, id int, editor, body string) (*models.Comment, []models.Notification, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, nil, err
+    }
+    defer tx.Rollback()
+
+    title, err := commentTaskTitle(ctx, tx, taskID)
+    if err != nil {
+        return nil, nil, err
+    }
+    before, err := lockComment(ctx, tx, taskID, id, editor)
+    if err != nil {
+        return nil, nil, err
+    }
+    if before.Body == body {
+        return before, nil, nil
+    }
+
+    query := `INSERT INTO task_comment_revisions (comment_id, body, edited_by) VALUES ($1, $2, $3)`
+    if _, err := tx.ExecContext(ctx, query, id, before.Body, editor); err != nil {
+        return nil, nil, err
+    }
+    query = `
+        UPDATE task_comments SET body = $1, edited_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $2
+    `
+    if _, err := tx.ExecContext(ctx, query, body, id); err != nil {
+        return nil, nil, err
+    }
+    comment, err := scanComment(tx.QueryRowContext(ctx, commentSelect+` WHERE c.id = $1`, id))
+    if err != nil {
+        return nil, nil, err
+    }
+
+    known := map[string]bool{}
+    for _, name := range before.Mentions {
+        known[name] = true
+    }
+    var added []string
+    for _, name := range comment.Mentions {
+        if !known[name] {
+            added = append(added, name)
+        }
+    }
+    notifications, err := queueMentions(ctx, tx, title, comment, added)
+    if err != nil {
+        return nil, nil, err
+    }
+    return comment, notifications, tx.Commit()
+}
+
+// DeleteComment - удаляет свой комментарий вместе с историей правок
+func (r *TaskRepository) DeleteComment(ctx context.Context, taskID, id int, user string) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := lockComment(ctx, tx, taskID, id, user); err != nil {
+        return err
+    }
+    if _, err := tx.ExecContext(ctx, `DELETE FROM task_comments WHERE id = $1`, id); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// GetCommentRevisions - прежние версии комментария от первой к последней
+func (r *TaskRepository) GetCommentRevisions(ctx context.Context, taskID, id int) ([]models.CommentRevision, error) {
+    if _, err := r.GetComment(ctx, taskID, id); err != nil {
+        return nil, err
+    }
+    query := `
+        SELECT id, body, edited_by, edited_at FROM task_comment_revisions
+        WHERE comment_id = $1 ORDER BY id
+    `
+    rows, err := r.db.QueryContext(ctx, query, id)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    revisions := []models.CommentRevision{}
+    for rows.Next() {
+        var rev models.CommentRevision
+        if err := rows.Scan(&rev.ID, &rev.Body, &rev.EditedBy, &rev.EditedAt); err != nil {
+            return nil, err
+        }
+        revisions = append(revisions, rev)
+    }
+    return revisions, rows.Err()
+}
+
+// commentTaskTitle - заголовок задачи, которую комментируют; задача в корзине - ErrNotFound
+func commentTaskTitle(ctx context.Context, tx *sql.Tx, taskID int) (string, error) {
+    var title string
+    err := tx.QueryRowContext(ctx, `SELECT title FROM tasks WHERE id = $1 AND deleted_at IS NULL`, taskID).Scan(&title)
+    if err == sql.ErrNoRows {
+        return "", fmt.Errorf("задача с ID %d: %w", taskID, ErrNotFound)
+    }
+    return title, err
+}
+
+// lockComment - блокирует комментарий для правки; править и удалять его может только автор
+func lockComment(ctx context.Context, tx *sql.Tx, taskID, id int, user string) (*models.Comment, error) {
+    comment, err := scanComment(tx.QueryRowContext(ctx, commentSelect+` WHERE c.id = $1 AND c.task_id = $2 FOR UPDATE OF c`, id, taskID))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("комментарий %d задачи %d: %w", id, taskID, ErrNotFound)
+    }
+    if err != nil {
+        return nil, err
+    }
+    if !strings.EqualFold(comment.Author, user) {
+        return nil, fmt.Errorf("комментарий %d принадлежит %q: %w", id, comment.Author, ErrForbidden)
+    }
+    return comment, nil
+}
+
+// queueMentions - ставит в notifications уведомления упомянутым пользователям с привязанным чатом
+// (кроме самого автора); comment.Notified - кому они поставлены. Отправляет их бот после фиксации
+func queueMentions(ctx context.Context, tx *sql.Tx, title string, comment *models.Comment, names []string) ([]models.Notification, error) {
+    var targets []string
+    for _, name := range names {
+        if !strings.EqualFold(name, comment.Author) {
+            targets = append(targets, name)
+        }
+    }
+    if len(targets) == 0 {
+        return nil, nil
+    }
+
+    rows, err := tx.QueryContext(ctx, `SELECT username, chat_id FROM telegram_chats WHERE username = ANY($1) ORDER BY username`, pq.Array(targets))
+    if err != nil {
+        return nil, err
+    }
+    chats := map[string]string{}
+    for rows.Next() {
+        var user, chatID string
+        if err := rows.Scan(&user, &chatID); err != nil {
+            rows.Close()
+            return nil, err
+        }
+        chats[user] = chatID
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    excerpt := []rune(comment.Body)
+    if len(excerpt) > mentionExcerpt {
+        excerpt = append(excerpt[:mentionExcerpt], '…')
+    }
+    message := fmt.Sprintf("%s упомянул(а) вас в комментарии к задаче «%s»:\n\n%s", comment.Author, title, string(excerpt))
+
+    var notifications []models.Notification
+    for _, user := range targets {
+        chatID, ok := chats[user]
+        if !ok {
+            continue
+        }
+        n := models.Notification{TaskID: comment.TaskID, Type: models.NotificationTypeMention, Message: message, ChatID: chatID}
+        query := `
+            INSERT INTO notifications (task_id, type, message, is_sent, chat_id)
+            VALUES ($1, $2, $3, false, $4)
+            RETURNING id, sent_at
+        `
+        if err := tx.QueryRowContext(ctx, query, n.TaskID, n.Type, n.Message, n.ChatID).Scan(&n.ID, &n.SentAt); err != nil {
+            return nil, err
+        }
+        notifications = append(notifications, n)
+        comment.Notified = append(comment.Notified, user)
+    }
+    return notifications, nil
+}
+
+// GetTelegramChats - привязанные чаты пользователей
+func (r *TaskRepository) GetTelegramChats(ctx context.Context) ([]models.TelegramChat, error) {
+    rows, err := r.db.QueryContext(ctx, `SELECT username, chat_id, updated_at FROM telegram_chats ORDER BY username`)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    chats := []models.TelegramChat{}
+    for rows.Next() {
+        var chat models.TelegramChat
+        if err := rows.Scan(&chat.User, &chat.ChatID, &chat.UpdatedAt); err != nil {
+            return nil, err
+        }
+        chats = append(chats, chat)
+    }
+    return chats, rows.Err()
+}
+
+// SetTelegramChat - привязывает чат к пользователю (имя без учета регистра, повторный вызов заменяет чат)
+func (r *TaskRepository) SetTelegramChat(ctx context.Context, chat *models.TelegramChat) error {
+    chat.User = strings.ToLower(chat.User)
+    query := `
+        INSERT INTO telegram_chats (username, chat_id)
+        VALUES ($1, $2)
+        ON CONFLICT (username)
+        DO UPDATE SET chat_id = EXCLUDED.chat_id, updated_at = CURRENT_TIMESTAMP
+        RETURNING updated_at
+    `
+    return r.db.QueryRowContext(ctx, query, chat.User, chat.ChatID).Scan(&chat.UpdatedAt)
+}
+
+// DeleteTelegramChat - отвязывает чат: упоминания пользователя больше не отправляются
+func (r *TaskRepository) DeleteTelegramChat(ctx context.Context, user string) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM telegram_chats WHERE username = $1`, strings.ToLower(user))
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("чат пользователя %q: %w", user, ErrNotFound)
+    }
+    return nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+    "errors"
+    "fmt"
+    "github.com/lib/pq"
+)
+
+var (
+    // ErrNotFound - запись не найдена
+    ErrNotFound = errors.New("не найдено")
+    // ErrConflict - операция нарушает состояние данных (например, удаление непустой колонки)
+    ErrConflict = errors.New("конфликт")
+    // ErrVersionMismatch - запись изменилась после того, как ее прочитал клиент (If-Match)
+    ErrVersionMismatch = errors.New("версия устарела")
+    // ErrBadCursor - курсор страницы поврежден или выдан для другой сортировки
+    ErrBadCursor = errors.New("неверный курсор страницы")
+    // ErrInvalidRelation - связь задач недопустима: задача ссылается на себя, на удаленную задачу или замыкает цикл
+    ErrInvalidRelation = errors.New("недопустимая связь задач")
+    // ErrForbidden - запись принадлежит другому пользователю (например, чужой комментарий)
+    ErrForbidden = errors.New("нет прав")
+)
+
+// mapUniqueViolation - превращает нарушение UNIQUE в ErrConflict с описанием what
+func mapUniqueViolation(err error, what string) error {
+    var pqErr *pq.Error
+    if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+        return fmt.Errorf("%s уже существует: %w", what, ErrConflict)
+    }
+    return err
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "kanban-calendar/internal/models"
+
+    "github.com/lib/pq"
+)
+
+// GetExternalTasks - задачи источника импорта по UID события (вместе с архивными и удаленными в корзину).
+// Задачи, импортированные до появления источников, подхватываются по одному UID
+func (r *TaskRepository) GetExternalTasks(ctx context.Context, source string) (map[string]*models.Task, error) {
+    query := taskSelect + `
+        WHERE t.external_uid <> ''
+          AND (t.external_source = $1 OR t.external_source IS NULL)
+        ORDER BY (t.external_source IS NULL), t.id
+    `
+    tasks, err := r.queryTasks(ctx, query, source)
+    if err != nil {
+        return nil, err
+    }
+
+    byUID := make(map[string]*models.Task, len(tasks))
+    for i := range tasks {
+        // Первой идет задача самого источника, затем самая старая из ранее импортированных
+        if _, ok := byUID[tasks[i].ExternalUID]; !ok {
+            byUID[tasks[i].ExternalUID] = &tasks[i]
+        }
+    }
+    return byUID, nil
+}
+
+// UpdateExternalTask - переписывает поля, пришедшие из календаря, и достает задачу из архива.
+// Доска, колонка, теги и исполнитель остаются такими, какими их сделали в канбане;
+// приоритет берется из календаря, только если там задан PRIORITY
+func (r *TaskRepository) UpdateExternalTask(ctx context.Context, task *models.Task) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    before, err := loadTaskTx(ctx, tx, task.ID)
+    if err != nil {
+        return err
+    }
+    query := `
+        UPDATE tasks
+        SET title = $1, description = $2, deadline = $3, start_date = $4, end_date = $5,
+            all_day = $6, rrule = NULLIF($7, ''), exdates = $8,
+            external_source = NULLIF($9, ''), external_sequence = $10, external_modified = $11,
+            priority = COALESCE(NULLIF($13, ''), priority), external_priority = NULLIF($14, 0), external_percent = $15,
+            archived_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $12
+        RETURNING updated_at, version
+    `
+    err = tx.QueryRowContext(ctx, query,
+        task.Title,
+        task.Description,
+        task.Deadline,
+        task.StartDate,
+        task.EndDate,
+        task.AllDay,
+        task.RRule,
+        pq.Array(encodeExDates(task.ExDates)),
+        task.ExternalSource,
+        task.ExternalSequence,
+        task.ExternalModified,
+        task.ID,
+        task.Priority,
+        task.ExternalPriority,
+        task.ExternalPercent,
+    ).Scan(&task.UpdatedAt, &task.Version)
+    if err != nil {
+        return mapUniqueViolation(err, fmt.Sprintf("событие %s в источнике %s", task.ExternalUID, task.ExternalSource))
+    }
+    task.ArchivedAt = nil
+
+    after, err := loadTaskTx(ctx, tx, task.ID)
+    if err != nil {
+        return err
+    }
+    if err := recordEvent(ctx, tx, models.EventUpdate, before, after); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// ArchiveTasks - убирает задачи в архив (уже архивные не трогает), возвращает число затронутых
+func (r *TaskRepository) ArchiveTasks(ctx context.Context, ids []int) (int64, error) {
+    if len(ids) == 0 {
+        return 0, nil
+    }
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback()
+
+    query := `
+        UPDATE tasks SET archived_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = ANY($1) AND archived_at IS NULL
+        RETURNING id
+    `
+    rows, err := tx.QueryContext(ctx, query, pq.Array(ids))
+    if err != nil {
+        return 0, err
+    }
+    var archived []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return 0, err
+        }
+        archived = append(archived, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, err
+    }
+
+    for _, id := range archived {
+        if err := recordArchived(ctx, tx, id); err != nil {
+            return 0, err
+        }
+    }
+    return int64(len(archived)), tx.Commit()
+}
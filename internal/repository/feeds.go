@@ -0,0 +1,88 @@
+package repository
+
+import (
+    "context"
+    "crypto/rand"
+    "database/sql"
+    "encoding/hex"
+    "fmt"
+    "kanban-calendar/internal/models"
+)
+
+const feedSelect = `
+    SELECT id, token, name, assignee, created_at, last_accessed_at
+    FROM calendar_feeds
+`
+
+func scanFeed(row rowScanner) (*models.CalendarFeed, error) {
+    feed := &models.CalendarFeed{}
+    var lastAccessed sql.NullTime
+    if err := row.Scan(&feed.ID, &feed.Token, &feed.Name, &feed.Assignee, &feed.CreatedAt, &lastAccessed); err != nil {
+        return nil, err
+    }
+    if lastAccessed.Valid {
+        feed.LastAccessedAt = &lastAccessed.Time
+    }
+    return feed, nil
+}
+
+// GetFeeds - ленты (assignee = "" - все)
+func (r *TaskRepository) GetFeeds(ctx context.Context, assignee string) ([]models.CalendarFeed, error) {
+    rows, err := r.db.QueryContext(ctx, feedSelect+` WHERE $1 = '' OR assignee = $1 ORDER BY id`, assignee)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    feeds := []models.CalendarFeed{}
+    for rows.Next() {
+        feed, err := scanFeed(rows)
+        if err != nil {
+            return nil, err
+        }
+        feeds = append(feeds, *feed)
+    }
+    return feeds, rows.Err()
+}
+
+// GetFeedByToken - лента по токену; отмечает время обращения
+func (r *TaskRepository) GetFeedByToken(ctx context.Context, token string) (*models.CalendarFeed, error) {
+    query := `
+        UPDATE calendar_feeds SET last_accessed_at = CURRENT_TIMESTAMP
+        WHERE token = $1
+        RETURNING id, token, name, assignee, created_at, last_accessed_at
+    `
+    feed, err := scanFeed(r.db.QueryRowContext(ctx, query, token))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("лента: %w", ErrNotFound)
+    }
+    return feed, err
+}
+
+// CreateFeed - создает ленту со случайным токеном
+func (r *TaskRepository) CreateFeed(ctx context.Context, feed *models.CalendarFeed) error {
+    buf := make([]byte, 24)
+    if _, err := rand.Read(buf); err != nil {
+        return err
+    }
+    feed.Token = hex.EncodeToString(buf)
+
+    query := `
+        INSERT INTO calendar_feeds (token, name, assignee)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `
+    return r.db.QueryRowContext(ctx, query, feed.Token, feed.Name, feed.Assignee).Scan(&feed.ID, &feed.CreatedAt)
+}
+
+// DeleteFeed - удаляет ленту (старый URL перестает работать)
+func (r *TaskRepository) DeleteFeed(ctx context.Context, id int) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feeds WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("лента с ID %d: %w", id, ErrNotFound)
+    }
+    return nil
+}
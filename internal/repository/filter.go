@@ -0,0 +1,124 @@
+package repository
+
+import (
+    "fmt"
+    "strings"
+    "time"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// TaskFilter - условия отбора задач для списков и календаря (пустые поля не фильтруют).
+// Списки значений (статусы, исполнители...) - любое из значений
+type TaskFilter struct {
+    BoardID    int
+    Statuses   []models.TaskStatus
+    Tags       []string
+    Assignees  []string
+    Priorities []string
+    // Text - подстрока в заголовке или описании (без учета регистра)
+    Text string
+    // From, To - задачи, пересекающие интервал (повторяющиеся - начавшиеся до To)
+    From *time.Time
+    To   *time.Time
+    // Диапазоны дат; у открытых задач без дедлайна DeadlineFrom/DeadlineTo не выполняются
+    DeadlineFrom *time.Time
+    DeadlineTo   *time.Time
+    CreatedFrom  *time.Time
+    CreatedTo    *time.Time
+    UpdatedFrom  *time.Time
+    UpdatedTo    *time.Time
+    // Query - запрос языка представлений (assignee:me status:!done), условия добавляются к остальным
+    Query *TaskQuery
+    // Archived - включать архивные задачи (по умолчанию скрыты)
+    Archived bool
+    // Deleted - только задачи в корзине (по умолчанию они скрыты всегда)
+    Deleted bool
+}
+
+// conditions - SQL-условия для taskSelect (алиасы t и c), аргументы добавляются в args
+func (f TaskFilter) conditions(args *[]interface{}) []string {
+    var conds []string
+    if f.Deleted {
+        conds = append(conds, "t.deleted_at IS NOT NULL")
+    } else {
+        conds = append(conds, "t.deleted_at IS NULL")
+        if !f.Archived && (f.Query == nil || !f.Query.archived) {
+            conds = append(conds, "t.archived_at IS NULL")
+        }
+    }
+    arg := func(v interface{}) string {
+        *args = append(*args, v)
+        return fmt.Sprintf("$%d", len(*args))
+    }
+
+    if f.BoardID != 0 {
+        conds = append(conds, "t.board_id = "+arg(f.BoardID))
+    }
+    if len(f.Statuses) > 0 {
+        statuses := make([]string, len(f.Statuses))
+        for i, s := range f.Statuses {
+            statuses[i] = string(s)
+        }
+        conds = append(conds, "c.slug = ANY("+arg(pq.Array(statuses))+")")
+    }
+    if len(f.Assignees) > 0 {
+        conds = append(conds, "t.assignee = ANY("+arg(pq.Array(f.Assignees))+")")
+    }
+    if len(f.Priorities) > 0 {
+        conds = append(conds, "t.priority = ANY("+arg(pq.Array(f.Priorities))+")")
+    }
+    if text := strings.TrimSpace(f.Text); text != "" {
+        pattern := arg("%" + likeEscaper.Replace(text) + "%")
+        conds = append(conds, "(t.title ILIKE "+pattern+" OR t.description ILIKE "+pattern+")")
+    }
+    if f.To != nil {
+        conds = append(conds, "COALESCE(t.start_date, t.deadline, t.created_at) <= "+arg(*f.To))
+    }
+    if f.From != nil {
+        conds = append(conds, "(t.rrule IS NOT NULL OR COALESCE(t.end_date, t.deadline, t.start_date, t.created_at) >= "+arg(*f.From)+")")
+    }
+    for _, r := range []struct {
+        column string
+        from   *time.Time
+        to     *time.Time
+    }{
+        {"t.deadline", f.DeadlineFrom, f.DeadlineTo},
+        {"t.created_at", f.CreatedFrom, f.CreatedTo},
+        {"t.updated_at", f.UpdatedFrom, f.UpdatedTo},
+    } {
+        if r.from != nil {
+            conds = append(conds, r.column+" >= "+arg(*r.from))
+        }
+        if r.to != nil {
+            conds = append(conds, r.column+" <= "+arg(*r.to))
+        }
+    }
+    var tags []string
+    for _, tag := range f.Tags {
+        if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    if len(tags) > 0 {
+        conds = append(conds, `EXISTS (
+            SELECT 1 FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id
+            WHERE tt.task_id = t.id AND LOWER(tg.name) = ANY(`+arg(pq.Array(tags))+`))`)
+    }
+    if f.Query != nil {
+        conds = append(conds, f.Query.conditions(args)...)
+    }
+
+    return conds
+}
+
+// likeEscaper - экранирует спецсимволы LIKE в пользовательском тексте
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// whereClause - собирает условия в WHERE (или пустую строку)
+func whereClause(conds []string) string {
+    if len(conds) == 0 {
+        return ""
+    }
+    return " WHERE " + strings.Join(conds, " AND ")
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// linkGraphLockID - ключ advisory-блокировки графа связей: две одновременные связи не должны вместе замкнуть цикл
+const linkGraphLockID = 7214509032
+
+// blockedCondition - у задачи t есть незавершенная блокирующая задача (архивные и удаленные не блокируют)
+const blockedCondition = `EXISTS (
+               SELECT 1 FROM task_links bl
+               JOIN tasks b ON b.id = bl.source_id
+               JOIN board_columns bc ON bc.id = b.column_id
+               WHERE bl.target_id = t.id AND bl.type = 'blocks' AND NOT bc.is_done
+                 AND b.archived_at IS NULL AND b.deleted_at IS NULL)`
+
+// NormalizeLink - связь со стороны задачи taskID в хранимом виде (source, target, type);
+// ok = false - неизвестный тип
+func NormalizeLink(taskID, otherID int, linkType string) (source, target int, stored string, ok bool) {
+    switch linkType {
+    case models.LinkBlocks, models.LinkRelatesTo, models.LinkDuplicates:
+        return taskID, otherID, linkType, true
+    case models.LinkBlockedBy:
+        return otherID, taskID, models.LinkBlocks, true
+    case models.LinkDuplicatedBy:
+        return otherID, taskID, models.LinkDuplicates, true
+    }
+    return 0, 0, "", false
+}
+
+// CreateLink - связывает задачи. Связь с собой, с задачей в корзине и цикл в blocks или duplicates -
+// ErrInvalidRelation, повтор (для relates_to - в любом направлении) - ErrConflict
+func (r *TaskRepository) CreateLink(ctx context.Context, link *models.TaskLink) error {
+    if link.SourceID == link.TargetID {
+        return fmt.Errorf("задача %d не может ссылаться на себя: %w", link.SourceID, ErrInvalidRelation)
+    }
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, linkGraphLockID); err != nil {
+        return err
+    }
+    source, err := loadTaskTx(ctx, tx, link.SourceID)
+    if err != nil {
+        return err
+    }
+    target, err := loadTaskTx(ctx, tx, link.TargetID)
+    if err != nil {
+        return err
+    }
+    for _, task := range []*models.Task{source, target} {
+        if task.DeletedAt != nil {
+            return fmt.Errorf("задача %d в корзине: %w", task.ID, ErrInvalidRelation)
+        }
+    }
+
+    var exists bool
+    query := `
+        SELECT EXISTS (
+            SELECT 1 FROM task_links
+            WHERE type = $3 AND ((source_id = $1 AND target_id = $2) OR ($3 = 'relates_to' AND source_id = $2 AND target_id = $1))
+        )
+    `
+    if err := tx.QueryRowContext(ctx, query, link.SourceID, link.TargetID, link.Type).Scan(&exists); err != nil {
+        return err
+    }
+    if exists {
+        return fmt.Errorf("связь %s между задачами %d и %d уже существует: %w", link.Type, link.SourceID, link.TargetID, ErrConflict)
+    }
+
+    // Направленная связь замыкает цикл, если source уже достижима из target по связям того же типа
+    if link.Type != models.LinkRelatesTo {
+        var cycle bool
+        query := `
+            WITH RECURSIVE reach(id) AS (
+                SELECT $1::int
+                UNION
+                SELECT l.target_id FROM task_links l JOIN reach ON l.source_id = reach.id WHERE l.type = $3
+            )
+            SELECT EXISTS (SELECT 1 FROM reach WHERE id = $2)
+        `
+        if err := tx.QueryRowContext(ctx, query, link.TargetID, link.SourceID, link.Type).Scan(&cycle); err != nil {
+            return err
+        }
+        if cycle {
+            return fmt.Errorf("связь %d %s %d замкнет цикл: %w", link.SourceID, link.Type, link.TargetID, ErrInvalidRelation)
+        }
+    }
+
+    link.CreatedBy = actorFrom(ctx)
+    query = `
+        INSERT INTO task_links (source_id, target_id, type, created_by)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at
+    `
+    err = tx.QueryRowContext(ctx, query, link.SourceID, link.TargetID, link.Type, link.CreatedBy).Scan(&link.ID, &link.CreatedAt)
+    if err != nil {
+        return err
+    }
+    if err := recordLinkChange(ctx, tx, link, source, target, false); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// DeleteLink - удаляет связь id, если в ней участвует задача taskID
+func (r *TaskRepository) DeleteLink(ctx context.Context, taskID, id int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    link := &models.TaskLink{}
+    query := `
+        DELETE FROM task_links WHERE id = $1 AND (source_id = $2 OR target_id = $2)
+        RETURNING id, source_id, target_id, type, created_by, created_at
+    `
+    err = tx.QueryRowContext(ctx, query, id, taskID).
+        Scan(&link.ID, &link.SourceID, &link.TargetID, &link.Type, &link.CreatedBy, &link.CreatedAt)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("связь %d задачи %d: %w", id, taskID, ErrNotFound)
+    }
+    if err != nil {
+        return err
+    }
+
+    source, err := loadTaskTx(ctx, tx, link.SourceID)
+    if err != nil {
+        return err
+    }
+    target, err := loadTaskTx(ctx, tx, link.TargetID)
+    if err != nil {
+        return err
+    }
+    if err := recordLinkChange(ctx, tx, link, source, target, true); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// recordLinkChange - связь в истории обеих задач, каждой - со своей стороны
+func recordLinkChange(ctx context.Context, tx *sql.Tx, link *models.TaskLink, source, target *models.Task, removed bool) error {
+    reverse := map[string]string{
+        models.LinkBlocks:     models.LinkBlockedBy,
+        models.LinkRelatesTo:  models.LinkRelatesTo,
+        models.LinkDuplicates: models.LinkDuplicatedBy,
+    }
+    sides := []struct {
+        task    *models.Task
+        kind    string
+        otherID int
+    }{
+        {source, link.Type, link.TargetID},
+        {target, reverse[link.Type], link.SourceID},
+    }
+    for _, side := range sides {
+        value := map[string]interface{}{"id": link.ID, "type": side.kind, "task_id": side.otherID}
+        change := models.FieldChange{After: value}
+        if removed {
+            change = models.FieldChange{Before: value}
+        }
+        if err := insertEvent(ctx, tx, models.EventUpdate, side.task, map[string]models.FieldChange{"links": change}); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// GetTaskLinks - связи задачи по смыслу; задачи в корзине не показываются
+func (r *TaskRepository) GetTaskLinks(ctx context.Context, taskID int) (*models.TaskLinks, error) {
+    query := `
+        SELECT l.id, l.type, l.source_id = $1, o.id, o.title, c.slug, c.is_done, o.deadline, o.end_date
+        FROM task_links l
+        JOIN tasks o ON o.id = CASE WHEN l.source_id = $1 THEN l.target_id ELSE l.source_id END
+        JOIN board_columns c ON c.id = o.column_id
+        WHERE (l.source_id = $1 OR l.target_id = $1) AND o.deleted_at IS NULL
+        ORDER BY l.id
+    `
+    rows, err := r.db.QueryContext(ctx, query, taskID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    links := &models.TaskLinks{
+        Blockers:     []models.TaskRef{},
+        Dependents:   []models.TaskRef{},
+        Related:      []models.TaskRef{},
+        Duplicates:   []models.TaskRef{},
+        DuplicatedBy: []models.TaskRef{},
+    }
+    for rows.Next() {
+        var ref models.TaskRef
+        var linkType string
+        var outgoing bool
+        var deadline, endDate sql.NullTime
+        err := rows.Scan(&ref.LinkID, &linkType, &outgoing, &ref.ID, &ref.Title, &ref.Status, &ref.IsDone, &deadline, &endDate)
+        if err != nil {
+            return nil, err
+        }
+        if deadline.Valid {
+            ref.Deadline = &deadline.Time
+        }
+        if endDate.Valid {
+            ref.EndDate = &endDate.Time
+        }
+
+        switch {
+        case linkType == models.LinkBlocks && outgoing:
+            links.Dependents = append(links.Dependents, ref)
+        case linkType == models.LinkBlocks:
+            links.Blockers = append(links.Blockers, ref)
+        case linkType == models.LinkDuplicates && outgoing:
+            links.Duplicates = append(links.Duplicates, ref)
+        case linkType == models.LinkDuplicates:
+            links.DuplicatedBy = append(links.DuplicatedBy, ref)
+        default:
+            links.Related = append(links.Related, ref)
+        }
+    }
+    return links, rows.Err()
+}
+
+// GetDependencies - связи blocks, у которых оба конца среди задач ids (ребра диаграммы Ганта)
+func (r *TaskRepository) GetDependencies(ctx context.Context, ids []int) ([]models.Dependency, error) {
+    deps := []models.Dependency{}
+    if len(ids) == 0 {
+        return deps, nil
+    }
+    query := `
+        SELECT id, source_id, target_id, type FROM task_links
+        WHERE type = 'blocks' AND source_id = ANY($1) AND target_id = ANY($1)
+        ORDER BY id
+    `
+    rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var dep models.Dependency
+        if err := rows.Scan(&dep.LinkID, &dep.From, &dep.To, &dep.Type); err != nil {
+            return nil, err
+        }
+        deps = append(deps, dep)
+    }
+    return deps, rows.Err()
+}
+
+// startsWork - перенос из колонки fromID в колонку toID начинает работу над задачей: новая колонка -
+// рабочая (не первая на доске и не «выполнено»), а прежняя - нет. Колонки досок задает пользователь,
+// поэтому «начата» определяется по устройству доски, а не по slug
+func startsWork(ctx context.Context, tx *sql.Tx, fromID, toID int) (bool, error) {
+    query := `
+        SELECT COALESCE(bool_or(c.id = $2 AND working), false), COALESCE(bool_or(c.id = $1 AND working), false)
+        FROM (
+            SELECT c.id, NOT c.is_done AND c.position > (SELECT MIN(f.position) FROM board_columns f WHERE f.board_id = c.board_id) AS working
+            FROM board_columns c
+            WHERE c.id IN ($1, $2)
+        ) c
+    `
+    var to, from bool
+    if err := tx.QueryRowContext(ctx, query, fromID, toID).Scan(&to, &from); err != nil {
+        return false, err
+    }
+    return to && !from, nil
+}
+
+// checkBlocked - можно ли начать задачу taskID (перенести в рабочую колонку, см. startsWork).
+// Незавершенные блокирующие задачи дают BlockedError, а с force возвращаются как предупреждение
+func checkBlocked(ctx context.Context, tx *sql.Tx, taskID int, force bool) ([]models.TaskRef, error) {
+    query := `
+        SELECT l.id, b.id, b.title, c.slug, c.is_done, b.deadline, b.end_date
+        FROM task_links l
+        JOIN tasks b ON b.id = l.source_id
+        JOIN board_columns c ON c.id = b.column_id
+        WHERE l.target_id = $1 AND l.type = 'blocks' AND NOT c.is_done
+          AND b.archived_at IS NULL AND b.deleted_at IS NULL
+        ORDER BY l.id
+    `
+    rows, err := tx.QueryContext(ctx, query, taskID)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var blockers []models.TaskRef
+    for rows.Next() {
+        var ref models.TaskRef
+        var deadline, endDate sql.NullTime
+        if err := rows.Scan(&ref.LinkID, &ref.ID, &ref.Title, &ref.Status, &ref.IsDone, &deadline, &endDate); err != nil {
+            return nil, err
+        }
+        if deadline.Valid {
+            ref.Deadline = &deadline.Time
+        }
+        if endDate.Valid {
+            ref.EndDate = &endDate.Time
+        }
+        blockers = append(blockers, ref)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(blockers) > 0 && !force {
+        return nil, &models.BlockedError{TaskID: taskID, Blockers: blockers}
+    }
+    return blockers, nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/rank"
+)
+
+// maxRankLength - после такой длины ранга колонка перебалансируется
+const maxRankLength = 64
+
+// lockColumn - блокирует строку колонки, чтобы параллельные вставки и переносы шли по очереди
+func lockColumn(ctx context.Context, tx *sql.Tx, columnID int) error {
+    var id int
+    err := tx.QueryRowContext(ctx, `SELECT id FROM board_columns WHERE id = $1 FOR UPDATE`, columnID).Scan(&id)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("колонка с ID %d: %w", columnID, ErrNotFound)
+    }
+    return err
+}
+
+// lockColumnTail - блокирует колонку и возвращает ранг для новой карточки в ее конце.
+// Каждая вставка в конец удлиняет ранг, поэтому разросшаяся колонка перебалансируется так же, как в MoveTask
+func lockColumnTail(ctx context.Context, tx *sql.Tx, columnID, excludeTaskID int) (string, error) {
+    if err := lockColumn(ctx, tx, columnID); err != nil {
+        return "", err
+    }
+
+    position, err := tailRank(ctx, tx, columnID, excludeTaskID)
+    if err != nil || len(position) > maxRankLength {
+        if err := rebalanceColumn(ctx, tx, columnID, excludeTaskID); err != nil {
+            return "", err
+        }
+        return tailRank(ctx, tx, columnID, excludeTaskID)
+    }
+    return position, nil
+}
+
+// tailRank - ранг после последней карточки колонки
+func tailRank(ctx context.Context, tx *sql.Tx, columnID, excludeTaskID int) (string, error) {
+    var last string
+    query := `SELECT COALESCE(MAX(position), '') FROM tasks WHERE column_id = $1 AND id <> $2`
+    if err := tx.QueryRowContext(ctx, query, columnID, excludeTaskID).Scan(&last); err != nil {
+        return "", err
+    }
+    return rank.Between(last, "")
+}
+
+// checkWIPLimit - проверяет лимит колонки, в которую попадает задача (колонка уже заблокирована).
+// В режиме reject возвращает *models.WIPLimitError, в режиме warn - нарушение без ошибки
+func checkWIPLimit(ctx context.Context, tx *sql.Tx, columnID, taskID int) (*models.WIPViolation, error) {
+    var name, mode string
+    var limit, count int
+    query := `
+        SELECT c.name, COALESCE(c.wip_limit, 0), c.wip_mode,
+               (SELECT COUNT(*) FROM tasks t WHERE t.column_id = c.id AND t.id <> $2 AND t.archived_at IS NULL AND t.deleted_at IS NULL)
+        FROM board_columns c
+        WHERE c.id = $1
+    `
+    if err := tx.QueryRowContext(ctx, query, columnID, taskID).Scan(&name, &limit, &mode, &count); err != nil {
+        return nil, err
+    }
+
+    count++ // с учетом самой задачи
+    if limit == 0 || count <= limit {
+        return nil, nil
+    }
+
+    violation := models.WIPViolation{
+        ColumnID: columnID,
+        Column:   name,
+        Limit:    limit,
+        Count:    count,
+        OverBy:   count - limit,
+        Mode:     mode,
+    }
+    if mode == models.WIPModeWarn {
+        return &violation, nil
+    }
+    return nil, &models.WIPLimitError{WIPViolation: violation}
+}
+
+// MoveTask - переносит задачу в колонку col между соседями afterID и beforeID (0 - не задан).
+// Меняется только ранг самой задачи; колонка перебалансируется, лишь когда ранги исчерпаны.
+// Заблокированную задачу в рабочую колонку (см. startsWork) переносит только force
+func (r *TaskRepository) MoveTask(ctx context.Context, taskID int, col *models.BoardColumn, afterID, beforeID int, force bool) (*models.Task, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    if err := lockColumn(ctx, tx, col.ID); err != nil {
+        return nil, err
+    }
+
+    var currentColumn int
+    var wasDone bool
+    query := `
+        SELECT t.column_id, c.is_done
+        FROM tasks t JOIN board_columns c ON c.id = t.column_id
+        WHERE t.id = $1 AND t.deleted_at IS NULL
+        FOR UPDATE OF t
+    `
+    err = tx.QueryRowContext(ctx, query, taskID).Scan(&currentColumn, &wasDone)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("задача с ID %d: %w", taskID, ErrNotFound)
+        }
+        return nil, err
+    }
+    before, err := loadTaskTx(ctx, tx, taskID)
+    if err != nil {
+        return nil, err
+    }
+    
+    var warning *models.WIPViolation
+    var blockers []models.TaskRef
+    if currentColumn != col.ID {
+        if warning, err = checkWIPLimit(ctx, tx, col.ID, taskID); err != nil {
+            return nil, err
+        }
+        starts, err := startsWork(ctx, tx, currentColumn, col.ID)
+        if err != nil {
+            return nil, err
+        }
+        if starts {
+            if blockers, err = checkBlocked(ctx, tx, taskID, force); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    position, err := neighbourRank(ctx, tx, taskID, col.ID, afterID, beforeID)
+    if err != nil || len(position) > maxRankLength {
+        // Ранги соседей совпали или разрослись - переписываем колонку и пробуем еще раз
+        if err := rebalanceColumn(ctx, tx, col.ID, taskID); err != nil {
+            return nil, err
+        }
+        position, err = neighbourRank(ctx, tx, taskID, col.ID, afterID, beforeID)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    query = `
+        UPDATE tasks
+        SET board_id = $1, column_id = $2, status = $3, position = $4,
+            version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $5
+    `
+    if _, err := tx.ExecContext(ctx, query, col.BoardID, col.ID, col.Slug, position, taskID); err != nil {
+        return nil, err
+    }
+    after, err := loadTaskTx(ctx, tx, taskID)
+    if err != nil {
+        return nil, err
+    }
+    if err := recordEvent(ctx, tx, models.EventMove, before, after); err != nil {
+        return nil, err
+    }
+    if err := spawnIfCompleted(ctx, tx, r.zone, taskID, wasDone); err != nil {
+        return nil, err
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, err
+    }
+
+    task, err := r.GetTaskByID(ctx, taskID)
+    if err != nil {
+        return nil, err
+    }
+    task.WIPWarning = warning
+    task.BlockedWarning = blockers
+    return task, nil
+}
+
+// neighbourRank - ранг между соседями в колонке (сама задача из расчета исключается)
+func neighbourRank(ctx context.Context, tx *sql.Tx, taskID, columnID, afterID, beforeID int) (string, error) {
+    var prev, next string
+    var err error
+
+    switch {
+    case afterID != 0 && beforeID != 0:
+        if prev, err = taskRank(ctx, tx, afterID, columnID); err != nil {
+            return "", err
+        }
+        if next, err = taskRank(ctx, tx, beforeID, columnID); err != nil {
+            return "", err
+        }
+    case afterID != 0:
+        if prev, err = taskRank(ctx, tx, afterID, columnID); err != nil {
+            return "", err
+        }
+        query := `SELECT COALESCE(MIN(position), '') FROM tasks WHERE column_id = $1 AND id <> $2 AND position > $3`
+        if err = tx.QueryRowContext(ctx, query, columnID, taskID, prev).Scan(&next); err != nil {
+            return "", err
+        }
+    case beforeID != 0:
+        if next, err = taskRank(ctx, tx, beforeID, columnID); err != nil {
+            return "", err
+        }
+        query := `SELECT COALESCE(MAX(position), '') FROM tasks WHERE column_id = $1 AND id <> $2 AND position < $3`
+        if err = tx.QueryRowContext(ctx, query, columnID, taskID, next).Scan(&prev); err != nil {
+            return "", err
+        }
+    default:
+        query := `SELECT COALESCE(MAX(position), '') FROM tasks WHERE column_id = $1 AND id <> $2`
+        if err = tx.QueryRowContext(ctx, query, columnID, taskID).Scan(&prev); err != nil {
+            return "", err
+        }
+    }
+
+    return rank.Between(prev, next)
+}
+
+// taskRank - ранг соседней задачи, которая обязана лежать в той же колонке
+func taskRank(ctx context.Context, tx *sql.Tx, id, columnID int) (string, error) {
+    var position string
+    var actualColumn int
+    err := tx.QueryRowContext(ctx, `SELECT position, column_id FROM tasks WHERE id = $1`, id).Scan(&position, &actualColumn)
+    if err == sql.ErrNoRows {
+        return "", fmt.Errorf("соседняя задача %d: %w", id, ErrNotFound)
+    }
+    if err != nil {
+        return "", err
+    }
+    if actualColumn != columnID {
+        return "", fmt.Errorf("соседняя задача %d находится в другой колонке: %w", id, ErrConflict)
+    }
+    return position, nil
+}
+
+// rebalanceColumn - заново раздает ранги карточкам колонки, сохраняя их порядок
+func rebalanceColumn(ctx context.Context, tx *sql.Tx, columnID, excludeTaskID int) error {
+    rows, err := tx.QueryContext(ctx,
+        `SELECT id FROM tasks WHERE column_id = $1 AND id <> $2 ORDER BY position, id FOR UPDATE`,
+        columnID, excludeTaskID,
+    )
+    if err != nil {
+        return err
+    }
+
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            rows.Close()
+            return err
+        }
+        ids = append(ids, id)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return err
+    }
+
+    for i, id := range ids {
+        if _, err := tx.ExecContext(ctx, `UPDATE tasks SET position = $1 WHERE id = $2`, rank.Initial(i+1), id); err != nil {
+            return err
+        }
+    }
+    return nil
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/base64"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "kanban-calendar/internal/models"
+)
+
+// Размер страницы списка задач
+const (
+    DefaultPageLimit = 100
+    MaxPageLimit     = 500
+)
+
+// TaskSort - ключ сортировки списка задач: поле и направление
+type TaskSort struct {
+    Field string
+    Desc  bool
+}
+
+// TaskPage - сортировка и страница списка задач. Cursor - next_cursor предыдущей страницы
+type TaskPage struct {
+    Sort   []TaskSort
+    Limit  int
+    Cursor string
+}
+
+// sortKey - SQL-выражение ключа сортировки и тип, в который приводится значение из курсора
+type sortKey struct {
+    expr     string
+    cast     string
+    nullable bool
+}
+
+// sortFields - поля, доступные в sort=. Пустые даты всегда идут последними
+var sortFields = map[string][]sortKey{
+    "position":   {{"t.board_id", "int", false}, {"c.position", "int", false}, {"t.position", "text", false}},
+    "id":         {{"t.id", "int", false}},
+    "title":      {{"t.title", "text", false}},
+    "status":     {{"c.position", "int", false}},
+    "priority":   {{"CASE t.priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END", "int", false}},
+    "assignee":   {{"COALESCE(t.assignee, '')", "text", false}},
+    "deadline":   {{"t.deadline", "timestamp", true}},
+    "start_date": {{"t.start_date", "timestamp", true}},
+    "end_date":   {{"t.end_date", "timestamp", true}},
+    "created_at": {{"t.created_at", "timestamp", false}},
+    "updated_at": {{"t.updated_at", "timestamp", false}},
+}
+
+// SortFields - допустимые поля сортировки (для сообщений об ошибках)
+func SortFields() []string {
+    fields := make([]string, 0, len(sortFields))
+    for field := range sortFields {
+        fields = append(fields, field)
+    }
+    sort.Strings(fields)
+    return fields
+}
+
+// ParseTaskSort - разбирает sort=-deadline,title: минус - по убыванию. Пустая строка - порядок доски
+func ParseTaskSort(spec string) ([]TaskSort, error) {
+    var sorts []TaskSort
+    seen := map[string]bool{}
+    for _, part := range strings.Split(spec, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        s := TaskSort{Field: strings.TrimLeft(part, "+-"), Desc: strings.HasPrefix(part, "-")}
+        if _, ok := sortFields[s.Field]; !ok {
+            return nil, fmt.Errorf("нельзя сортировать по %q, доступно: %s", s.Field, strings.Join(SortFields(), ", "))
+        }
+        if seen[s.Field] {
+            continue
+        }
+        seen[s.Field] = true
+        sorts = append(sorts, s)
+    }
+    return sorts, nil
+}
+
+// sortSpec - каноническая запись сортировки; курсор действителен только для нее
+func sortSpec(sorts []TaskSort) string {
+    parts := make([]string, len(sorts))
+    for i, s := range sorts {
+        parts[i] = s.Field
+        if s.Desc {
+            parts[i] = "-" + s.Field
+        }
+    }
+    return strings.Join(parts, ",")
+}
+
+// orderKey - ключ ORDER BY с направлением
+type orderKey struct {
+    expr string
+    cast string
+    desc bool
+}
+
+// orderKeys - ключи сортировки; t.id в конце делает порядок однозначным, без чего keyset-страницы теряют строки
+func orderKeys(sorts []TaskSort) []orderKey {
+    if len(sorts) == 0 {
+        sorts = []TaskSort{{Field: "position"}}
+    }
+    var keys []orderKey
+    hasID := false
+    for _, s := range sorts {
+        hasID = hasID || s.Field == "id"
+        for _, k := range sortFields[s.Field] {
+            expr := k.expr
+            if k.nullable {
+                // NULL заменяется на край диапазона так, чтобы пустые даты шли последними в обоих направлениях
+                edge := "'infinity'::timestamp"
+                if s.Desc {
+                    edge = "'-infinity'::timestamp"
+                }
+                expr = "COALESCE(" + expr + ", " + edge + ")"
+            }
+            keys = append(keys, orderKey{expr, k.cast, s.Desc})
+        }
+    }
+    if !hasID {
+        keys = append(keys, orderKey{"t.id", "int", false})
+    }
+    return keys
+}
+
+// pageCursor - содержимое next_cursor: сортировка и значения ключей последней строки
+type pageCursor struct {
+    Sort   string   `json:"s"`
+    Values []string `json:"v"`
+}
+
+func encodeCursor(cur pageCursor) (string, error) {
+    raw, err := json.Marshal(cur)
+    if err != nil {
+        return "", err
+    }
+    return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+    var cur pageCursor
+    raw, err := base64.RawURLEncoding.DecodeString(s)
+    if err != nil {
+        return cur, ErrBadCursor
+    }
+    if err := json.Unmarshal(raw, &cur); err != nil {
+        return cur, ErrBadCursor
+    }
+    return cur, nil
+}
+
+// keysetCondition - строки строго после курсора в порядке keys:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... (для убывающих ключей - "<")
+func keysetCondition(keys []orderKey, values []string, args *[]interface{}) string {
+    params := make([]string, len(keys))
+    for i, k := range keys {
+        *args = append(*args, values[i])
+        params[i] = fmt.Sprintf("$%d::%s", len(*args), k.cast)
+    }
+    var ors []string
+    for i, k := range keys {
+        var ands []string
+        for j := 0; j < i; j++ {
+            ands = append(ands, keys[j].expr+" = "+params[j])
+        }
+        op := " > "
+        if k.desc {
+            op = " < "
+        }
+        ands = append(ands, k.expr+op+params[i])
+        ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+    }
+    return "(" + strings.Join(ors, " OR ") + ")"
+}
+
+// ListTasksPage - страница задач по фильтру в порядке page.Sort. next - курсор следующей страницы,
+// пустой, если страница последняя. Курсор другой сортировки - ErrBadCursor
+func (r *TaskRepository) ListTasksPage(ctx context.Context, filter TaskFilter, page TaskPage) ([]models.Task, string, error) {
+    limit := page.Limit
+    if limit <= 0 {
+        limit = DefaultPageLimit
+    }
+    if limit > MaxPageLimit {
+        limit = MaxPageLimit
+    }
+    spec := sortSpec(page.Sort)
+    keys := orderKeys(page.Sort)
+
+    var args []interface{}
+    conds := filter.conditions(&args)
+    if page.Cursor != "" {
+        cur, err := decodeCursor(page.Cursor)
+        if err != nil {
+            return nil, "", err
+        }
+        if cur.Sort != spec || len(cur.Values) != len(keys) {
+            return nil, "", fmt.Errorf("курсор выдан для sort=%q: %w", cur.Sort, ErrBadCursor)
+        }
+        conds = append(conds, keysetCondition(keys, cur.Values, &args))
+    }
+
+    order := make([]string, len(keys))
+    for i, k := range keys {
+        order[i] = k.expr
+        if k.desc {
+            order[i] += " DESC"
+        }
+    }
+    args = append(args, limit+1)
+    query := taskSelect + whereClause(conds) + `
+        ORDER BY ` + strings.Join(order, ", ") + fmt.Sprintf(`
+        LIMIT $%d`, len(args))
+
+    tasks, err := r.queryTasks(ctx, query, args...)
+    if err != nil || len(tasks) <= limit {
+        return tasks, "", err
+    }
+    tasks = tasks[:limit]
+
+    next, err := r.cursorAfter(ctx, keys, spec, tasks[limit-1].ID)
+    if err != nil {
+        return nil, "", err
+    }
+    return tasks, next, nil
+}
+
+// cursorAfter - курсор, указывающий на задачу id: значения ее ключей сортировки в виде текста
+func (r *TaskRepository) cursorAfter(ctx context.Context, keys []orderKey, spec string, id int) (string, error) {
+    exprs := make([]string, len(keys))
+    for i, k := range keys {
+        exprs[i] = "(" + k.expr + ")::text"
+    }
+    values := make([]sql.NullString, len(keys))
+    dest := make([]interface{}, len(keys))
+    for i := range values {
+        dest[i] = &values[i]
+    }
+    query := `SELECT ` + strings.Join(exprs, ", ") + `
+        FROM tasks t
+        JOIN board_columns c ON c.id = t.column_id
+        WHERE t.id = $1`
+    if err := r.db.QueryRowContext(ctx, query, id).Scan(dest...); err != nil {
+        return "", err
+    }
+
+    cur := pageCursor{Sort: spec, Values: make([]string, len(values))}
+    for i, v := range values {
+        cur.Values[i] = v.String
+    }
+    return encodeCursor(cur)
+}
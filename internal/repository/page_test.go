@@ -0,0 +1,126 @@
+package repository
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func TestParseTaskSort(t *testing.T) {
+    tests := []struct {
+        spec    string
+        want    []TaskSort
+        wantErr bool
+    }{
+        {spec: "", want: nil},
+        {spec: "title", want: []TaskSort{{Field: "title"}}},
+        {spec: "-deadline, +title", want: []TaskSort{{Field: "deadline", Desc: true}, {Field: "title"}}},
+        {spec: "id,,-id", want: []TaskSort{{Field: "id"}}},
+        {spec: "t.id", wantErr: true},
+        {spec: "title,password", wantErr: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.spec, func(t *testing.T) {
+            got, err := ParseTaskSort(tt.spec)
+            if tt.wantErr {
+                if err == nil {
+                    t.Fatalf("ParseTaskSort(%q) = %+v, ожидалась ошибка", tt.spec, got)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatalf("ParseTaskSort(%q): %v", tt.spec, err)
+            }
+            if !reflect.DeepEqual(got, tt.want) {
+                t.Fatalf("ParseTaskSort(%q) = %+v, ожидалось %+v", tt.spec, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestOrderKeys(t *testing.T) {
+    tests := []struct {
+        name  string
+        sorts []TaskSort
+        want  []orderKey
+    }{
+        {
+            name: "порядок доски",
+            want: []orderKey{
+                {"t.board_id", "int", false},
+                {"c.position", "int", false},
+                {"t.position", "text", false},
+                {"t.id", "int", false},
+            },
+        },
+        {
+            name:  "id не дублируется",
+            sorts: []TaskSort{{Field: "id", Desc: true}},
+            want:  []orderKey{{"t.id", "int", true}},
+        },
+        {
+            name:  "пустые даты последними по возрастанию",
+            sorts: []TaskSort{{Field: "deadline"}},
+            want: []orderKey{
+                {"COALESCE(t.deadline, 'infinity'::timestamp)", "timestamp", false},
+                {"t.id", "int", false},
+            },
+        },
+        {
+            name:  "пустые даты последними по убыванию",
+            sorts: []TaskSort{{Field: "deadline", Desc: true}},
+            want: []orderKey{
+                {"COALESCE(t.deadline, '-infinity'::timestamp)", "timestamp", true},
+                {"t.id", "int", false},
+            },
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := orderKeys(tt.sorts); !reflect.DeepEqual(got, tt.want) {
+                t.Fatalf("orderKeys(%+v) = %+v, ожидалось %+v", tt.sorts, got, tt.want)
+            }
+        })
+    }
+}
+
+func TestCursor(t *testing.T) {
+    cur := pageCursor{Sort: "-deadline,title", Values: []string{"2026-01-20 15:00:00", "Отчет, \"итог\"", "42"}}
+    s, err := encodeCursor(cur)
+    if err != nil {
+        t.Fatal(err)
+    }
+    got, err := decodeCursor(s)
+    if err != nil {
+        t.Fatalf("decodeCursor(%q): %v", s, err)
+    }
+    if !reflect.DeepEqual(got, cur) {
+        t.Fatalf("decodeCursor(encodeCursor(%+v)) = %+v", cur, got)
+    }
+
+    for _, bad := range []string{"не base64", "e30=", "bm90IGpzb24", "WzEsMl0"} {
+        if _, err := decodeCursor(bad); !errors.Is(err, ErrBadCursor) {
+            t.Errorf("decodeCursor(%q): %v, ожидалось ErrBadCursor", bad, err)
+        }
+    }
+}
+
+func TestKeysetCondition(t *testing.T) {
+    keys := []orderKey{
+        {"c.position", "int", false},
+        {"t.deadline", "timestamp", true},
+        {"t.id", "int", false},
+    }
+    args := []interface{}{"board"}
+    got := keysetCondition(keys, []string{"2", "2026-01-20", "7"}, &args)
+
+    want := "((c.position > $2::int)" +
+        " OR (c.position = $2::int AND t.deadline < $3::timestamp)" +
+        " OR (c.position = $2::int AND t.deadline = $3::timestamp AND t.id > $4::int))"
+    if got != want {
+        t.Fatalf("keysetCondition:\n%s\nожидалось:\n%s", got, want)
+    }
+    if wantArgs := []interface{}{"board", "2", "2026-01-20", "7"}; !reflect.DeepEqual(args, wantArgs) {
+        t.Fatalf("аргументы %v, ожидалось %v", args, wantArgs)
+    }
+}
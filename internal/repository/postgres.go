@@ -4,27 +4,177 @@ import (
     "context"
     "database/sql"
     "fmt"
+    "sort"
     "time"
     "kanban-calendar/internal/models"
+    "github.com/lib/pq"
 )
 
 // TaskRepository - репозиторий для работы с задачами
 type TaskRepository struct {
-    db *sql.DB
+    db   *sql.DB
+    zone *time.Location // пояс, в котором разворачиваются повторения (BYDAY, переход на летнее время)
 }
 
-// NewTaskRepository - конструктор
-func NewTaskRepository(db *sql.DB) *TaskRepository {
-    return &TaskRepository{db: db}
+// NewTaskRepository - конструктор; zone - пояс сервера
+func NewTaskRepository(db *sql.DB, zone *time.Location) *TaskRepository {
+    return &TaskRepository{db: db, zone: zone}
 }
 
+// taskSelect - общий SELECT задачи вместе с данными ее колонки
+const taskSelect = `
+    SELECT t.id, t.title, COALESCE(t.description, ''), c.slug, COALESCE(t.priority, ''),
+           t.created_at, t.updated_at, t.deadline, t.start_date, t.end_date,
+           COALESCE(t.assignee, ''), COALESCE(t.external_uid, ''), COALESCE(t.last_notified_hours, 999),
+           t.board_id, t.column_id, t.position, c.is_done, COALESCE(c.color, ''),
+           ARRAY(SELECT tg.name FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id
+                 WHERE tt.task_id = t.id ORDER BY tg.name) AS tags,
+           COALESCE(t.rrule, ''), t.exdates, COALESCE(t.series_id, 0), t.all_day,
+           COALESCE(t.external_source, ''), t.external_sequence, t.external_modified, t.archived_at,
+           COALESCE(t.caldav_name, ''), COALESCE(t.caldav_component, ''),
+           COALESCE(t.external_priority, 0), t.external_percent, t.version, t.deleted_at,
+           COALESCE(t.parent_id, 0), ` + progressColumns + `,
+           ` + blockedCondition + ` AS blocked
+    FROM tasks t
+    JOIN board_columns c ON c.id = t.column_id
+`
+
+// progressColumns - счетчики прогресса задачи t: [выполнено, всего] по подзадачам и по чек-листу
+const progressColumns = `
+           (SELECT ARRAY[COUNT(*) FILTER (WHERE sc.is_done), COUNT(*)]
+            FROM tasks s JOIN board_columns sc ON sc.id = s.column_id
+            WHERE s.parent_id = t.id AND s.deleted_at IS NULL) AS subtasks,
+           (SELECT ARRAY[COUNT(*) FILTER (WHERE ci.done), COUNT(*)]
+            FROM checklist_items ci WHERE ci.task_id = t.id) AS checklist`
+
+// scanProgress - прогресс из счетчиков progressColumns
+func scanProgress(subtasks, checklist []int64) *models.Progress {
+    if len(subtasks) != 2 || len(checklist) != 2 {
+        return nil
+    }
+    return models.NewProgress(int(subtasks[0]), int(subtasks[1]), int(checklist[0]), int(checklist[1]))
+}
+
+// rowScanner - общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+// scanTask - читает строку taskSelect в структуру задачи
+func scanTask(row rowScanner) (*models.Task, error) {
+    task := &models.Task{}
+    var deadline, startDate, endDate, externalModified, archivedAt, deletedAt sql.NullTime
+    var externalSequence, externalPercent sql.NullInt64
+    var exdates []string
+    var subtasks, checklist []int64 // выполнено, всего
+    
+    err := row.Scan(
+        &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
+        &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
+        &task.Assignee, &task.ExternalUID, &task.LastNotifiedHours,
+        &task.BoardID, &task.ColumnID, &task.Position, &task.IsDone, &task.ColumnColor,
+        pq.Array(&task.Tags),
+        &task.RRule, pq.Array(&exdates), &task.SeriesID, &task.AllDay,
+        &task.ExternalSource, &externalSequence, &externalModified, &archivedAt,
+        &task.CalDAVName, &task.CalDAVComponent,
+        &task.ExternalPriority, &externalPercent, &task.Version, &deletedAt,
+        &task.ParentID, pq.Array(&subtasks), pq.Array(&checklist), &task.Blocked,
+    )
+    if err != nil {
+        return nil, err
+    }
+    task.ExDates = decodeExDates(exdates)
+    task.Progress = scanProgress(subtasks, checklist)
+    if externalSequence.Valid {
+        seq := int(externalSequence.Int64)
+        task.ExternalSequence = &seq
+    }
+    if externalPercent.Valid {
+        percent := int(externalPercent.Int64)
+        task.ExternalPercent = &percent
+    }
+    if externalModified.Valid {
+        task.ExternalModified = &externalModified.Time
+    }
+    if archivedAt.Valid {
+        task.ArchivedAt = &archivedAt.Time
+    }
+    if deletedAt.Valid {
+        task.DeletedAt = &deletedAt.Time
+    }
+    
+    // Преобразуем NullTime в *time.Time
+    if deadline.Valid {
+        task.Deadline = &deadline.Time
+    }
+    if startDate.Valid {
+        task.StartDate = &startDate.Time
+    }
+    if endDate.Valid {
+        task.EndDate = &endDate.Time
+    }
+    
+    return task, nil
+}
+
+// queryTasks - выполняет запрос на основе taskSelect и собирает задачи
+func (r *TaskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]models.Task, error) {
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+    
+    var tasks []models.Task
+    for rows.Next() {
+        task, err := scanTask(rows)
+        if err != nil {
+            return nil, err
+        }
+        tasks = append(tasks, *task)
+    }
+    
+    return tasks, rows.Err()
+}
+
+// CreateTask - создает задачу в конце ее колонки
 func (r *TaskRepository) CreateTask(ctx context.Context, task *models.Task) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    task.Position, err = lockColumnTail(ctx, tx, task.ColumnID, 0)
+    if err != nil {
+        return err
+    }
+    if task.WIPWarning, err = checkWIPLimit(ctx, tx, task.ColumnID, 0); err != nil {
+        return err
+    }
+    if err := checkParent(ctx, tx, 0, task.ParentID); err != nil {
+        return err
+    }
+    
+    if err := insertTask(ctx, tx, task); err != nil {
+        return err
+    }
+    
+    return tx.Commit()
+}
+
+// insertTask - вставляет задачу с уже выбранной колонкой и рангом, сохраняет теги
+func insertTask(ctx context.Context, tx *sql.Tx, task *models.Task) error {
     query := `
-        INSERT INTO tasks (title, description, status, priority, deadline, start_date, end_date, assignee, external_uid, last_notified_hours)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-        RETURNING id, created_at, updated_at`
+        INSERT INTO tasks (title, description, status, priority, deadline, start_date, end_date, assignee, external_uid, last_notified_hours,
+                           board_id, column_id, position, rrule, exdates, series_id, all_day,
+                           external_source, external_sequence, external_modified, caldav_name, caldav_component,
+                           external_priority, external_percent, parent_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NULLIF($14, ''), $15, NULLIF($16, 0), $17,
+                NULLIF($18, ''), $19, $20, NULLIF($21, ''), NULLIF($22, ''), NULLIF($23, 0), $24, NULLIF($25, 0))
+        RETURNING id, created_at, updated_at, version`
     
-    return r.db.QueryRowContext(ctx, query,
+    err := tx.QueryRowContext(ctx, query,
         task.Title, 
         task.Description, 
         task.Status, 
@@ -35,97 +185,150 @@ func (r *TaskRepository) CreateTask(ctx context.Context, task *models.Task) erro
         task.Assignee, 
         task.ExternalUID,
         task.LastNotifiedHours,
-    ).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt)
+        task.BoardID,
+        task.ColumnID,
+        task.Position,
+        task.RRule,
+        pq.Array(encodeExDates(task.ExDates)),
+        task.SeriesID,
+        task.AllDay,
+        task.ExternalSource,
+        task.ExternalSequence,
+        task.ExternalModified,
+        task.CalDAVName,
+        task.CalDAVComponent,
+        task.ExternalPriority,
+        task.ExternalPercent,
+        task.ParentID,
+    ).Scan(&task.ID, &task.CreatedAt, &task.UpdatedAt, &task.Version)
+    if err != nil {
+        // Тот же UID внешнего источника уже у другой задачи (например, ресурс CalDAV на другой доске)
+        return mapUniqueViolation(err, fmt.Sprintf("событие %s в источнике %s", task.ExternalUID, task.ExternalSource))
+    }
+    
+    task.Tags = NormalizeTags(task.Tags)
+    if err := setTaskTags(ctx, tx, task.ID, task.Tags); err != nil {
+        return err
+    }
+    
+    created, err := loadTaskTx(ctx, tx, task.ID)
+    if err != nil {
+        return err
+    }
+    return recordEvent(ctx, tx, models.EventCreate, nil, created)
 }
 
-// GetTaskByID - получает задачу по ID (БЕЗ TAGS)
+// GetTaskByID - получает задачу по ID
 func (r *TaskRepository) GetTaskByID(ctx context.Context, id int) (*models.Task, error) {
-    query := `
-        SELECT id, title, description, status, priority, created_at, updated_at,
-               deadline, start_date, end_date, assignee
-        FROM tasks WHERE id = $1
-    `
-    
-    task := &models.Task{}
-    var deadline, startDate, endDate sql.NullTime
-    
-    err := r.db.QueryRowContext(ctx, query, id).Scan(
-        &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-        &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-        &task.Assignee,
-        // УБРАЛИ: pq.Array(&tags),
-    )
-    
+    task, err := scanTask(r.db.QueryRowContext(ctx, taskSelect+` WHERE t.id = $1 AND t.deleted_at IS NULL`, id))
     if err != nil {
         if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("задача с ID %d не найдена", id)
+            return nil, fmt.Errorf("задача с ID %d: %w", id, ErrNotFound)
         }
         return nil, err
     }
     
-    // Преобразуем NullTime в *time.Time
-    if deadline.Valid {
-        task.Deadline = &deadline.Time
-    }
-    if startDate.Valid {
-        task.StartDate = &startDate.Time
+    if task.Checklist, err = r.GetChecklist(ctx, id); err != nil {
+        return nil, err
     }
-    if endDate.Valid {
-        task.EndDate = &endDate.Time
+    links, err := r.GetTaskLinks(ctx, id)
+    if err != nil {
+        return nil, err
     }
-    // УБРАЛИ: task.Tags = tags
-    
+    task.Blockers, task.Dependents = links.Blockers, links.Dependents
     return task, nil
 }
 
-// Получение всех задач (обновлено: добавлено поле last_notified_hours)
+// GetAllTasks - получение всех задач
 func (r *TaskRepository) GetAllTasks(ctx context.Context) ([]models.Task, error) {
-	query := `
-		SELECT id, title, description, status, priority, created_at, updated_at, 
-		       deadline, start_date, end_date, assignee, last_notified_hours
-		FROM tasks
-	`
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+    return r.queryTasks(ctx, taskSelect+` WHERE t.archived_at IS NULL AND t.deleted_at IS NULL ORDER BY t.board_id, c.position, t.position, t.id`)
+}
 
-	var tasks []models.Task
-	for rows.Next() {
-		var task models.Task
-		var deadline, startDate, endDate sql.NullTime
-		
-		err := rows.Scan(
-			&task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-			&task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-			&task.Assignee, &task.LastNotifiedHours, // Сканируем новое поле
-		)
-		if err != nil {
-			return nil, err
-		}
-		
-		if deadline.Valid { task.Deadline = &deadline.Time }
-		if startDate.Valid { task.StartDate = &startDate.Time }
-		if endDate.Valid { task.EndDate = &endDate.Time }
-		
-		tasks = append(tasks, task)
-	}
-	return tasks, nil
+// GetBoardTasks - задачи одной доски в порядке колонок
+func (r *TaskRepository) GetBoardTasks(ctx context.Context, boardID int) ([]models.Task, error) {
+    query := taskSelect + ` WHERE t.board_id = $1 AND t.archived_at IS NULL AND t.deleted_at IS NULL ORDER BY c.position, t.position, t.id`
+    return r.queryTasks(ctx, query, boardID)
 }
 
-// UpdateTask - обновляет задачу (БЕЗ TAGS)
+// UpdateTask - обновляет задачу вместе с тегами. При смене колонки задача встает в ее конец
 func (r *TaskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+    
+    if err := r.updateTask(ctx, tx, task); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// updateTask - UpdateTask внутри транзакции tx
+func (r *TaskRepository) updateTask(ctx context.Context, tx *sql.Tx, task *models.Task) error {
+    var currentColumn, version int
+    var position string
+    var wasDone bool
     query := `
+        SELECT t.column_id, t.position, c.is_done, t.version
+        FROM tasks t JOIN board_columns c ON c.id = t.column_id
+        WHERE t.id = $1 AND t.deleted_at IS NULL
+        FOR UPDATE OF t
+    `
+    err := tx.QueryRowContext(ctx, query, task.ID).Scan(&currentColumn, &position, &wasDone, &version)
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return fmt.Errorf("задача с ID %d: %w", task.ID, ErrNotFound)
+        }
+        return err
+    }
+    // Проверка под блокировкой строки: между чтением клиента и записью задачу могли изменить
+    if task.Version != 0 && task.Version != version {
+        return fmt.Errorf("задача %d: ожидалась версия %d, текущая %d: %w", task.ID, task.Version, version, ErrVersionMismatch)
+    }
+    before, err := loadTaskTx(ctx, tx, task.ID)
+    if err != nil {
+        return err
+    }
+    
+    if currentColumn != task.ColumnID {
+        position, err = lockColumnTail(ctx, tx, task.ColumnID, task.ID)
+        if err != nil {
+            return err
+        }
+        if task.WIPWarning, err = checkWIPLimit(ctx, tx, task.ColumnID, task.ID); err != nil {
+            return err
+        }
+        starts, err := startsWork(ctx, tx, currentColumn, task.ColumnID)
+        if err != nil {
+            return err
+        }
+        if starts {
+            if task.BlockedWarning, err = checkBlocked(ctx, tx, task.ID, task.Force); err != nil {
+                return err
+            }
+        }
+    }
+    task.Position = position
+    if before.ParentID != task.ParentID {
+        if err := checkParent(ctx, tx, task.ID, task.ParentID); err != nil {
+            return err
+        }
+    }
+    
+    query = `
         UPDATE tasks 
         SET title = $1, description = $2, status = $3, priority = $4,
             deadline = $5, start_date = $6, end_date = $7, 
-            assignee = $8, updated_at = CURRENT_TIMESTAMP
-        WHERE id = $9
-        RETURNING updated_at
+            assignee = $8, board_id = $9, column_id = $10, position = $11,
+            rrule = NULLIF($12, ''), exdates = $13, all_day = $14,
+            external_priority = NULLIF($16, 0), external_percent = $17, parent_id = NULLIF($18, 0),
+            version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $15
+        RETURNING updated_at, version
     `
     
-    return r.db.QueryRowContext(ctx, query,
+    err = tx.QueryRowContext(ctx, query,
         task.Title,
         task.Description,
         task.Status,
@@ -134,94 +337,116 @@ func (r *TaskRepository) UpdateTask(ctx context.Context, task *models.Task) erro
         task.StartDate,
         task.EndDate,
         task.Assignee,
-        // УБРАЛИ: pq.Array(task.Tags),
+        task.BoardID,
+        task.ColumnID,
+        task.Position,
+        task.RRule,
+        pq.Array(encodeExDates(task.ExDates)),
+        task.AllDay,
         task.ID,
-    ).Scan(&task.UpdatedAt)
-}
-
-// DeleteTask - удаляет задачу
-func (r *TaskRepository) DeleteTask(ctx context.Context, id int) error {
-    query := `DELETE FROM tasks WHERE id = $1`
-    result, err := r.db.ExecContext(ctx, query, id)
+        task.ExternalPriority,
+        task.ExternalPercent,
+        task.ParentID,
+    ).Scan(&task.UpdatedAt, &task.Version)
     if err != nil {
         return err
     }
     
-    rows, err := result.RowsAffected()
+    task.Tags = NormalizeTags(task.Tags)
+    if err := setTaskTags(ctx, tx, task.ID, task.Tags); err != nil {
+        return err
+    }
+    after, err := loadTaskTx(ctx, tx, task.ID)
     if err != nil {
         return err
     }
-    
-    if rows == 0 {
-        return fmt.Errorf("задача с ID %d не найдена", id)
+    if err := recordEvent(ctx, tx, models.EventUpdate, before, after); err != nil {
+        return err
     }
     
-    return nil
+    // Выполненное вхождение повторяющейся задачи порождает следующее
+    return spawnIfCompleted(ctx, tx, r.zone, task.ID, wasDone)
 }
 
-// GetTasksByStatus - получает задачи по статусу (БЕЗ TAGS)
-func (r *TaskRepository) GetTasksByStatus(ctx context.Context, status models.TaskStatus) ([]models.Task, error) {
-    query := `
-        SELECT id, title, description, status, priority, created_at, updated_at,
-               deadline, start_date, end_date, assignee
-        FROM tasks 
-        WHERE status = $1
-        ORDER BY created_at DESC
-    `
+// DeleteTask - перемещает задачу в корзину; version != 0 - только если с этой версии задачу не меняли.
+// Насовсем задача удаляется из корзины (PurgeTask) или планировщиком после срока хранения
+func (r *TaskRepository) DeleteTask(ctx context.Context, id, version int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
     
-    rows, err := r.db.QueryContext(ctx, query, status)
+    var current int
+    query := `SELECT version FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+    err = tx.QueryRowContext(ctx, query, id).Scan(&current)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("задача с ID %d: %w", id, ErrNotFound)
+    }
     if err != nil {
-        return nil, err
+        return err
+    }
+    if version != 0 && version != current {
+        return fmt.Errorf("задача %d: ожидалась версия %d, текущая %d: %w", id, version, current, ErrVersionMismatch)
+    }
+    before, err := loadTaskTx(ctx, tx, id)
+    if err != nil {
+        return err
     }
-    defer rows.Close()
     
-    var tasks []models.Task
-    for rows.Next() {
-        var task models.Task
-        var deadline, startDate, endDate sql.NullTime
-        
-        err := rows.Scan(
-            &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-            &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-            &task.Assignee,
-            // УБРАЛИ: pq.Array(&tags),
-        )
-        if err != nil {
-            return nil, err
-        }
-        
-        if deadline.Valid {
-            task.Deadline = &deadline.Time
-        }
-        if startDate.Valid {
-            task.StartDate = &startDate.Time
-        }
-        if endDate.Valid {
-            task.EndDate = &endDate.Time
-        }
-        // УБРАЛИ: task.Tags = tags
-        
-        tasks = append(tasks, task)
+    query = `
+        UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `
+    if _, err := tx.ExecContext(ctx, query, id); err != nil {
+        return err
+    }
+    after, err := loadTaskTx(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    if err := recordEvent(ctx, tx, models.EventDelete, before, after); err != nil {
+        return err
     }
     
-    return tasks, nil
+    return tx.Commit()
+}
+
+// ListTasks - задачи по фильтру в порядке досок и колонок
+func (r *TaskRepository) ListTasks(ctx context.Context, filter TaskFilter) ([]models.Task, error) {
+    var args []interface{}
+    query := taskSelect + whereClause(filter.conditions(&args)) + `
+        ORDER BY t.board_id, c.position, t.position, t.id
+    `
+    return r.queryTasks(ctx, query, args...)
 }
 
 // GetCalendarEvents - получает события для календаря
-func (r *TaskRepository) GetCalendarEvents(ctx context.Context, startDate, endDate time.Time) ([]models.CalendarEvent, error) {
+func (r *TaskRepository) GetCalendarEvents(ctx context.Context, startDate, endDate time.Time, filter TaskFilter) ([]models.CalendarEvent, error) {
+    args := []interface{}{startDate, endDate}
+    conds := append([]string{`(
+            (t.start_date BETWEEN $1 AND $2) 
+         OR (t.end_date BETWEEN $1 AND $2)
+         OR (t.deadline BETWEEN $1 AND $2)
+         OR (t.created_at BETWEEN $1 AND $2)
+         OR (t.rrule IS NOT NULL AND NOT c.is_done AND COALESCE(t.start_date, t.deadline) <= $2)
+        )`}, filter.conditions(&args)...)
+    
     query := `
-        SELECT id, title, description, status, 
-               COALESCE(start_date, created_at) as start,
-               COALESCE(end_date, deadline, created_at + INTERVAL '1 day') as end
-        FROM tasks 
-        WHERE (start_date BETWEEN $1 AND $2) 
-           OR (end_date BETWEEN $1 AND $2)
-           OR (deadline BETWEEN $1 AND $2)
-           OR (created_at BETWEEN $1 AND $2)
+        SELECT t.id, t.title, COALESCE(t.description, ''), c.slug, c.is_done, COALESCE(c.color, ''),
+               CASE WHEN t.rrule IS NOT NULL THEN COALESCE(t.start_date, t.deadline, t.created_at)
+                    ELSE COALESCE(t.start_date, t.created_at) END as start,
+               COALESCE(t.end_date, t.deadline, t.created_at + INTERVAL '1 day') as end,
+               ARRAY(SELECT tg.name FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id
+                     WHERE tt.task_id = t.id ORDER BY tg.name) AS tags,
+               CASE WHEN c.is_done THEN '' ELSE COALESCE(t.rrule, '') END, t.exdates, t.all_day,
+               COALESCE(t.parent_id, 0), ` + progressColumns + `
+        FROM tasks t
+        JOIN board_columns c ON c.id = t.column_id` + whereClause(conds) + `
         ORDER BY start
     `
     
-    rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+    rows, err := r.db.QueryContext(ctx, query, args...)
     if err != nil {
         return nil, err
     }
@@ -231,178 +456,87 @@ func (r *TaskRepository) GetCalendarEvents(ctx context.Context, startDate, endDa
     for rows.Next() {
         var event models.CalendarEvent
         var start, end time.Time
+        var isDone bool
+        var columnColor, rrule string
+        var exdates []string
+        var subtasks, checklist []int64
         
         err := rows.Scan(
             &event.ID,
             &event.Title,
             &event.Description,
             &event.Status,
+            &isDone,
+            &columnColor,
             &start,
             &end,
+            pq.Array(&event.Tags),
+            &rrule,
+            pq.Array(&exdates),
+            &event.AllDay,
+            &event.ParentID,
+            pq.Array(&subtasks),
+            pq.Array(&checklist),
         )
         if err != nil {
             return nil, err
         }
         
+        event.Progress = scanProgress(subtasks, checklist)
         event.Start = start
         event.End = end
         
-        // Устанавливаем цвет по статусу
-        if event.Status == models.StatusDone {
-            event.Color = "#28a745"
-        } else if event.Status == models.StatusInProgress {
-            event.Color = "#ffc107"
-        } else {
-            event.Color = "#3174ad"
-        }
+        // Устанавливаем цвет по колонке
+        event.Color = models.StatusColor(event.Status, isDone, columnColor)
         
+        if rrule != "" {
+            // Открытая повторяющаяся задача разворачивается во все вхождения окна
+            events = append(events, expandRecurring(event, rrule, decodeExDates(exdates), startDate, endDate, r.zone)...)
+            continue
+        }
         events = append(events, event)
     }
     
+    sort.SliceStable(events, func(i, j int) bool {
+        return events[i].Start.Before(events[j].Start)
+    })
     return events, nil
 }
+
 // GetUpcomingDeadlines - получает задачи с приближающимися дедлайнами
 func (r *TaskRepository) GetUpcomingDeadlines(ctx context.Context, hoursBefore int) ([]models.Task, error) {
-    query := `
-        SELECT id, title, description, status, priority, created_at, updated_at,
-               deadline, start_date, end_date, assignee
-        FROM tasks 
-        WHERE deadline IS NOT NULL 
-          AND deadline > NOW()
-          AND deadline <= NOW() + INTERVAL '1 hour' * $1
-          AND status != $2
-        ORDER BY deadline ASC
+    query := taskSelect + `
+        WHERE t.deadline IS NOT NULL 
+          AND t.deadline > NOW()
+          AND t.deadline <= NOW() + INTERVAL '1 hour' * $1
+          AND NOT c.is_done
+          AND t.archived_at IS NULL AND t.deleted_at IS NULL
+        ORDER BY t.deadline ASC
     `
-    
-    rows, err := r.db.QueryContext(ctx, query, hoursBefore, models.StatusDone)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-    
-    var tasks []models.Task
-    for rows.Next() {
-        var task models.Task
-        var deadline, startDate, endDate sql.NullTime
-        
-        err := rows.Scan(
-            &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-            &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-            &task.Assignee,
-        )
-        if err != nil {
-            return nil, err
-        }
-        
-        if deadline.Valid {
-            task.Deadline = &deadline.Time
-        }
-        if startDate.Valid {
-            task.StartDate = &startDate.Time
-        }
-        if endDate.Valid {
-            task.EndDate = &endDate.Time
-        }
-        
-        tasks = append(tasks, task)
-    }
-    
-    return tasks, nil
+    return r.queryTasks(ctx, query, hoursBefore)
 }
 
 // GetOverdueTasks - получает просроченные задачи
 func (r *TaskRepository) GetOverdueTasks(ctx context.Context) ([]models.Task, error) {
-    query := `
-        SELECT id, title, description, status, priority, created_at, updated_at,
-               deadline, start_date, end_date, assignee
-        FROM tasks 
-        WHERE deadline IS NOT NULL 
-          AND deadline < NOW()
-          AND status != $1
-        ORDER BY deadline ASC
+    query := taskSelect + `
+        WHERE t.deadline IS NOT NULL 
+          AND t.deadline < NOW()
+          AND NOT c.is_done
+          AND t.archived_at IS NULL AND t.deleted_at IS NULL
+        ORDER BY t.deadline ASC
     `
-    
-    rows, err := r.db.QueryContext(ctx, query, models.StatusDone)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-    
-    var tasks []models.Task
-    for rows.Next() {
-        var task models.Task
-        var deadline, startDate, endDate sql.NullTime
-        
-        err := rows.Scan(
-            &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-            &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-            &task.Assignee,
-        )
-        if err != nil {
-            return nil, err
-        }
-        
-        if deadline.Valid {
-            task.Deadline = &deadline.Time
-        }
-        if startDate.Valid {
-            task.StartDate = &startDate.Time
-        }
-        if endDate.Valid {
-            task.EndDate = &endDate.Time
-        }
-        
-        tasks = append(tasks, task)
-    }
-    
-    return tasks, nil
+    return r.queryTasks(ctx, query)
 }
 
 // GetTasksCompletedToday - получает задачи, выполненные сегодня
 func (r *TaskRepository) GetTasksCompletedToday(ctx context.Context) ([]models.Task, error) {
-    query := `
-        SELECT id, title, description, status, priority, created_at, updated_at,
-               deadline, start_date, end_date, assignee
-        FROM tasks 
-        WHERE status = $1 
-          AND DATE(updated_at) = CURRENT_DATE
-        ORDER BY updated_at DESC
+    query := taskSelect + `
+        WHERE c.is_done 
+          AND DATE(t.updated_at) = CURRENT_DATE
+          AND t.archived_at IS NULL AND t.deleted_at IS NULL
+        ORDER BY t.updated_at DESC
     `
-    
-    rows, err := r.db.QueryContext(ctx, query, models.StatusDone)
-    if err != nil {
-        return nil, err
-    }
-    defer rows.Close()
-    
-    var tasks []models.Task
-    for rows.Next() {
-        var task models.Task
-        var deadline, startDate, endDate sql.NullTime
-        
-        err := rows.Scan(
-            &task.ID, &task.Title, &task.Description, &task.Status, &task.Priority,
-            &task.CreatedAt, &task.UpdatedAt, &deadline, &startDate, &endDate,
-            &task.Assignee,
-        )
-        if err != nil {
-            return nil, err
-        }
-        
-        if deadline.Valid {
-            task.Deadline = &deadline.Time
-        }
-        if startDate.Valid {
-            task.StartDate = &startDate.Time
-        }
-        if endDate.Valid {
-            task.EndDate = &endDate.Time
-        }
-        
-        tasks = append(tasks, task)
-    }
-    
-    return tasks, nil
+    return r.queryTasks(ctx, query)
 }
 
 // CreateNotification - создает запись об уведомлении
@@ -426,11 +560,36 @@ func (r *TaskRepository) CreateNotification(ctx context.Context, notification *m
 
 // MarkNotificationSent - помечает уведомление как отправленное
 func (r *TaskRepository) MarkNotificationSent(ctx context.Context, id int) error {
-    query := `UPDATE notifications SET is_sent = true WHERE id = $1`
+    query := `UPDATE notifications SET is_sent = true, sent_at = CURRENT_TIMESTAMP WHERE id = $1`
     _, err := r.db.ExecContext(ctx, query, id)
     return err
 }
 
+// GetPendingNotifications - неотправленные уведомления типа notificationType, созданные в промежутке [since, before]
+func (r *TaskRepository) GetPendingNotifications(ctx context.Context, notificationType string, since, before time.Time) ([]models.Notification, error) {
+    query := `
+        SELECT id, task_id, type, message, sent_at, is_sent, COALESCE(chat_id, '')
+        FROM notifications
+        WHERE NOT is_sent AND type = $1 AND created_at BETWEEN $2 AND $3
+        ORDER BY id
+    `
+    rows, err := r.db.QueryContext(ctx, query, notificationType, since, before)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var notifications []models.Notification
+    for rows.Next() {
+        var n models.Notification
+        if err := rows.Scan(&n.ID, &n.TaskID, &n.Type, &n.Message, &n.SentAt, &n.IsSent, &n.ChatID); err != nil {
+            return nil, err
+        }
+        notifications = append(notifications, n)
+    }
+    return notifications, rows.Err()
+}
+
 func (r *TaskRepository) UpdateLastNotified(ctx context.Context, taskID int, hours int) error {
     query := `UPDATE tasks SET last_notified_hours = $1, updated_at = NOW() WHERE id = $2`
     _, err := r.db.ExecContext(ctx, query, hours, taskID)
@@ -0,0 +1,395 @@
+package repository
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+    "kanban-calendar/internal/datetime"
+    "github.com/lib/pq"
+)
+
+// QueryEnv - контекст разбора запроса: кто такой "me", в каком поясе понимать даты и что такое "now"
+type QueryEnv struct {
+    User string
+    Zone *time.Location
+    Now  time.Time
+}
+
+// TaskQuery - скомпилированный запрос языка представлений:
+//
+//   assignee:me status:!done deadline:<now priority:high tag:backend
+//
+// Условия складываются через И; значения через запятую - любое из них (status:todo,in_progress).
+// "-поле:..." или "поле:!..." - отрицание. Слова без поля ищутся подстрокой в заголовке и описании.
+// Все значения передаются в SQL параметрами, в текст запроса попадают только имена полей
+type TaskQuery struct {
+    Source   string
+    terms    []queryTerm
+    archived bool // is:archived - запрос сам просит архивные задачи
+}
+
+// queryTerm - одно условие; sql добавляет значения в аргументы через arg
+type queryTerm struct {
+    negate bool
+    sql    func(arg func(interface{}) string) string
+}
+
+// QueryError - ошибка разбора запроса с позицией условия, на котором он сломался
+type QueryError struct {
+    Token   string
+    Message string
+}
+
+func (e *QueryError) Error() string {
+    if e.Token == "" {
+        return e.Message
+    }
+    return fmt.Sprintf("%s: %s", e.Token, e.Message)
+}
+
+// queryFields - поля запроса (для подсказок в ошибках)
+var queryFields = []string{"status", "assignee", "priority", "tag", "board", "deadline", "start", "end", "created", "updated", "is", "has"}
+
+// dateFields - поля-даты и их колонки
+var dateFields = map[string]string{
+    "deadline": "t.deadline",
+    "start":    "t.start_date",
+    "end":      "t.end_date",
+    "created":  "t.created_at",
+    "updated":  "t.updated_at",
+}
+
+// priorityRank - порядок приоритетов для priority:>=medium
+var priorityRank = map[string]int{"none": 0, "low": 1, "medium": 2, "high": 3}
+
+const priorityRankSQL = "CASE t.priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END"
+
+// ParseTaskQuery - разбирает запрос; пустая строка - запрос без условий
+func ParseTaskQuery(s string, env QueryEnv) (*TaskQuery, error) {
+    if env.Zone == nil {
+        env.Zone = time.UTC
+    }
+    if env.Now.IsZero() {
+        env.Now = time.Now()
+    }
+    tokens, err := splitQuery(s)
+    if err != nil {
+        return nil, err
+    }
+
+    q := &TaskQuery{Source: strings.TrimSpace(s)}
+    for _, tok := range tokens {
+        term, err := q.compile(tok, env)
+        if err != nil {
+            return nil, err
+        }
+        q.terms = append(q.terms, term)
+    }
+    return q, nil
+}
+
+// conditions - SQL-условия запроса (алиасы t и c, как в TaskFilter)
+func (q *TaskQuery) conditions(args *[]interface{}) []string {
+    arg := func(v interface{}) string {
+        *args = append(*args, v)
+        return fmt.Sprintf("$%d", len(*args))
+    }
+    conds := make([]string, 0, len(q.terms))
+    for _, term := range q.terms {
+        cond := term.sql(arg)
+        if term.negate {
+            // NULL в условии (нет дедлайна, нет исполнителя) при отрицании дает "подходит"
+            cond = "NOT COALESCE((" + cond + "), FALSE)"
+        }
+        conds = append(conds, cond)
+    }
+    return conds
+}
+
+// queryToken - условие запроса: поле, значение и признак отрицания. field = "" - слово без поля
+type queryToken struct {
+    raw    string
+    negate bool
+    field  string
+    value  string
+    quoted bool // значение было в кавычках: не делится по запятым и не понимается как оператор
+}
+
+// splitQuery - делит запрос на условия по пробелам; "в кавычках" пробелы допустимы
+func splitQuery(s string) ([]queryToken, error) {
+    var tokens []queryToken
+    runes := []rune(s)
+    for i := 0; i < len(runes); {
+        if unicode.IsSpace(runes[i]) {
+            i++
+            continue
+        }
+        start := i
+        tok := queryToken{}
+        if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+            tok.negate = true
+            i++
+        }
+
+        // Имя поля - буквы до двоеточия; иначе все условие - слово для поиска
+        j := i
+        for j < len(runes) && unicode.IsLetter(runes[j]) {
+            j++
+        }
+        if j > i && j < len(runes) && runes[j] == ':' {
+            tok.field = strings.ToLower(string(runes[i:j]))
+            i = j + 1
+        }
+
+        var value []rune
+        for i < len(runes) && !unicode.IsSpace(runes[i]) {
+            if runes[i] != '"' {
+                value = append(value, runes[i])
+                i++
+                continue
+            }
+            end := i + 1
+            for end < len(runes) && runes[end] != '"' {
+                end++
+            }
+            if end == len(runes) {
+                return nil, &QueryError{string(runes[start:]), "не закрыта кавычка"}
+            }
+            value = append(value, runes[i+1:end]...)
+            tok.quoted = true
+            i = end + 1
+        }
+        tok.raw = string(runes[start:i])
+        tok.value = string(value)
+        if tok.field != "" && tok.value == "" {
+            return nil, &QueryError{tok.raw, "не указано значение"}
+        }
+        if tok.field == "" && tok.value == "" {
+            continue
+        }
+        tokens = append(tokens, tok)
+    }
+    return tokens, nil
+}
+
+// compile - условие запроса в SQL
+func (q *TaskQuery) compile(tok queryToken, env QueryEnv) (queryTerm, error) {
+    term := queryTerm{negate: tok.negate}
+    value := tok.value
+    if !tok.quoted && tok.field != "" && strings.HasPrefix(value, "!") {
+        term.negate = !term.negate
+        value = value[1:]
+    }
+    fail := func(format string, a ...interface{}) (queryTerm, error) {
+        return queryTerm{}, &QueryError{tok.raw, fmt.Sprintf(format, a...)}
+    }
+    values := []string{value}
+    if !tok.quoted {
+        values = splitValues(value)
+    }
+    if len(values) == 0 {
+        return fail("не указано значение")
+    }
+
+    switch tok.field {
+    case "":
+        pattern := "%" + likeEscaper.Replace(value) + "%"
+        term.sql = func(arg func(interface{}) string) string {
+            p := arg(pattern)
+            return "(t.title ILIKE " + p + " OR COALESCE(t.description, '') ILIKE " + p + ")"
+        }
+
+    case "status":
+        term.sql = func(arg func(interface{}) string) string {
+            return "c.slug = ANY(" + arg(pq.Array(values)) + ")"
+        }
+
+    case "assignee":
+        names := make([]string, len(values))
+        for i, v := range values {
+            switch strings.ToLower(v) {
+            case "me":
+                if env.User == "" {
+                    return fail("me - неизвестно, кто вы: укажите заголовок X-User")
+                }
+                names[i] = env.User
+            case "none":
+                names[i] = ""
+            default:
+                names[i] = v
+            }
+        }
+        term.sql = func(arg func(interface{}) string) string {
+            return "COALESCE(t.assignee, '') = ANY(" + arg(pq.Array(names)) + ")"
+        }
+
+    case "priority":
+        if op, rest := splitOperator(value); op != "" && !tok.quoted {
+            rank, ok := priorityRank[strings.ToLower(rest)]
+            if !ok {
+                return fail("приоритет - low, medium, high или none")
+            }
+            term.sql = func(arg func(interface{}) string) string {
+                return priorityRankSQL + " " + op + " " + arg(rank)
+            }
+            break
+        }
+        ranks := make([]int64, len(values))
+        for i, v := range values {
+            rank, ok := priorityRank[strings.ToLower(v)]
+            if !ok {
+                return fail("приоритет - low, medium, high или none")
+            }
+            ranks[i] = int64(rank)
+        }
+        term.sql = func(arg func(interface{}) string) string {
+            return priorityRankSQL + " = ANY(" + arg(pq.Array(ranks)) + ")"
+        }
+
+    case "tag":
+        tags := make([]string, len(values))
+        for i, v := range values {
+            tags[i] = strings.ToLower(v)
+        }
+        term.sql = func(arg func(interface{}) string) string {
+            return `EXISTS (SELECT 1 FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id
+                WHERE tt.task_id = t.id AND LOWER(tg.name) = ANY(` + arg(pq.Array(tags)) + `))`
+        }
+
+    case "board":
+        ids := make([]int64, len(values))
+        for i, v := range values {
+            id, err := strconv.ParseInt(v, 10, 64)
+            if err != nil {
+                return fail("board - ID доски")
+            }
+            ids[i] = id
+        }
+        term.sql = func(arg func(interface{}) string) string {
+            return "t.board_id = ANY(" + arg(pq.Array(ids)) + ")"
+        }
+
+    case "deadline", "start", "end", "created", "updated":
+        sql, err := dateCondition(dateFields[tok.field], value, env)
+        if err != nil {
+            return fail("%s", err.Error())
+        }
+        term.sql = sql
+
+    case "is":
+        var cond string
+        switch strings.ToLower(value) {
+        case "done":
+            cond = "c.is_done"
+        case "open":
+            cond = "NOT c.is_done"
+        case "overdue":
+            now := env.Now.UTC()
+            term.sql = func(arg func(interface{}) string) string {
+                return "(NOT c.is_done AND t.deadline < " + arg(now) + ")"
+            }
+            return term, nil
+        case "recurring":
+            cond = "t.rrule IS NOT NULL"
+        case "blocked":
+            cond = blockedCondition
+        case "archived":
+            q.archived = true
+            cond = "t.archived_at IS NOT NULL"
+        default:
+            return fail("is - done, open, overdue, recurring, blocked или archived")
+        }
+        term.sql = func(func(interface{}) string) string { return cond }
+
+    case "has":
+        var cond string
+        switch strings.ToLower(value) {
+        case "deadline":
+            cond = "t.deadline IS NOT NULL"
+        case "assignee":
+            cond = "COALESCE(t.assignee, '') <> ''"
+        case "description":
+            cond = "COALESCE(t.description, '') <> ''"
+        case "tags", "tag":
+            cond = "EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = t.id)"
+        default:
+            return fail("has - deadline, assignee, description или tags")
+        }
+        term.sql = func(func(interface{}) string) string { return cond }
+
+    default:
+        return fail("неизвестное поле, доступны: %s", strings.Join(queryFields, ", "))
+    }
+    return term, nil
+}
+
+// splitValues - значения через запятую без пустых
+func splitValues(s string) []string {
+    var values []string
+    for _, v := range strings.Split(s, ",") {
+        if v = strings.TrimSpace(v); v != "" {
+            values = append(values, v)
+        }
+    }
+    return values
+}
+
+// splitOperator - оператор сравнения в начале значения: <now, >=2026-01-01
+func splitOperator(s string) (string, string) {
+    for _, op := range []string{"<=", ">=", "<", ">", "="} {
+        if strings.HasPrefix(s, op) {
+            return op, s[len(op):]
+        }
+    }
+    return "", s
+}
+
+// dateCondition - сравнение даты: deadline:<now, created:>=-7d, deadline:today, deadline:none.
+// Дата без времени (today, 2026-01-20) - целые сутки в поясе запроса: deadline:today - в течение дня,
+// deadline:<today - до его начала, deadline:<=today - до его конца
+func dateCondition(column, value string, env QueryEnv) (func(arg func(interface{}) string) string, error) {
+    op, rest := splitOperator(value)
+    if strings.EqualFold(rest, "none") && op == "" {
+        return func(func(interface{}) string) string { return column + " IS NULL" }, nil
+    }
+
+    var v datetime.Value
+    if strings.EqualFold(rest, "now") {
+        v = datetime.Value{Time: env.Now.UTC()}
+    } else {
+        var err error
+        v, err = datetime.Parse(rest, env.Zone, env.Now)
+        if err != nil {
+            return nil, err
+        }
+    }
+
+    if !v.AllDay {
+        if op == "" || op == "=" {
+            return nil, fmt.Errorf("для момента времени укажите сравнение: <, <=, > или >=")
+        }
+        return func(arg func(interface{}) string) string {
+            return column + " " + op + " " + arg(v.Time)
+        }, nil
+    }
+
+    local := v.Time.In(env.Zone)
+    dayStart := v.Time
+    dayEnd := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, env.Zone).UTC()
+    return func(arg func(interface{}) string) string {
+        switch op {
+        case "<":
+            return column + " < " + arg(dayStart)
+        case "<=":
+            return column + " < " + arg(dayEnd)
+        case ">":
+            return column + " >= " + arg(dayEnd)
+        case ">=":
+            return column + " >= " + arg(dayStart)
+        default:
+            return "(" + column + " >= " + arg(dayStart) + " AND " + column + " < " + arg(dayEnd) + ")"
+        }
+    }, nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+    "errors"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+    _ "time/tzdata"
+    "github.com/lib/pq"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+    moscow, err := time.LoadLocation("Europe/Moscow")
+    if err != nil {
+        t.Fatal(err)
+    }
+    env := QueryEnv{User: "anna", Zone: moscow, Now: time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)}
+    // Начало и конец 20.01.2026 по Москве в UTC
+    dayStart := time.Date(2026, 1, 19, 21, 0, 0, 0, time.UTC)
+    dayEnd := time.Date(2026, 1, 20, 21, 0, 0, 0, time.UTC)
+
+    tests := []struct {
+        query    string
+        conds    []string
+        args     []interface{}
+        archived bool
+    }{
+        {query: "", conds: []string{}},
+        {
+            query: "status:todo,in_progress",
+            conds: []string{"c.slug = ANY($1)"},
+            args:  []interface{}{pq.Array([]string{"todo", "in_progress"})},
+        },
+        {
+            query: "status:!done",
+            conds: []string{"NOT COALESCE((c.slug = ANY($1)), FALSE)"},
+            args:  []interface{}{pq.Array([]string{"done"})},
+        },
+        {
+            query: "-assignee:me",
+            conds: []string{"NOT COALESCE((COALESCE(t.assignee, '') = ANY($1)), FALSE)"},
+            args:  []interface{}{pq.Array([]string{"anna"})},
+        },
+        {
+            query: "assignee:none,Boris",
+            conds: []string{"COALESCE(t.assignee, '') = ANY($1)"},
+            args:  []interface{}{pq.Array([]string{"", "Boris"})},
+        },
+        {
+            query: "priority:>=medium",
+            conds: []string{priorityRankSQL + " >= $1"},
+            args:  []interface{}{2},
+        },
+        {
+            query: "priority:high,none",
+            conds: []string{priorityRankSQL + " = ANY($1)"},
+            args:  []interface{}{pq.Array([]int64{3, 0})},
+        },
+        {
+            query: "board:3,7",
+            conds: []string{"t.board_id = ANY($1)"},
+            args:  []interface{}{pq.Array([]int64{3, 7})},
+        },
+        {
+            query: `"отчет 50%"`,
+            conds: []string{"(t.title ILIKE $1 OR COALESCE(t.description, '') ILIKE $1)"},
+            args:  []interface{}{`%отчет 50\%%`},
+        },
+        {
+            query: "deadline:<now",
+            conds: []string{"t.deadline < $1"},
+            args:  []interface{}{env.Now},
+        },
+        {
+            query: "deadline:today",
+            conds: []string{"(t.deadline >= $1 AND t.deadline < $2)"},
+            args:  []interface{}{dayStart, dayEnd},
+        },
+        {
+            query: "deadline:<=2026-01-20",
+            conds: []string{"t.deadline < $1"},
+            args:  []interface{}{dayEnd},
+        },
+        {
+            query: "deadline:>2026-01-20",
+            conds: []string{"t.deadline >= $1"},
+            args:  []interface{}{dayEnd},
+        },
+        {
+            query: "deadline:none",
+            conds: []string{"t.deadline IS NULL"},
+        },
+        {
+            query: "is:open has:deadline",
+            conds: []string{"NOT c.is_done", "t.deadline IS NOT NULL"},
+        },
+        {
+            query: "is:overdue",
+            conds: []string{"(NOT c.is_done AND t.deadline < $1)"},
+            args:  []interface{}{env.Now},
+        },
+        {
+            query:    "is:archived",
+            conds:    []string{"t.archived_at IS NOT NULL"},
+            archived: true,
+        },
+        {
+            query: "status:todo tag:Backend",
+            conds: []string{
+                "c.slug = ANY($1)",
+                `EXISTS (SELECT 1 FROM task_tags tt JOIN tags tg ON tg.id = tt.tag_id
+                WHERE tt.task_id = t.id AND LOWER(tg.name) = ANY($2))`,
+            },
+            args: []interface{}{pq.Array([]string{"todo"}), pq.Array([]string{"backend"})},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.query, func(t *testing.T) {
+            q, err := ParseTaskQuery(tt.query, env)
+            if err != nil {
+                t.Fatalf("ParseTaskQuery(%q): %v", tt.query, err)
+            }
+            var args []interface{}
+            conds := q.conditions(&args)
+            if !reflect.DeepEqual(conds, tt.conds) {
+                t.Fatalf("условия %q, ожидалось %q", conds, tt.conds)
+            }
+            if !reflect.DeepEqual(args, tt.args) {
+                t.Fatalf("аргументы %#v, ожидалось %#v", args, tt.args)
+            }
+            if q.archived != tt.archived {
+                t.Fatalf("archived = %v, ожидалось %v", q.archived, tt.archived)
+            }
+        })
+    }
+}
+
+func TestParseTaskQueryErrors(t *testing.T) {
+    env := QueryEnv{Zone: time.UTC, Now: time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)}
+    tests := []struct {
+        query   string
+        token   string
+        message string
+    }{
+        {`"не закрыта`, `"не закрыта`, "не закрыта кавычка"},
+        {"status:", "status:", "не указано значение"},
+        {"status:,", "status:,", "не указано значение"},
+        {"color:red", "color:red", "неизвестное поле"},
+        {"assignee:me", "assignee:me", "X-User"},
+        {"priority:urgent", "priority:urgent", "приоритет"},
+        {"priority:>urgent", "priority:>urgent", "приоритет"},
+        {"board:main", "board:main", "ID доски"},
+        {"is:late", "is:late", "is - "},
+        {"has:files", "has:files", "has - "},
+        {"deadline:now", "deadline:now", "укажите сравнение"},
+        {"deadline:вчера-завтра", "deadline:вчера-завтра", ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.query, func(t *testing.T) {
+            q, err := ParseTaskQuery(tt.query, env)
+            if err == nil {
+                t.Fatalf("ParseTaskQuery(%q) = %+v, ожидалась ошибка", tt.query, q)
+            }
+            var qerr *QueryError
+            if !errors.As(err, &qerr) {
+                t.Fatalf("ошибка %T, ожидалась *QueryError: %v", err, err)
+            }
+            if qerr.Token != tt.token {
+                t.Fatalf("Token = %q, ожидалось %q", qerr.Token, tt.token)
+            }
+            if !strings.Contains(qerr.Message, tt.message) {
+                t.Fatalf("Message = %q, ожидалось с %q", qerr.Message, tt.message)
+            }
+        })
+    }
+}
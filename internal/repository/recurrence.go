@@ -0,0 +1,178 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/recurrence"
+    "time"
+)
+
+// encodeExDates - исключенные даты хранятся строками RFC3339 в UTC
+func encodeExDates(dates []time.Time) []string {
+    result := make([]string, 0, len(dates))
+    for _, d := range dates {
+        result = append(result, d.UTC().Format(time.RFC3339))
+    }
+    return result
+}
+
+// decodeExDates - обратное преобразование; нечитаемые строки пропускаются
+func decodeExDates(values []string) []time.Time {
+    var result []time.Time
+    for _, v := range values {
+        if d, err := time.Parse(time.RFC3339, v); err == nil {
+            result = append(result, d)
+        }
+    }
+    return result
+}
+
+// recurrenceAnchor - от какой даты разворачивается серия: начало, иначе дедлайн
+func recurrenceAnchor(task *models.Task) *time.Time {
+    if task.StartDate != nil {
+        return task.StartDate
+    }
+    return task.Deadline
+}
+
+// spawnIfCompleted - если повторяющаяся задача только что попала в колонку "выполнено",
+// создает следующее вхождение серии в первой невыполненной колонке той же доски
+func spawnIfCompleted(ctx context.Context, tx *sql.Tx, loc *time.Location, taskID int, wasDone bool) error {
+    if wasDone {
+        return nil
+    }
+
+    task, err := scanTask(tx.QueryRowContext(ctx, taskSelect+` WHERE t.id = $1`, taskID))
+    if err != nil {
+        return err
+    }
+    if !task.IsDone || task.RRule == "" {
+        return nil
+    }
+
+    next, err := nextOccurrence(task, loc)
+    if err != nil || next == nil {
+        return err
+    }
+
+    seriesID := task.SeriesID
+    if seriesID == 0 {
+        seriesID = task.ID
+    }
+
+    // Повторное закрытие того же вхождения не должно плодить дубли
+    var exists bool
+    query := `
+        SELECT EXISTS (
+            SELECT 1 FROM tasks
+            WHERE (id = $1 OR series_id = $1) AND COALESCE(start_date, deadline) = $2
+        )
+    `
+    if err := tx.QueryRowContext(ctx, query, seriesID, next.anchor).Scan(&exists); err != nil {
+        return err
+    }
+    if exists {
+        return nil
+    }
+
+    query = columnSelect + ` WHERE board_id = $1 AND NOT is_done ORDER BY position, id LIMIT 1`
+    col, err := scanColumn(tx.QueryRowContext(ctx, query, task.BoardID))
+    if err != nil {
+        if err == sql.ErrNoRows {
+            return nil // на доске нет невыполненных колонок - продолжать серию некуда
+        }
+        return err
+    }
+
+    shift := func(t *time.Time) *time.Time {
+        if t == nil {
+            return nil
+        }
+        moved := t.Add(next.shift)
+        return &moved
+    }
+
+    spawned := &models.Task{
+        Title:             task.Title,
+        Description:       task.Description,
+        Priority:          task.Priority,
+        Deadline:          shift(task.Deadline),
+        StartDate:         shift(task.StartDate),
+        EndDate:           shift(task.EndDate),
+        Assignee:          task.Assignee,
+        Tags:              task.Tags,
+        LastNotifiedHours: 999, // напоминания для нового вхождения отсчитываются заново
+        RRule:             next.rule,
+        ExDates:           task.ExDates,
+        SeriesID:          seriesID,
+        AllDay:            task.AllDay,
+        ParentID:          task.ParentID,
+    }
+    spawned.SetColumn(col)
+
+    // Лимит WIP здесь не проверяем: новое вхождение порождает система, а не пользователь
+    if spawned.Position, err = lockColumnTail(ctx, tx, col.ID, 0); err != nil {
+        return err
+    }
+    if err := insertTask(ctx, tx, spawned); err != nil {
+        return fmt.Errorf("не удалось создать следующее вхождение задачи %d: %w", task.ID, err)
+    }
+    return nil
+}
+
+type occurrence struct {
+    anchor time.Time
+    shift  time.Duration
+    rule   string // правило с уменьшенным COUNT
+}
+
+// nextOccurrence - следующее после текущего вхождение серии; nil - серия закончилась.
+// Серия считается в поясе loc: "каждый понедельник в 10:00" остается 10:00 и после перевода часов
+func nextOccurrence(task *models.Task, loc *time.Location) (*occurrence, error) {
+    if recurrenceAnchor(task) == nil {
+        return nil, nil
+    }
+    anchor := recurrenceAnchor(task).In(loc)
+
+    rule, err := recurrence.Parse(task.RRule)
+    if err != nil {
+        return nil, fmt.Errorf("задача %d: %w", task.ID, err)
+    }
+
+    next, ok := rule.After(anchor, anchor, task.ExDates)
+    if !ok {
+        return nil, nil
+    }
+
+    if rule.Count > 0 {
+        // Вхождения до следующего (включая исключенные) уже израсходованы
+        used := len(rule.Between(anchor, anchor, next.Add(-time.Nanosecond), nil))
+        rule.Count -= used
+        if rule.Count < 1 {
+            return nil, nil
+        }
+    }
+
+    return &occurrence{anchor: next.UTC(), shift: next.Sub(anchor), rule: rule.String()}, nil
+}
+
+// expandRecurring - вхождения повторяющейся задачи, пересекающие окно [from, to]; серия считается в поясе loc
+func expandRecurring(event models.CalendarEvent, rrule string, exdates []time.Time, from, to time.Time, loc *time.Location) []models.CalendarEvent {
+    rule, err := recurrence.Parse(rrule)
+    if err != nil {
+        return []models.CalendarEvent{event}
+    }
+
+    duration := event.End.Sub(event.Start)
+    var result []models.CalendarEvent
+    for _, start := range rule.Between(event.Start.In(loc), from.Add(-duration), to, exdates) {
+        occ := event
+        occ.Start = start.UTC()
+        occ.End = occ.Start.Add(duration)
+        occ.Recurring = true
+        result = append(result, occ)
+    }
+    return result
+}
@@ -0,0 +1,300 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// MaxLagDays - предельный зазор между блокирующей задачей и началом зависимой
+const MaxLagDays = 365
+
+// CascadeOptions - как сдвигать зависимые задачи при изменении дат блокирующей
+type CascadeOptions struct {
+    LagDays     int  // зазор после окончания блокирующей задачи
+    WorkingDays bool // зазор в рабочих днях, даты не попадают на выходные
+    Preview     bool // посчитать сдвиги, ничего не сохраняя
+}
+
+// UpdateTaskCascade - UpdateTask, после которого зависимые задачи (по связям blocks, по всей цепочке)
+// сдвигаются так, чтобы начинаться не раньше окончания своих блокирующих плюс зазор. Задачи только
+// отодвигаются вперед и сохраняют длительность. Все сдвиги - в одной транзакции с изменением;
+// с Preview транзакция откатывается, а task.Rescheduled показывает, что изменилось бы
+func (r *TaskRepository) UpdateTaskCascade(ctx context.Context, task *models.Task, opts CascadeOptions) error {
+    if opts.LagDays < 0 || opts.LagDays > MaxLagDays {
+        return fmt.Errorf("зазор должен быть от 0 до %d дней", MaxLagDays)
+    }
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    if err := r.updateTask(ctx, tx, task); err != nil {
+        return err
+    }
+    if task.Rescheduled, err = r.cascadeDates(ctx, tx, task.ID, opts); err != nil {
+        return err
+    }
+    if opts.Preview {
+        return nil
+    }
+    return tx.Commit()
+}
+
+// cascadeDates - сдвигает задачи ниже rootID по цепочке blocks и возвращает сдвиги в порядке применения
+func (r *TaskRepository) cascadeDates(ctx context.Context, tx *sql.Tx, rootID int, opts CascadeOptions) ([]models.Reschedule, error) {
+    plan := []models.Reschedule{}
+
+    // Задачи ниже по цепочке (граф blocks ацикличен - это проверяет CreateLink)
+    query := `
+        WITH RECURSIVE down(id) AS (
+            SELECT $1::int
+            UNION
+            SELECT l.target_id FROM task_links l JOIN down ON l.source_id = down.id WHERE l.type = 'blocks'
+        )
+        SELECT id FROM down WHERE id <> $1
+    `
+    downstream, err := queryIDs(ctx, tx, query, rootID)
+    if err != nil || len(downstream) == 0 {
+        return plan, err
+    }
+
+    // Все блокирующие для каждой задачи цепочки: сдвиг учитывает и тех, кто вне ее
+    type edge struct{ from, to int }
+    var edges []edge
+    rows, err := tx.QueryContext(ctx, `SELECT source_id, target_id FROM task_links WHERE type = 'blocks' AND target_id = ANY($1) ORDER BY id`, pq.Array(downstream))
+    if err != nil {
+        return nil, err
+    }
+    involved := map[int]bool{rootID: true}
+    for _, id := range downstream {
+        involved[id] = true
+    }
+    for rows.Next() {
+        var e edge
+        if err := rows.Scan(&e.from, &e.to); err != nil {
+            rows.Close()
+            return nil, err
+        }
+        edges = append(edges, e)
+        involved[e.from] = true
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    ids := make([]int, 0, len(involved))
+    for id := range involved {
+        ids = append(ids, id)
+    }
+    rows, err = tx.QueryContext(ctx, taskSelect+` WHERE t.id = ANY($1) ORDER BY t.id FOR UPDATE OF t`, pq.Array(ids))
+    if err != nil {
+        return nil, err
+    }
+    tasks := map[int]*models.Task{}
+    for rows.Next() {
+        task, err := scanTask(rows)
+        if err != nil {
+            rows.Close()
+            return nil, err
+        }
+        tasks[task.ID] = task
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+
+    // Порядок обхода: задача идет после всех своих блокирующих из цепочки
+    inChain := map[int]bool{}
+    for _, id := range downstream {
+        inChain[id] = true
+    }
+    preds := map[int][]int{}
+    pending := map[int]int{}
+    next := map[int][]int{}
+    for _, e := range edges {
+        preds[e.to] = append(preds[e.to], e.from)
+        if inChain[e.from] {
+            pending[e.to]++
+            next[e.from] = append(next[e.from], e.to)
+        }
+    }
+    var queue []int
+    for _, id := range downstream {
+        if pending[id] == 0 {
+            queue = append(queue, id)
+        }
+    }
+
+    for len(queue) > 0 {
+        id := queue[0]
+        queue = queue[1:]
+        for _, n := range next[id] {
+            if pending[n]--; pending[n] == 0 {
+                queue = append(queue, n)
+            }
+        }
+
+        task := tasks[id]
+        if task == nil || !shiftable(task) {
+            continue
+        }
+        anchor := scheduleAnchor(task)
+        if anchor == nil {
+            continue
+        }
+
+        // Самое раннее начало - после самой поздней из блокирующих
+        var earliest time.Time
+        causedBy := 0
+        for _, p := range preds[id] {
+            pred := tasks[p]
+            if pred == nil || !constrains(pred) {
+                continue
+            }
+            start := r.earliestStart(*scheduleFinish(pred), opts)
+            if start.After(earliest) {
+                earliest, causedBy = start, p
+            }
+        }
+        if causedBy == 0 || !anchor.Before(earliest) {
+            continue
+        }
+
+        before := *task
+        delta := r.shiftTask(task, earliest.Sub(*anchor), opts)
+
+        changes, err := taskChanges(&before, task)
+        if err != nil {
+            return nil, err
+        }
+        for field := range changes {
+            if field != "start_date" && field != "end_date" && field != "deadline" {
+                delete(changes, field)
+            }
+        }
+        plan = append(plan, models.Reschedule{
+            TaskID:    id,
+            Title:     task.Title,
+            CausedBy:  causedBy,
+            ShiftDays: float64(delta) / float64(24*time.Hour),
+            Changes:   changes,
+        })
+
+        query := `
+            UPDATE tasks SET start_date = $1, end_date = $2, deadline = $3,
+                version = version + 1, updated_at = CURRENT_TIMESTAMP
+            WHERE id = $4
+        `
+        if _, err := tx.ExecContext(ctx, query, task.StartDate, task.EndDate, task.Deadline, id); err != nil {
+            return nil, err
+        }
+        after, err := loadTaskTx(ctx, tx, id)
+        if err != nil {
+            return nil, err
+        }
+        if err := recordEvent(ctx, tx, models.EventUpdate, &before, after); err != nil {
+            return nil, err
+        }
+    }
+    return plan, nil
+}
+
+// shiftable - задачу можно сдвигать: она не выполнена, не в архиве и не в корзине
+func shiftable(task *models.Task) bool {
+    return !task.IsDone && task.ArchivedAt == nil && task.DeletedAt == nil
+}
+
+// constrains - блокирующая задача ограничивает начало зависимых (так же, как она их блокирует)
+func constrains(task *models.Task) bool {
+    return shiftable(task) && scheduleFinish(task) != nil
+}
+
+// scheduleFinish - окончание задачи: end_date, иначе дедлайн
+func scheduleFinish(task *models.Task) *time.Time {
+    if task.EndDate != nil {
+        return task.EndDate
+    }
+    return task.Deadline
+}
+
+// scheduleAnchor - дата, по которой задача сравнивается с окончанием блокирующих: начало,
+// а без него - окончание
+func scheduleAnchor(task *models.Task) *time.Time {
+    if task.StartDate != nil {
+        return task.StartDate
+    }
+    return scheduleFinish(task)
+}
+
+// earliestStart - самое раннее начало после окончания блокирующей задачи с зазором
+func (r *TaskRepository) earliestStart(finish time.Time, opts CascadeOptions) time.Time {
+    if !opts.WorkingDays {
+        return finish.AddDate(0, 0, opts.LagDays)
+    }
+    t := finish.In(r.zone)
+    for i := 0; i < opts.LagDays; {
+        t = t.AddDate(0, 0, 1)
+        if !weekend(t) {
+            i++
+        }
+    }
+    return nextWorkingDay(t).UTC()
+}
+
+// shiftTask - сдвигает даты задачи на delta с сохранением длительности и возвращает итоговый сдвиг.
+// В рабочих днях на рабочий день переносится опорная дата (scheduleAnchor), а остальные даты
+// сдвигаются на тот же итоговый сдвиг: начало не уходит за окончание
+func (r *TaskRepository) shiftTask(task *models.Task, delta time.Duration, opts CascadeOptions) time.Duration {
+    if anchor := scheduleAnchor(task); anchor != nil && opts.WorkingDays {
+        delta = nextWorkingDay(anchor.Add(delta).In(r.zone)).UTC().Sub(*anchor)
+    }
+    shift := func(t *time.Time) *time.Time {
+        if t == nil {
+            return nil
+        }
+        shifted := t.Add(delta)
+        return &shifted
+    }
+    task.StartDate = shift(task.StartDate)
+    task.EndDate = shift(task.EndDate)
+    task.Deadline = shift(task.Deadline)
+    return delta
+}
+
+func weekend(t time.Time) bool {
+    return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// nextWorkingDay - t или то же время ближайшего следующего рабочего дня
+func nextWorkingDay(t time.Time) time.Time {
+    for weekend(t) {
+        t = t.AddDate(0, 0, 1)
+    }
+    return t
+}
+
+// queryIDs - столбец ID из запроса в транзакции
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]int, error) {
+    rows, err := tx.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var ids []int
+    for rows.Next() {
+        var id int
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+    "testing"
+    "time"
+    "kanban-calendar/internal/models"
+)
+
+func TestShiftTask(t *testing.T) {
+    r := &TaskRepository{zone: time.UTC}
+    day := func(d int) *time.Time {
+        t := time.Date(2026, 1, d, 9, 0, 0, 0, time.UTC) // 1 января 2026 - четверг
+        return &t
+    }
+    tests := []struct {
+        name        string
+        task        models.Task
+        delta       time.Duration
+        workingDays bool
+        want        models.Task
+        wantDays    int
+    }{
+        {
+            name:     "календарные дни: сдвиг как есть",
+            task:     models.Task{StartDate: day(5), EndDate: day(7), Deadline: day(8)},
+            delta:    5 * 24 * time.Hour,
+            want:     models.Task{StartDate: day(10), EndDate: day(12), Deadline: day(13)},
+            wantDays: 5,
+        },
+        {
+            name:        "начало попадает на субботу: вся задача переезжает на понедельник",
+            task:        models.Task{StartDate: day(5), EndDate: day(8), Deadline: day(9)},
+            delta:       5 * 24 * time.Hour,
+            workingDays: true,
+            want:        models.Task{StartDate: day(12), EndDate: day(15), Deadline: day(16)},
+            wantDays:    7,
+        },
+        {
+            name:        "на выходной попадает только окончание: длительность сохраняется",
+            task:        models.Task{StartDate: day(5), EndDate: day(7)},
+            delta:       3 * 24 * time.Hour,
+            workingDays: true,
+            want:        models.Task{StartDate: day(8), EndDate: day(10)},
+            wantDays:    3,
+        },
+        {
+            name:        "без начала опорная дата - окончание",
+            task:        models.Task{EndDate: day(9), Deadline: day(10)},
+            delta:       24 * time.Hour,
+            workingDays: true,
+            want:        models.Task{EndDate: day(12), Deadline: day(13)},
+            wantDays:    3,
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            task := tt.task
+            delta := r.shiftTask(&task, tt.delta, CascadeOptions{WorkingDays: tt.workingDays})
+            if delta != time.Duration(tt.wantDays)*24*time.Hour {
+                t.Fatalf("сдвиг %v, ожидалось %d дн.", delta, tt.wantDays)
+            }
+            for _, f := range []struct {
+                what      string
+                got, want *time.Time
+            }{
+                {"начало", task.StartDate, tt.want.StartDate},
+                {"окончание", task.EndDate, tt.want.EndDate},
+                {"дедлайн", task.Deadline, tt.want.Deadline},
+            } {
+                if (f.got == nil) != (f.want == nil) || (f.got != nil && !f.got.Equal(*f.want)) {
+                    t.Fatalf("%s = %v, ожидалось %v", f.what, f.got, f.want)
+                }
+            }
+        })
+    }
+}
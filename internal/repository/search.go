@@ -0,0 +1,147 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "html"
+    "strings"
+    "unicode"
+    "kanban-calendar/internal/models"
+    "github.com/lib/pq"
+)
+
+// Размер выдачи поиска
+const (
+    DefaultSearchLimit = 20
+    MaxSearchLimit     = 100
+    maxSearchTerms     = 16
+)
+
+// Маркеры подсветки из ts_headline (символы частной области Unicode не встречаются в тексте задач):
+// текст экранируется целиком, и только потом маркеры заменяются на <mark>
+const (
+    markStart = "\uE000"
+    markStop  = "\uE001"
+)
+
+// Параметры ts_headline для заголовка (подсвечивается целиком) и для фрагментов описания
+const (
+    titleHeadline   = `'HighlightAll=true, StartSel=` + markStart + `, StopSel=` + markStop + `'`
+    snippetHeadline = `'MaxFragments=2, MaxWords=25, MinWords=8, FragmentDelimiter=" … ", StartSel=` + markStart + `, StopSel=` + markStop + `'`
+)
+
+// SearchTerms - слова поискового запроса: только буквы и цифры, синтаксис tsquery из ввода не попадает в SQL
+func SearchTerms(q string) []string {
+    terms := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+    if len(terms) > maxSearchTerms {
+        terms = terms[:maxSearchTerms]
+    }
+    return terms
+}
+
+// prefixQuery - все слова обязательны, каждое - как префикс: "отч" найдет "отчет" и "отчеты"
+func prefixQuery(terms []string) string {
+    return strings.Join(terms, ":* & ") + ":*"
+}
+
+// highlight - результат ts_headline в безопасный HTML с <mark>
+func highlight(s string) string {
+    s = html.EscapeString(s)
+    return strings.NewReplacer(markStart, "<mark>", markStop, "</mark>").Replace(s)
+}
+
+// bestHeadline - подсветка той конфигурации, которая нашла больше слов: русские слова
+// подсвечивает russian, английские - english, а совпасть запрос мог по любой из них
+func bestHeadline(russian, english string) string {
+    if strings.Count(english, markStart) > strings.Count(russian, markStart) {
+        return english
+    }
+    return russian
+}
+
+// SearchTasks - задачи, подходящие под запрос q и фильтр, по убыванию релевантности.
+// Запрос без слов дает пустой результат
+func (r *TaskRepository) SearchTasks(ctx context.Context, q string, filter TaskFilter, limit, offset int) ([]models.SearchResult, error) {
+    terms := SearchTerms(q)
+    if len(terms) == 0 {
+        return []models.SearchResult{}, nil
+    }
+    if limit <= 0 {
+        limit = DefaultSearchLimit
+    }
+    if limit > MaxSearchLimit {
+        limit = MaxSearchLimit
+    }
+
+    // Слово ищется в обеих конфигурациях: русская основа или английская
+    args := []interface{}{prefixQuery(terms)}
+    conds := append([]string{"t.search_vector @@ q.query"}, filter.conditions(&args)...)
+    args = append(args, limit, offset)
+    n := len(args)
+
+    // Подсветка считается только для выданной страницы, а не для всех совпадений, и в обеих
+    // конфигурациях - той же, по которой слово нашлось (см. bestHeadline)
+    query := `
+        WITH q AS (SELECT to_tsquery('russian', $1) || to_tsquery('english', $1) AS query),
+        found AS (
+            SELECT t.id, t.title, COALESCE(t.description, '') AS description,
+                   ts_rank_cd(t.search_vector, q.query) AS rank, t.updated_at
+            FROM tasks t
+            JOIN board_columns c ON c.id = t.column_id
+            CROSS JOIN q` + whereClause(conds) + `
+            ORDER BY rank DESC, t.updated_at DESC, t.id
+            ` + fmt.Sprintf("LIMIT $%d OFFSET $%d", n-1, n) + `
+        )
+        SELECT f.id, f.rank,
+               ts_headline('russian', f.title, q.query, ` + titleHeadline + `),
+               ts_headline('english', f.title, q.query, ` + titleHeadline + `),
+               CASE WHEN f.description = '' THEN '' ELSE ts_headline('russian', f.description, q.query, ` + snippetHeadline + `) END,
+               CASE WHEN f.description = '' THEN '' ELSE ts_headline('english', f.description, q.query, ` + snippetHeadline + `) END
+        FROM found f CROSS JOIN q
+        ORDER BY f.rank DESC, f.updated_at DESC, f.id
+    `
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    results := []models.SearchResult{}
+    var ids []int64
+    for rows.Next() {
+        var res models.SearchResult
+        var titleRU, titleEN, snippetRU, snippetEN string
+        if err := rows.Scan(&res.Task.ID, &res.Rank, &titleRU, &titleEN, &snippetRU, &snippetEN); err != nil {
+            return nil, err
+        }
+        res.Title = highlight(bestHeadline(titleRU, titleEN))
+        res.Snippet = highlight(bestHeadline(snippetRU, snippetEN))
+        results = append(results, res)
+        ids = append(ids, int64(res.Task.ID))
+    }
+    if err := rows.Err(); err != nil {
+        return nil, err
+    }
+    if len(ids) == 0 {
+        return results, nil
+    }
+
+    tasks, err := r.queryTasks(ctx, taskSelect+" WHERE t.id = ANY($1)", pq.Array(ids))
+    if err != nil {
+        return nil, err
+    }
+    byID := make(map[int]models.Task, len(tasks))
+    for _, task := range tasks {
+        byID[task.ID] = task
+    }
+    found := results[:0]
+    for _, res := range results {
+        if task, ok := byID[res.Task.ID]; ok { // задачу могли удалить между запросами
+            res.Task = task
+            found = append(found, res)
+        }
+    }
+    return found, nil
+}
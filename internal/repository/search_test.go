@@ -0,0 +1,25 @@
+package repository
+
+import "testing"
+
+func TestSearchHeadline(t *testing.T) {
+    mark := func(s string) string { return markStart + s + markStop }
+    tests := []struct {
+        name             string
+        russian, english string
+        want             string
+    }{
+        {"русское слово", "Сдать " + mark("отчет"), "Сдать отчет", "Сдать <mark>отчет</mark>"},
+        {"английское слово", "Fix login", mark("Fix") + " login", "<mark>Fix</mark> login"},
+        {"поровну - русская", mark("API") + " отчет", mark("API") + " отчет", "<mark>API</mark> отчет"},
+        {"без совпадений", "Сдать отчет", "Сдать отчет", "Сдать отчет"},
+        {"HTML экранируется", "<b>" + mark("отчет") + "</b>", "<b>отчет</b>", "&lt;b&gt;<mark>отчет</mark>&lt;/b&gt;"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := highlight(bestHeadline(tt.russian, tt.english)); got != tt.want {
+                t.Fatalf("подсветка %q, ожидалось %q", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "kanban-calendar/internal/models"
+)
+
+const subscriptionSelect = `
+    SELECT id, name, url, refresh_minutes, board_id, default_assignee, archive_missing, enabled,
+           etag, last_modified, last_sync_at, last_success_at, last_status, last_error, last_report,
+           next_sync_at, created_at, updated_at
+    FROM calendar_subscriptions
+`
+
+func scanSubscription(row rowScanner) (*models.CalendarSubscription, error) {
+    sub := &models.CalendarSubscription{}
+    var lastSync, lastSuccess sql.NullTime
+    var report []byte
+    err := row.Scan(
+        &sub.ID, &sub.Name, &sub.URL, &sub.RefreshMinutes, &sub.BoardID, &sub.DefaultAssignee,
+        &sub.ArchiveMissing, &sub.Enabled, &sub.ETag, &sub.LastModified, &lastSync, &lastSuccess,
+        &sub.LastStatus, &sub.LastError, &report, &sub.NextSyncAt, &sub.CreatedAt, &sub.UpdatedAt,
+    )
+    if err != nil {
+        return nil, err
+    }
+    if lastSync.Valid {
+        sub.LastSyncAt = &lastSync.Time
+    }
+    if lastSuccess.Valid {
+        sub.LastSuccessAt = &lastSuccess.Time
+    }
+    if len(report) > 0 {
+        sub.LastReport = json.RawMessage(report)
+    }
+    return sub, nil
+}
+
+func (r *TaskRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]models.CalendarSubscription, error) {
+    rows, err := r.db.QueryContext(ctx, query, args...)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    subs := []models.CalendarSubscription{}
+    for rows.Next() {
+        sub, err := scanSubscription(rows)
+        if err != nil {
+            return nil, err
+        }
+        subs = append(subs, *sub)
+    }
+    return subs, rows.Err()
+}
+
+// GetSubscriptions - все подписки с состоянием последней синхронизации
+func (r *TaskRepository) GetSubscriptions(ctx context.Context) ([]models.CalendarSubscription, error) {
+    return r.querySubscriptions(ctx, subscriptionSelect+` ORDER BY id`)
+}
+
+// GetDueSubscriptions - включенные подписки, которым пора синхронизироваться
+func (r *TaskRepository) GetDueSubscriptions(ctx context.Context) ([]models.CalendarSubscription, error) {
+    return r.querySubscriptions(ctx, subscriptionSelect+` WHERE enabled AND next_sync_at <= NOW() ORDER BY next_sync_at`)
+}
+
+// GetSubscriptionByID - подписка по ID
+func (r *TaskRepository) GetSubscriptionByID(ctx context.Context, id int) (*models.CalendarSubscription, error) {
+    sub, err := scanSubscription(r.db.QueryRowContext(ctx, subscriptionSelect+` WHERE id = $1`, id))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("подписка с ID %d: %w", id, ErrNotFound)
+    }
+    return sub, err
+}
+
+// CreateSubscription - создает подписку; первая синхронизация - при ближайшем проходе планировщика
+func (r *TaskRepository) CreateSubscription(ctx context.Context, sub *models.CalendarSubscription) error {
+    query := `
+        INSERT INTO calendar_subscriptions (name, url, refresh_minutes, board_id, default_assignee, archive_missing, enabled)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id, next_sync_at, created_at, updated_at
+    `
+    return r.db.QueryRowContext(ctx, query,
+        sub.Name, sub.URL, sub.RefreshMinutes, sub.BoardID, sub.DefaultAssignee, sub.ArchiveMissing, sub.Enabled,
+    ).Scan(&sub.ID, &sub.NextSyncAt, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+// UpdateSubscription - сохраняет настройки подписки. Смена URL сбрасывает валидаторы кэша
+func (r *TaskRepository) UpdateSubscription(ctx context.Context, sub *models.CalendarSubscription) error {
+    query := `
+        UPDATE calendar_subscriptions
+        SET name = $1, refresh_minutes = $3, board_id = $4, default_assignee = $5,
+            archive_missing = $6, enabled = $7,
+            etag = CASE WHEN url = $2 THEN etag ELSE '' END,
+            last_modified = CASE WHEN url = $2 THEN last_modified ELSE '' END,
+            next_sync_at = CASE WHEN url = $2 THEN next_sync_at ELSE NOW() END,
+            url = $2
+        WHERE id = $8
+        RETURNING next_sync_at, updated_at
+    `
+    err := r.db.QueryRowContext(ctx, query,
+        sub.Name, sub.URL, sub.RefreshMinutes, sub.BoardID, sub.DefaultAssignee, sub.ArchiveMissing, sub.Enabled, sub.ID,
+    ).Scan(&sub.NextSyncAt, &sub.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("подписка с ID %d: %w", sub.ID, ErrNotFound)
+    }
+    return err
+}
+
+// DeleteSubscription - удаляет подписку; импортированные задачи остаются
+func (r *TaskRepository) DeleteSubscription(ctx context.Context, id int) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM calendar_subscriptions WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("подписка с ID %d: %w", id, ErrNotFound)
+    }
+    return nil
+}
+
+// RecordSubscriptionSync - сохраняет итог синхронизации и планирует следующую через refresh_minutes.
+// При ошибке валидаторы кэша и отчет прошлой успешной синхронизации не трогаются
+func (r *TaskRepository) RecordSubscriptionSync(ctx context.Context, id int, res models.SubscriptionSync) error {
+    var report []byte
+    if res.Report != nil {
+        var err error
+        if report, err = json.Marshal(res.Report); err != nil {
+            return err
+        }
+    }
+
+    query := `
+        UPDATE calendar_subscriptions
+        SET last_sync_at = NOW(),
+            last_status = $2,
+            last_error = $3,
+            last_success_at = CASE WHEN $2 = 'error' THEN last_success_at ELSE NOW() END,
+            etag = CASE WHEN $2 = 'ok' THEN $4 ELSE etag END,
+            last_modified = CASE WHEN $2 = 'ok' THEN $5 ELSE last_modified END,
+            last_report = COALESCE($6::jsonb, last_report),
+            next_sync_at = NOW() + INTERVAL '1 minute' * refresh_minutes
+        WHERE id = $1
+    `
+    _, err := r.db.ExecContext(ctx, query, id, res.Status, res.Error, res.ETag, res.LastModified, nullableJSON(report))
+    return err
+}
+
+// nullableJSON - пустой JSON передается как NULL
+func nullableJSON(b []byte) interface{} {
+    if len(b) == 0 {
+        return nil
+    }
+    return string(b)
+}
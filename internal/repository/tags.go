@@ -0,0 +1,119 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "strings"
+    "kanban-calendar/internal/models"
+)
+
+// NormalizeTags - убирает пробелы, пустые значения и дубликаты (без учета регистра)
+func NormalizeTags(tags []string) []string {
+    seen := make(map[string]bool, len(tags))
+    result := make([]string, 0, len(tags))
+    for _, tag := range tags {
+        tag = strings.TrimSpace(tag)
+        key := strings.ToLower(tag)
+        if tag == "" || seen[key] {
+            continue
+        }
+        seen[key] = true
+        result = append(result, tag)
+    }
+    return result
+}
+
+// setTaskTags - заменяет теги задачи, создавая отсутствующие в справочнике
+func setTaskTags(ctx context.Context, tx *sql.Tx, taskID int, tags []string) error {
+    if _, err := tx.ExecContext(ctx, `DELETE FROM task_tags WHERE task_id = $1`, taskID); err != nil {
+        return err
+    }
+
+    for _, name := range NormalizeTags(tags) {
+        _, err := tx.ExecContext(ctx, `INSERT INTO tags (name) VALUES ($1) ON CONFLICT ((LOWER(name))) DO NOTHING`, name)
+        if err != nil {
+            return err
+        }
+
+        query := `
+            INSERT INTO task_tags (task_id, tag_id)
+            SELECT $1, id FROM tags WHERE LOWER(name) = LOWER($2)
+            ON CONFLICT DO NOTHING
+        `
+        if _, err := tx.ExecContext(ctx, query, taskID, name); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// GetTags - все теги с числом задач
+func (r *TaskRepository) GetTags(ctx context.Context) ([]models.Tag, error) {
+    query := `
+        SELECT tg.id, tg.name, COALESCE(tg.color, ''), COUNT(tt.task_id), tg.created_at
+        FROM tags tg
+        LEFT JOIN task_tags tt ON tt.tag_id = tg.id
+            AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = tt.task_id AND t.deleted_at IS NOT NULL)
+        GROUP BY tg.id
+        ORDER BY COUNT(tt.task_id) DESC, tg.name
+    `
+    rows, err := r.db.QueryContext(ctx, query)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    var tags []models.Tag
+    for rows.Next() {
+        var tag models.Tag
+        if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Count, &tag.CreatedAt); err != nil {
+            return nil, err
+        }
+        tags = append(tags, tag)
+    }
+    return tags, rows.Err()
+}
+
+// GetTagByID - тег по ID
+func (r *TaskRepository) GetTagByID(ctx context.Context, id int) (*models.Tag, error) {
+    query := `
+        SELECT tg.id, tg.name, COALESCE(tg.color, ''),
+               (SELECT COUNT(*) FROM task_tags tt JOIN tasks t ON t.id = tt.task_id
+                WHERE tt.tag_id = tg.id AND t.deleted_at IS NULL), tg.created_at
+        FROM tags tg WHERE tg.id = $1
+    `
+    tag := &models.Tag{}
+    err := r.db.QueryRowContext(ctx, query, id).Scan(&tag.ID, &tag.Name, &tag.Color, &tag.Count, &tag.CreatedAt)
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("тег с ID %d: %w", id, ErrNotFound)
+    }
+    return tag, err
+}
+
+// UpdateTag - переименовывает тег или меняет цвет
+func (r *TaskRepository) UpdateTag(ctx context.Context, tag *models.Tag) error {
+    result, err := r.db.ExecContext(ctx,
+        `UPDATE tags SET name = $1, color = NULLIF($2, '') WHERE id = $3`,
+        tag.Name, tag.Color, tag.ID,
+    )
+    if err != nil {
+        return mapUniqueViolation(err, fmt.Sprintf("тег %q", tag.Name))
+    }
+    if rows, _ := result.RowsAffected(); rows == 0 {
+        return fmt.Errorf("тег с ID %d: %w", tag.ID, ErrNotFound)
+    }
+    return nil
+}
+
+// DeleteTag - удаляет тег (со всех задач)
+func (r *TaskRepository) DeleteTag(ctx context.Context, id int) error {
+    result, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = $1`, id)
+    if err != nil {
+        return err
+    }
+    if rows, _ := result.RowsAffected(); rows == 0 {
+        return fmt.Errorf("тег с ID %d: %w", id, ErrNotFound)
+    }
+    return nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "kanban-calendar/internal/models"
+)
+
+// GetTimeZones - все переопределения поясов
+func (r *TaskRepository) GetTimeZones(ctx context.Context) ([]models.TimeZone, error) {
+    rows, err := r.db.QueryContext(ctx, `
+        SELECT subject_type, subject, zone, updated_at FROM time_zones
+        ORDER BY subject_type, subject
+    `)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    zones := []models.TimeZone{}
+    for rows.Next() {
+        var tz models.TimeZone
+        if err := rows.Scan(&tz.SubjectType, &tz.Subject, &tz.Zone, &tz.UpdatedAt); err != nil {
+            return nil, err
+        }
+        zones = append(zones, tz)
+    }
+    return zones, rows.Err()
+}
+
+// SetTimeZone - назначает пояс пользователю или чату (повторный вызов заменяет пояс)
+func (r *TaskRepository) SetTimeZone(ctx context.Context, tz *models.TimeZone) error {
+    query := `
+        INSERT INTO time_zones (subject_type, subject, zone)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (subject_type, subject)
+        DO UPDATE SET zone = EXCLUDED.zone, updated_at = CURRENT_TIMESTAMP
+        RETURNING updated_at
+    `
+    return r.db.QueryRowContext(ctx, query, tz.SubjectType, tz.Subject, tz.Zone).Scan(&tz.UpdatedAt)
+}
+
+// DeleteTimeZone - убирает переопределение, дальше действует пояс сервера
+func (r *TaskRepository) DeleteTimeZone(ctx context.Context, subjectType, subject string) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM time_zones WHERE subject_type = $1 AND subject = $2`, subjectType, subject)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("пояс для %s %q: %w", subjectType, subject, ErrNotFound)
+    }
+    return nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "time"
+    "kanban-calendar/internal/models"
+)
+
+// ArchiveTask - убирает задачу в архив: она скрыта из доски, календаря и уведомлений,
+// но находится поиском и по ID. version != 0 - только если с этой версии задачу не меняли
+func (r *TaskRepository) ArchiveTask(ctx context.Context, id, version int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    var archived bool
+    var current int
+    query := `SELECT archived_at IS NOT NULL, version FROM tasks WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+    err = tx.QueryRowContext(ctx, query, id).Scan(&archived, &current)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("задача с ID %d: %w", id, ErrNotFound)
+    }
+    if err != nil {
+        return err
+    }
+    if archived {
+        return fmt.Errorf("задача %d уже в архиве: %w", id, ErrConflict)
+    }
+    if version != 0 && version != current {
+        return fmt.Errorf("задача %d: ожидалась версия %d, текущая %d: %w", id, version, current, ErrVersionMismatch)
+    }
+
+    query = `UPDATE tasks SET archived_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+    if _, err := tx.ExecContext(ctx, query, id); err != nil {
+        return err
+    }
+    if err := recordArchived(ctx, tx, id); err != nil {
+        return err
+    }
+    return tx.Commit()
+}
+
+// recordArchived - событие архивации задачи, которую уже убрали в архив в транзакции tx
+func recordArchived(ctx context.Context, tx *sql.Tx, id int) error {
+    after, err := loadTaskTx(ctx, tx, id)
+    if err != nil {
+        return err
+    }
+    before := *after
+    before.ArchivedAt = nil
+    return recordEvent(ctx, tx, models.EventArchive, &before, after)
+}
+
+// RestoreTask - возвращает задачу на шаг назад: из корзины - туда, где она была (на доску или в архив),
+// из архива - на доску. Задача из корзины снова учитывается в WIP-лимите колонки и проходит его проверку
+func (r *TaskRepository) RestoreTask(ctx context.Context, id int) (*models.WIPViolation, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, err
+    }
+    defer tx.Rollback()
+
+    var deleted, archived bool
+    var columnID int
+    query := `SELECT deleted_at IS NOT NULL, archived_at IS NOT NULL, column_id FROM tasks WHERE id = $1 FOR UPDATE`
+    err = tx.QueryRowContext(ctx, query, id).Scan(&deleted, &archived, &columnID)
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("задача с ID %d: %w", id, ErrNotFound)
+    }
+    if err != nil {
+        return nil, err
+    }
+
+    var set string
+    switch {
+    case deleted:
+        set = "deleted_at = NULL"
+    case archived:
+        set = "archived_at = NULL"
+    default:
+        return nil, fmt.Errorf("задача %d не в корзине и не в архиве: %w", id, ErrConflict)
+    }
+
+    before, err := loadTaskTx(ctx, tx, id)
+    if err != nil {
+        return nil, err
+    }
+
+    var wip *models.WIPViolation
+    if deleted {
+        if err := lockColumn(ctx, tx, columnID); err != nil {
+            return nil, err
+        }
+        if wip, err = checkWIPLimit(ctx, tx, columnID, id); err != nil {
+            return nil, err
+        }
+    }
+
+    query = `UPDATE tasks SET ` + set + `, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+    if _, err := tx.ExecContext(ctx, query, id); err != nil {
+        return nil, mapUniqueViolation(err, "ресурс CalDAV с именем этой задачи")
+    }
+    after, err := loadTaskTx(ctx, tx, id)
+    if err != nil {
+        return nil, err
+    }
+    if err := recordEvent(ctx, tx, models.EventRestore, before, after); err != nil {
+        return nil, err
+    }
+    return wip, tx.Commit()
+}
+
+// GetTrash - задачи в корзине, недавно удаленные первыми
+func (r *TaskRepository) GetTrash(ctx context.Context) ([]models.Task, error) {
+    return r.queryTasks(ctx, taskSelect+` WHERE t.deleted_at IS NOT NULL ORDER BY t.deleted_at DESC, t.id`)
+}
+
+// PurgeTask - удаляет задачу из корзины навсегда (вместе с уведомлениями и тегами; история остается)
+func (r *TaskRepository) PurgeTask(ctx context.Context, id int) error {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return err
+    }
+    defer tx.Rollback()
+
+    n, err := purgeTasks(ctx, tx, `WHERE t.id = $1 AND t.deleted_at IS NOT NULL`, id)
+    if err != nil {
+        return err
+    }
+    if n == 0 {
+        return fmt.Errorf("задача %d в корзине: %w", id, ErrNotFound)
+    }
+    return tx.Commit()
+}
+
+// PurgeTrash - удаляет навсегда задачи, попавшие в корзину раньше before; возвращает их число
+func (r *TaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return 0, err
+    }
+    defer tx.Rollback()
+
+    n, err := purgeTasks(ctx, tx, `WHERE t.deleted_at < $1`, before)
+    if err != nil {
+        return 0, err
+    }
+    return n, tx.Commit()
+}
+
+// purgeTasks - удаляет задачи, подходящие под where, и записывает в историю их последнее состояние
+func purgeTasks(ctx context.Context, tx *sql.Tx, where string, args ...interface{}) (int64, error) {
+    rows, err := tx.QueryContext(ctx, taskSelect+` `+where+` FOR UPDATE OF t`, args...)
+    if err != nil {
+        return 0, err
+    }
+    var tasks []*models.Task
+    for rows.Next() {
+        task, err := scanTask(rows)
+        if err != nil {
+            rows.Close()
+            return 0, err
+        }
+        tasks = append(tasks, task)
+    }
+    rows.Close()
+    if err := rows.Err(); err != nil {
+        return 0, err
+    }
+
+    for _, task := range tasks {
+        if _, err := tx.ExecContext(ctx, `DELETE FROM tasks WHERE id = $1`, task.ID); err != nil {
+            return 0, err
+        }
+        if err := recordEvent(ctx, tx, models.EventPurge, task, nil); err != nil {
+            return 0, err
+        }
+    }
+    return int64(len(tasks)), nil
+}
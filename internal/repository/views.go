@@ -0,0 +1,123 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "kanban-calendar/internal/models"
+)
+
+const viewSelect = `
+    SELECT id, owner, name, query, sort, created_at, updated_at
+    FROM saved_views
+`
+
+func scanView(row rowScanner) (*models.SavedView, error) {
+    view := &models.SavedView{}
+    err := row.Scan(&view.ID, &view.Owner, &view.Name, &view.Query, &view.Sort, &view.CreatedAt, &view.UpdatedAt)
+    return view, err
+}
+
+// GetViews - представления пользователя по имени; owner = "" - представления всех пользователей
+func (r *TaskRepository) GetViews(ctx context.Context, owner string) ([]models.SavedView, error) {
+    rows, err := r.db.QueryContext(ctx, viewSelect+` WHERE $1 = '' OR owner = $1 ORDER BY name, id`, owner)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    views := []models.SavedView{}
+    for rows.Next() {
+        view, err := scanView(rows)
+        if err != nil {
+            return nil, err
+        }
+        views = append(views, *view)
+    }
+    return views, rows.Err()
+}
+
+// GetView - представление по ID (любого владельца: ссылкой на представление можно поделиться)
+func (r *TaskRepository) GetView(ctx context.Context, id int) (*models.SavedView, error) {
+    view, err := scanView(r.db.QueryRowContext(ctx, viewSelect+` WHERE id = $1`, id))
+    if err == sql.ErrNoRows {
+        return nil, fmt.Errorf("представление %d: %w", id, ErrNotFound)
+    }
+    return view, err
+}
+
+// FindViews - представления всех пользователей с именем name (без учета регистра)
+func (r *TaskRepository) FindViews(ctx context.Context, name string) ([]models.SavedView, error) {
+    rows, err := r.db.QueryContext(ctx, viewSelect+` WHERE LOWER(name) = LOWER($1) ORDER BY id`, name)
+    if err != nil {
+        return nil, err
+    }
+    defer rows.Close()
+
+    views := []models.SavedView{}
+    for rows.Next() {
+        view, err := scanView(rows)
+        if err != nil {
+            return nil, err
+        }
+        views = append(views, *view)
+    }
+    return views, rows.Err()
+}
+
+// CreateView - сохраняет представление; имя уникально у владельца
+func (r *TaskRepository) CreateView(ctx context.Context, view *models.SavedView) error {
+    query := `
+        INSERT INTO saved_views (owner, name, query, sort)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at, updated_at
+    `
+    err := r.db.QueryRowContext(ctx, query, view.Owner, view.Name, view.Query, view.Sort).
+        Scan(&view.ID, &view.CreatedAt, &view.UpdatedAt)
+    return mapUniqueViolation(err, fmt.Sprintf("представление %q", view.Name))
+}
+
+// UpdateView - заменяет имя, запрос и сортировку; чужое представление - ErrNotFound
+func (r *TaskRepository) UpdateView(ctx context.Context, view *models.SavedView) error {
+    query := `
+        UPDATE saved_views SET name = $1, query = $2, sort = $3, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $4 AND owner = $5
+        RETURNING created_at, updated_at
+    `
+    err := r.db.QueryRowContext(ctx, query, view.Name, view.Query, view.Sort, view.ID, view.Owner).
+        Scan(&view.CreatedAt, &view.UpdatedAt)
+    if err == sql.ErrNoRows {
+        return fmt.Errorf("представление %d у %q: %w", view.ID, view.Owner, ErrNotFound)
+    }
+    return mapUniqueViolation(err, fmt.Sprintf("представление %q", view.Name))
+}
+
+// DeleteView - удаляет представление владельца
+func (r *TaskRepository) DeleteView(ctx context.Context, id int, owner string) error {
+    res, err := r.db.ExecContext(ctx, `DELETE FROM saved_views WHERE id = $1 AND owner = $2`, id, owner)
+    if err != nil {
+        return err
+    }
+    if n, _ := res.RowsAffected(); n == 0 {
+        return fmt.Errorf("представление %d у %q: %w", id, owner, ErrNotFound)
+    }
+    return nil
+}
+
+// RunView - первые limit задач представления по его запросу и сортировке; more - задач больше.
+// Ошибка разбора сохраненного запроса или сортировки - *QueryError
+func (r *TaskRepository) RunView(ctx context.Context, view *models.SavedView, env QueryEnv, limit int) ([]models.Task, bool, error) {
+    query, err := ParseTaskQuery(view.Query, env)
+    if err != nil {
+        return nil, false, err
+    }
+    sorts, err := ParseTaskSort(view.Sort)
+    if err != nil {
+        return nil, false, &QueryError{Token: view.Sort, Message: err.Error()}
+    }
+    tasks, next, err := r.ListTasksPage(ctx, TaskFilter{Query: query}, TaskPage{Sort: sorts, Limit: limit})
+    if err != nil {
+        return nil, false, err
+    }
+    return tasks, next != "", nil
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+    "context"
+    "database/sql"
+    "fmt"
     "log"
     "os"
+    "strconv"
+    "time"
+    _ "time/tzdata" // база поясов IANA для TZID из .ics, даже если в образе нет zoneinfo
     "kanban-calendar/internal/config"
     "kanban-calendar/internal/database"
+    "kanban-calendar/internal/datetime"
     "kanban-calendar/internal/handlers"
+    "kanban-calendar/internal/ical"
     "kanban-calendar/internal/repository"
+    "kanban-calendar/migrations"
     "kanban-calendar/scheduler"
     "kanban-calendar/telegram"
     "github.com/gin-gonic/gin"
@@ -34,16 +43,45 @@ func main() {
     }
     defer db.Close()
     
-    // Проверяем/создаем таблицы
+    // Режим CLI: ./main migrate status|up|down [N]
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrateCommand(db, os.Args[2:]); err != nil {
+            log.Fatalf("Ошибка миграций: %v", err)
+        }
+        return
+    }
+    
+    // Применяем новые миграции
     if err := database.Migrate(db); err != nil {
-        log.Printf("Предупреждение миграций: %v", err)
+        log.Fatalf("Ошибка миграций: %v", err)
     }
-
-    _, _ = db.Exec(`ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_external_uid_key;`)
-    _, _ = db.Exec(`DROP INDEX IF EXISTS tasks_external_uid_key;`)
+    
+    // Пояс сервера и переопределения пользователей и чатов
+    loc, err := datetime.LoadZone(cfg.TimeZone)
+    if err != nil {
+        log.Fatalf("APP_TIMEZONE: %v", err)
+    }
+    log.Printf("Часовой пояс сервера: %s", loc)
+    zones := datetime.NewZones(loc)
     
     // Создаем репозиторий
-    repo := repository.NewTaskRepository(db)
+    repo := repository.NewTaskRepository(db, loc)
+    
+    overrides, err := repo.GetTimeZones(context.Background())
+    if err != nil {
+        log.Fatalf("Ошибка загрузки часовых поясов: %v", err)
+    }
+    for _, tz := range overrides {
+        if err := zones.Apply(tz); err != nil {
+            log.Printf("Пояс %s %q пропущен: %v", tz.SubjectType, tz.Subject, err)
+        }
+    }
+    
+    retentionDays, err := strconv.Atoi(cfg.TrashRetention)
+    if err != nil || retentionDays < 0 {
+        log.Fatalf("TRASH_RETENTION_DAYS: ожидается число дней, получено %q", cfg.TrashRetention)
+    }
+    trashRetention := time.Duration(retentionDays) * 24 * time.Hour
     
     // Инициализируем Telegram бота (если токен указан)
     var telegramBot *telegram.TelegramBot
@@ -54,20 +92,24 @@ func main() {
             frontendURL = "http://localhost:3000" // Дефолт для разработки
         }
 
-        telegramBot, err = telegram.NewTelegramBot(cfg.TelegramToken, cfg.TelegramChatID, frontendURL)
+        telegramBot, err = telegram.NewTelegramBot(cfg.TelegramToken, cfg.TelegramChatID, frontendURL, zones)
         if err != nil {
             log.Printf("Telegram бот не запущен: %v", err)
+            telegramBot = nil
         } else {
             log.Println("Telegram бот инициализирован")
-            
-            // Запускаем планировщик уведомлений
-            sched := scheduler.NewScheduler(repo, telegramBot)
-            sched.Start()
             telegramBot.SendTestMessage()
-            log.Println("Планировщик уведомлений запущен")
+            go telegramBot.ListenCommands(repo)
         }
     }
     
+    // Планировщик: уведомления о дедлайнах (если есть бот), подписки на календари и очистка корзины
+    // Плавающее время в подписках - в поясе сервера
+    importer := ical.NewImporter(repo, zones.Default())
+    sched := scheduler.NewScheduler(repo, telegramBot, importer, trashRetention)
+    sched.Start()
+    log.Println("Планировщик запущен")
+    
     // Настраиваем Gin
     if cfg.ServerPort == "8080" {
         gin.SetMode(gin.ReleaseMode)
@@ -75,7 +117,7 @@ func main() {
     r := gin.Default()
     
     // Настраиваем маршруты
-    handlers.SetupRoutes(r, repo)
+    handlers.SetupRoutes(r, repo, zones, telegramBot, trashRetention)
     
     // Запуск сервера
     log.Printf("Сервер запущен на http://localhost:%s", cfg.ServerPort)
@@ -84,4 +126,59 @@ func main() {
     if err := r.Run(":" + cfg.ServerPort); err != nil {
         log.Fatal("Ошибка запуска сервера:", err)
     }
-}
\ No newline at end of file
+}
+
+// runMigrateCommand - обрабатывает команды migrate status|up|down [N]
+func runMigrateCommand(db *sql.DB, args []string) error {
+    m, err := database.NewMigrator(db, migrations.FS)
+    if err != nil {
+        return err
+    }
+    
+    ctx := context.Background()
+    cmd := "status"
+    if len(args) > 0 {
+        cmd = args[0]
+    }
+    
+    switch cmd {
+    case "status":
+        statuses, err := m.Status(ctx)
+        if err != nil {
+            return err
+        }
+        for _, st := range statuses {
+            state := "не применена"
+            if st.Applied {
+                state = "применена " + st.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            if st.Modified {
+                state += " (ФАЙЛ ИЗМЕНЕН)"
+            }
+            fmt.Printf("%03d  %-30s %s\n", st.Version, st.Name, state)
+        }
+    case "up":
+        applied, err := m.Up(ctx)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("Применено миграций: %d\n", applied)
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            steps, err = strconv.Atoi(args[1])
+            if err != nil || steps < 1 {
+                return fmt.Errorf("неверное количество шагов: %s", args[1])
+            }
+        }
+        rolled, err := m.Down(ctx, steps)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("Откачено миграций: %d\n", rolled)
+    default:
+        return fmt.Errorf("неизвестная команда %q, используйте: migrate status|up|down [N]", cmd)
+    }
+    
+    return nil
+}
@@ -0,0 +1 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS last_notified_hours;
@@ -1 +0,0 @@
-ALTER TABLE tasks ADD COLUMN last_notified_hours INTEGER DEFAULT 999;
\ No newline at end of file
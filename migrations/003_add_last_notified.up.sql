@@ -0,0 +1,2 @@
+-- Порог последнего отправленного уведомления о дедлайне (в часах)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS last_notified_hours INTEGER DEFAULT 999;
@@ -0,0 +1,8 @@
+-- UID события из внешнего календаря (.ics)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_uid VARCHAR(255);
+
+-- Раньше на колонке висело ограничение уникальности, из-за которого падал повторный импорт
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_external_uid_key;
+DROP INDEX IF EXISTS tasks_external_uid_key;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_external_uid ON tasks(external_uid);
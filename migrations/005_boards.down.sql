@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_tasks_column_id;
+DROP INDEX IF EXISTS idx_tasks_board_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS column_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS board_id;
+DROP TABLE IF EXISTS board_columns;
+DROP TABLE IF EXISTS boards;
@@ -0,0 +1,75 @@
+-- Доски
+CREATE TABLE IF NOT EXISTS boards (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Колонки доски. slug хранится в tasks.status, is_done - колонка считается "выполненной"
+CREATE TABLE IF NOT EXISTS board_columns (
+    id SERIAL PRIMARY KEY,
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    slug VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    is_done BOOLEAN NOT NULL DEFAULT FALSE,
+    color VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (board_id, slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_board_columns_board_id ON board_columns(board_id);
+
+-- Доска по умолчанию со старыми статусами
+INSERT INTO boards (name) SELECT 'Основная доска' WHERE NOT EXISTS (SELECT 1 FROM boards);
+
+INSERT INTO board_columns (board_id, slug, name, position, is_done)
+SELECT b.id, c.slug, c.name, c.position, c.is_done
+FROM (SELECT MIN(id) AS id FROM boards) b,
+     (VALUES ('todo', 'К выполнению', 1, FALSE),
+             ('in_progress', 'В работе', 2, FALSE),
+             ('done', 'Готово', 3, TRUE)) AS c(slug, name, position, is_done)
+ON CONFLICT (board_id, slug) DO NOTHING;
+
+-- Задачи, созданные без статуса, считаем todo
+UPDATE tasks SET status = 'todo' WHERE status IS NULL OR status = '';
+
+-- Нестандартные статусы из существующих задач превращаем в колонки
+INSERT INTO board_columns (board_id, slug, name, position)
+SELECT b.id, s.status, s.status, 100 + ROW_NUMBER() OVER (ORDER BY s.status)
+FROM (SELECT MIN(id) AS id FROM boards) b,
+     (SELECT DISTINCT status FROM tasks) s
+ON CONFLICT (board_id, slug) DO NOTHING;
+
+-- Привязываем задачи к доске и колонке
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS board_id INTEGER REFERENCES boards(id);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS column_id INTEGER REFERENCES board_columns(id);
+
+UPDATE tasks t
+SET board_id = c.board_id, column_id = c.id
+FROM board_columns c
+WHERE t.column_id IS NULL
+  AND c.board_id = (SELECT MIN(id) FROM boards)
+  AND c.slug = t.status;
+
+ALTER TABLE tasks ALTER COLUMN board_id SET NOT NULL;
+ALTER TABLE tasks ALTER COLUMN column_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_board_id ON tasks(board_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_column_id ON tasks(column_id);
+
+-- updated_at для досок и колонок
+DROP TRIGGER IF EXISTS update_boards_updated_at ON boards;
+CREATE TRIGGER update_boards_updated_at
+    BEFORE UPDATE ON boards
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_board_columns_updated_at ON board_columns;
+CREATE TRIGGER update_board_columns_updated_at
+    BEFORE UPDATE ON board_columns
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
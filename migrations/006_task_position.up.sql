@@ -0,0 +1,15 @@
+-- Ранг карточки внутри колонки (лексикографический, сравнение побайтно)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS position VARCHAR(255) COLLATE "C";
+
+-- Начальный порядок - по дате создания
+UPDATE tasks t
+SET position = r.rank
+FROM (
+    SELECT id, LPAD(ROW_NUMBER() OVER (PARTITION BY column_id ORDER BY created_at, id)::text, 10, '0') || 'V' AS rank
+    FROM tasks
+) r
+WHERE r.id = t.id AND t.position IS NULL;
+
+ALTER TABLE tasks ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_column_position ON tasks(column_id, position);
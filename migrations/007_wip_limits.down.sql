@@ -0,0 +1,2 @@
+ALTER TABLE board_columns DROP COLUMN IF EXISTS wip_mode;
+ALTER TABLE board_columns DROP COLUMN IF EXISTS wip_limit;
@@ -0,0 +1,8 @@
+-- WIP-лимит колонки: 0/NULL - без лимита. reject - отклонять, warn - пропускать с предупреждением
+ALTER TABLE board_columns ADD COLUMN IF NOT EXISTS wip_limit INTEGER;
+ALTER TABLE board_columns ADD COLUMN IF NOT EXISTS wip_mode VARCHAR(10) NOT NULL DEFAULT 'reject';
+
+ALTER TABLE board_columns DROP CONSTRAINT IF EXISTS board_columns_wip_mode_check;
+ALTER TABLE board_columns ADD CONSTRAINT board_columns_wip_mode_check CHECK (wip_mode IN ('reject', 'warn'));
+ALTER TABLE board_columns DROP CONSTRAINT IF EXISTS board_columns_wip_limit_check;
+ALTER TABLE board_columns ADD CONSTRAINT board_columns_wip_limit_check CHECK (wip_limit IS NULL OR wip_limit >= 0);
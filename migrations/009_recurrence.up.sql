@@ -0,0 +1,8 @@
+-- Повторяющиеся задачи: правило RFC 5545, исключенные даты (RFC3339, UTC) и серия
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS rrule TEXT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS exdates TEXT[] NOT NULL DEFAULT '{}';
+-- series_id - первая задача серии, у которой нет своего series_id
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS series_id INTEGER REFERENCES tasks(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_series_id ON tasks(series_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_rrule ON tasks(id) WHERE rrule IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Задачи на весь день (VALUE=DATE в iCalendar): время суток у дат не значимо
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT FALSE;
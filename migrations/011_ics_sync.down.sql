@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tasks_external_source_uid;
+ALTER TABLE tasks DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_modified;
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_sequence;
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_source;
@@ -0,0 +1,9 @@
+-- Синхронизация импорта .ics: источник, версия события и архив для пропавших из файла событий
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_source VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_sequence INTEGER;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_modified TIMESTAMP;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+-- Внутри одного источника UID события уникален
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_external_source_uid
+    ON tasks(external_source, external_uid) WHERE external_source IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_assignee;
+DROP TABLE IF EXISTS calendar_feeds;
@@ -0,0 +1,11 @@
+-- Подписываемые ленты .ics: токен в URL вместо авторизации, лента привязана к исполнителю
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    assignee VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_accessed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks(assignee);
@@ -0,0 +1,32 @@
+-- Внешние календари, которые планировщик периодически скачивает и синхронизирует с задачами
+CREATE TABLE IF NOT EXISTS calendar_subscriptions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    refresh_minutes INTEGER NOT NULL DEFAULT 60 CHECK (refresh_minutes >= 5),
+    board_id INTEGER NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    default_assignee VARCHAR(255) NOT NULL DEFAULT '',
+    archive_missing BOOLEAN NOT NULL DEFAULT TRUE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Валидаторы для условного запроса
+    etag TEXT NOT NULL DEFAULT '',
+    last_modified TEXT NOT NULL DEFAULT '',
+    -- Итог последней синхронизации
+    last_sync_at TIMESTAMP,
+    last_success_at TIMESTAMP,
+    last_status VARCHAR(20) NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    last_report JSONB,
+    next_sync_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_calendar_subscriptions_next_sync
+    ON calendar_subscriptions(next_sync_at) WHERE enabled;
+
+DROP TRIGGER IF EXISTS update_calendar_subscriptions_updated_at ON calendar_subscriptions;
+CREATE TRIGGER update_calendar_subscriptions_updated_at
+    BEFORE UPDATE ON calendar_subscriptions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
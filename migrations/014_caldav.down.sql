@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_caldav_name;
+ALTER TABLE tasks DROP COLUMN IF EXISTS caldav_component;
+ALTER TABLE tasks DROP COLUMN IF EXISTS caldav_name;
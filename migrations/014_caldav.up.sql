@@ -0,0 +1,5 @@
+-- CalDAV: имя ресурса, под которым клиент сохранил задачу, и тип его компонента
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS caldav_name VARCHAR(255);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS caldav_component VARCHAR(10);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_caldav_name ON tasks(board_id, caldav_name) WHERE caldav_name IS NOT NULL;
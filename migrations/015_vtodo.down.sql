@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_percent;
+ALTER TABLE tasks DROP COLUMN IF EXISTS external_priority;
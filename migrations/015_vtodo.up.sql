@@ -0,0 +1,3 @@
+-- Исходные PRIORITY (1-9) и PERCENT-COMPLETE задачи из календаря, чтобы выгрузка их не огрубляла
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_priority SMALLINT CHECK (external_priority BETWEEN 1 AND 9);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS external_percent SMALLINT CHECK (external_percent BETWEEN 0 AND 100);
@@ -0,0 +1,8 @@
+-- Часовые пояса исполнителей и чатов Telegram поверх пояса сервера (APP_TIMEZONE)
+CREATE TABLE IF NOT EXISTS time_zones (
+    subject_type VARCHAR(10) NOT NULL CHECK (subject_type IN ('user', 'chat')),
+    subject VARCHAR(255) NOT NULL,
+    zone VARCHAR(64) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (subject_type, subject)
+);
@@ -0,0 +1,3 @@
+-- Версия строки задачи для оптимистичной блокировки (ETag / If-Match).
+-- Растет при каждом изменении содержимого; служебные поля (ранг, счетчик напоминаний) ее не трогают
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
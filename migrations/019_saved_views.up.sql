@@ -0,0 +1,11 @@
+-- Сохраненные представления: именованные запросы пользователя (assignee:me status:!done ...)
+CREATE TABLE IF NOT EXISTS saved_views (
+    id SERIAL PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    query TEXT NOT NULL DEFAULT '',
+    sort VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (owner, name)
+);
@@ -0,0 +1,5 @@
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_tasks_caldav_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_caldav_name ON tasks(board_id, caldav_name) WHERE caldav_name IS NOT NULL;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,8 @@
+-- Корзина: удаленная задача остается в БД до истечения срока хранения (TRASH_RETENTION_DAYS)
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Имя CalDAV-ресурса задачи в корзине снова свободно для клиента
+DROP INDEX IF EXISTS idx_tasks_caldav_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_caldav_name ON tasks(board_id, caldav_name)
+    WHERE caldav_name IS NOT NULL AND deleted_at IS NULL;
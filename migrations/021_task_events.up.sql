@@ -0,0 +1,15 @@
+-- История изменений задач: кто, когда и что поменял (до и после по полям).
+-- Пишется в той же транзакции, что и изменение. Внешнего ключа нет: история переживает удаление задачи
+CREATE TABLE IF NOT EXISTS task_events (
+    id BIGSERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL,
+    board_id INTEGER,
+    task_title VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_events_task ON task_events(task_id, id);
+CREATE INDEX IF NOT EXISTS idx_task_events_created ON task_events(created_at);
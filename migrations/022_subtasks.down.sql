@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS checklist_items;
+DROP INDEX IF EXISTS idx_tasks_parent;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,13 @@
+-- Связи между задачами: blocks (source до target), relates_to, duplicates (source - дубликат target)
+CREATE TABLE IF NOT EXISTS task_links (
+    id SERIAL PRIMARY KEY,
+    source_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    target_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('blocks', 'relates_to', 'duplicates')),
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_id, target_id, type),
+    CHECK (source_id <> target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_links_target ON task_links(target_id, type);
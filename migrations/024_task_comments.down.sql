@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_notifications_pending;
+DROP TABLE IF EXISTS telegram_chats;
+DROP TABLE IF EXISTS task_comment_revisions;
+DROP TABLE IF EXISTS task_comments;
@@ -0,0 +1,32 @@
+-- Комментарии к задачам (Markdown) и их прежние версии
+CREATE TABLE IF NOT EXISTS task_comments (
+    id SERIAL PRIMARY KEY,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    author VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    edited_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments(task_id, id);
+
+CREATE TABLE IF NOT EXISTS task_comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id INTEGER NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    edited_by VARCHAR(255) NOT NULL,
+    edited_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_comment_revisions_comment ON task_comment_revisions(comment_id, id);
+
+-- Личные чаты Telegram пользователей для упоминаний (имя - в нижнем регистре)
+CREATE TABLE IF NOT EXISTS telegram_chats (
+    username VARCHAR(255) PRIMARY KEY,
+    chat_id VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Неотправленные уведомления забирает планировщик
+CREATE INDEX IF NOT EXISTS idx_notifications_pending ON notifications(type, id) WHERE NOT is_sent;
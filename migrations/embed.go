@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS - SQL-файлы миграций, встроенные в бинарник.
+// Имена файлов: <версия>_<название>.up.sql / <версия>_<название>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
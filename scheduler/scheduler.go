@@ -4,31 +4,110 @@ import (
 	"context"
 	"log"
 	"time"
+	"kanban-calendar/internal/ical"
+	"kanban-calendar/internal/models"
 	"kanban-calendar/internal/repository"
 	"kanban-calendar/telegram"
 )
 
 type Scheduler struct {
-	repo     *repository.TaskRepository
-	telegram *telegram.TelegramBot
+	repo           *repository.TaskRepository
+	telegram       *telegram.TelegramBot // nil - уведомления о дедлайнах отключены
+	importer       *ical.Importer
+	trashRetention time.Duration // 0 - корзина не очищается
 }
 
-func NewScheduler(repo *repository.TaskRepository, tg *telegram.TelegramBot) *Scheduler {
+func NewScheduler(repo *repository.TaskRepository, tg *telegram.TelegramBot, importer *ical.Importer, trashRetention time.Duration) *Scheduler {
 	return &Scheduler{
-		repo:     repo,
-		telegram: tg,
+		repo:           repo,
+		telegram:       tg,
+		importer:       importer,
+		trashRetention: trashRetention,
 	}
 }
 
 func (s *Scheduler) Start() {
 	ticker := time.NewTicker(1 * time.Minute)
+	purge := time.NewTicker(1 * time.Hour)
 	go func() {
-		for range ticker.C {
-			s.CheckDeadlines()
+		s.PurgeTrash()
+		for {
+			select {
+			case <-ticker.C:
+				if s.telegram != nil {
+					s.CheckDeadlines()
+					s.RetryMentions()
+				}
+			case <-purge.C:
+				s.PurgeTrash()
+			}
+		}
+	}()
+
+	// Подписки - в своем цикле: медленные календари (до 30 с на каждый) не задерживают напоминания.
+	// Тикер не копит пропущенные срабатывания, так что проходы синхронизации не накладываются
+	syncTicker := time.NewTicker(1 * time.Minute)
+	go func() {
+		for range syncTicker.C {
+			s.SyncSubscriptions()
 		}
 	}()
 }
 
+// PurgeTrash - удаляет навсегда задачи, пролежавшие в корзине дольше срока хранения
+func (s *Scheduler) PurgeTrash() {
+	if s.trashRetention <= 0 {
+		return
+	}
+	purged, err := s.repo.PurgeTrash(context.Background(), time.Now().Add(-s.trashRetention))
+	if err != nil {
+		log.Printf("Ошибка очистки корзины: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Корзина: удалено навсегда задач - %d", purged)
+	}
+}
+
+// RetryMentions - повторяет уведомления об упоминаниях, которые не ушли сразу после сохранения
+// комментария. Свежие не трогает - их еще отправляет обработчик запроса, устаревшие бросает
+func (s *Scheduler) RetryMentions() {
+	ctx := context.Background()
+	now := time.Now()
+	pending, err := s.repo.GetPendingNotifications(ctx, models.NotificationTypeMention, now.Add(-24*time.Hour), now.Add(-time.Minute))
+	if err != nil {
+		log.Printf("Ошибка получения уведомлений: %v", err)
+		return
+	}
+
+	for _, n := range pending {
+		if err := s.telegram.SendMention(n); err != nil {
+			log.Printf("Ошибка отправки упоминания %d в TG: %v", n.ID, err)
+			continue
+		}
+		if err := s.repo.MarkNotificationSent(ctx, n.ID); err != nil {
+			log.Printf("Ошибка отметки уведомления %d: %v", n.ID, err)
+		}
+	}
+}
+
+// SyncSubscriptions - синхронизирует подписки на календари, у которых подошел срок
+func (s *Scheduler) SyncSubscriptions() {
+	ctx := context.Background()
+	subs, err := s.repo.GetDueSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Ошибка получения подписок: %v", err)
+		return
+	}
+
+	for i := range subs {
+		res := s.importer.SyncSubscription(ctx, &subs[i])
+		if res.Status == models.SyncStatusError {
+			log.Printf("Подписка %d (%s): %s", subs[i].ID, subs[i].Name, res.Error)
+		}
+	}
+}
+
 func (s *Scheduler) CheckDeadlines() {
 	tasks, err := s.repo.GetAllTasks(context.Background())
 	if err != nil {
@@ -38,17 +117,17 @@ func (s *Scheduler) CheckDeadlines() {
 
 	// Идем ОТ БОЛЬШЕГО К МЕНЬШЕМУ
 	thresholds := []int{48, 24, 12, 6, 3, 0}
-	
-	loc := time.FixedZone("UTC+5", 5*60*60)
-	now := time.Now().In(loc)
+
+	// Дедлайн хранится как момент времени, поэтому оставшиеся часы от пояса не зависят;
+	// пояс чата и исполнителя важен только при выводе (см. TelegramBot)
+	now := time.Now()
 
 	for _, task := range tasks {
-		if task.Status == "done" || task.Deadline == nil || task.Deadline.IsZero() {
+		if task.IsDone || task.Deadline == nil || task.Deadline.IsZero() {
 			continue
 		}
 
-		deadline := task.Deadline.In(loc)
-		timeLeft := deadline.Sub(now)
+		timeLeft := task.Deadline.Sub(now)
 		hoursLeft := timeLeft.Hours()
 
 		// Если задача просрочена более чем на 1 час, перестаем спамить
@@ -70,13 +70,8 @@ switch ($choice) {
     }
     "3" {
         Write-Host "`nВыполнение миграций..." -ForegroundColor Blue
-        if (Test-Path "migrations") {
-            Get-ChildItem migrations/*.sql | ForEach-Object {
-                Write-Host "  Миграция: $($_.Name)" -ForegroundColor Gray
-            }
-        } else {
-            Write-Host "Директория migrations не найдена" -ForegroundColor Red
-        }
+        go run . migrate up
+        go run . migrate status
     }
     "4" {
         Write-Host "`nИнструкция по созданию Telegram бота:" -ForegroundColor Blue
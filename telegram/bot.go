@@ -5,6 +5,7 @@ import (
     "log"
     "strings"
     "time"
+    "kanban-calendar/internal/datetime"
     "kanban-calendar/internal/models"
     tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -13,9 +14,10 @@ type TelegramBot struct {
     bot    *tgbotapi.BotAPI
     ChatID string
     FrontendURL string
+    zones  *datetime.Zones // даты в сообщениях - в поясе чата
 }
 
-func NewTelegramBot(token, chatID, frontendURL string) (*TelegramBot, error) {
+func NewTelegramBot(token, chatID, frontendURL string, zones *datetime.Zones) (*TelegramBot, error) {
     if token == "" {
         return nil, fmt.Errorf("токен Telegram не указан")
     }
@@ -36,9 +38,25 @@ func NewTelegramBot(token, chatID, frontendURL string) (*TelegramBot, error) {
         bot:    bot,
         ChatID: chatID,
         FrontendURL: frontendURL,
+        zones:  zones,
     }, nil
 }
 
+// formatDeadline - дедлайн в поясе чата; если у исполнителя другое местное время, оно добавляется в скобках
+func (tb *TelegramBot) formatDeadline(task models.Task) string {
+    chat := tb.zones.Chat(tb.ChatID)
+    if task.AllDay {
+        return datetime.FormatDate(*task.Deadline, chat)
+    }
+    text := datetime.Format(*task.Deadline, chat)
+    if task.Assignee != "" {
+        if local := datetime.Format(*task.Deadline, tb.zones.User(task.Assignee)); local != text {
+            text += fmt.Sprintf(" (у исполнителя %s)", local)
+        }
+    }
+    return text
+}
+
 // SendDeadlineNotification - отправляет уведомление о дедлайне
 func (tb *TelegramBot) SendDeadlineNotification(task models.Task, hoursLeft int) error {
     var message string
@@ -72,7 +90,7 @@ func (tb *TelegramBot) SendDeadlineNotification(task models.Task, hoursLeft int)
             "*Статус:* %s",
             task.Title,
             hoursLeft,
-            task.Deadline.Local().Format("02.01.2006 15:04"),
+            tb.formatDeadline(task),
             task.Assignee,
             task.Status,
         )
@@ -87,11 +105,15 @@ func (tb *TelegramBot) SendDeadlineNotification(task models.Task, hoursLeft int)
             "*Исполнитель:* %s",
             task.Title,
             daysLeft,
-            task.Deadline.Format("02.01.2006"),
+            tb.formatDeadline(task),
             task.Assignee,
         )
     }
     
+    if task.Progress != nil {
+        message += "\n*Прогресс:* " + task.Progress.String()
+    }
+    
     // Добавляем ссылку в конец
     cleanBaseURL := strings.TrimSpace(tb.FrontendURL)
     link := fmt.Sprintf("\n\nСсылка на задачу: %s/tasks/%d", cleanBaseURL, task.ID)
@@ -181,6 +203,51 @@ func (tb *TelegramBot) SendDailySummary(
     return err
 }
 
+// SendView - задачи сохраненного представления; more - в представлении есть и другие задачи
+func (tb *TelegramBot) SendView(view models.SavedView, tasks []models.Task, more bool) error {
+    message := fmt.Sprintf("🔎 *%s*\n", view.Name)
+    if view.Query != "" {
+        message += fmt.Sprintf("`%s`\n", view.Query)
+    }
+    message += "\n"
+    
+    if len(tasks) == 0 {
+        message += "Задач нет."
+    }
+    for _, task := range tasks {
+        line := fmt.Sprintf("• %s [%s]", task.Title, task.Status)
+        if task.Assignee != "" {
+            line += " — " + task.Assignee
+        }
+        if task.Deadline != nil {
+            line += ", до " + tb.formatDeadline(task)
+        }
+        message += line + "\n"
+    }
+    if more {
+        message += "…и другие задачи\n"
+    }
+    message += fmt.Sprintf("\n[Открыть представление](%s/views/%d)", tb.FrontendURL, view.ID)
+    
+    msg := tgbotapi.NewMessageToChannel(tb.ChatID, message)
+    msg.ParseMode = "Markdown"
+    
+    _, err := tb.bot.Send(msg)
+    return err
+}
+
+// SendMention - уведомление об упоминании в комментарии, в личный чат пользователя n.ChatID
+func (tb *TelegramBot) SendMention(n models.Notification) error {
+    message := "💬 *Упоминание в комментарии*\n\n" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, n.Message)
+    message += fmt.Sprintf("\n\n[Открыть задачу](%s/tasks/%d)", strings.TrimSpace(tb.FrontendURL), n.TaskID)
+    
+    msg := tgbotapi.NewMessageToChannel(n.ChatID, message)
+    msg.ParseMode = "Markdown"
+    
+    _, err := tb.bot.Send(msg)
+    return err
+}
+
 // SendTestMessage - отправляет тестовое сообщение
 func (tb *TelegramBot) SendTestMessage() error {
     message := "✅ *Kanban Calendar Bot активирован!*\nБот готов отправлять уведомления о дедлайнах."
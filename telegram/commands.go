@@ -0,0 +1,148 @@
+package telegram
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "strconv"
+    "strings"
+    "time"
+    "kanban-calendar/internal/models"
+    "kanban-calendar/internal/repository"
+    tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ViewLimit - сколько задач представления помещается в одно сообщение бота
+const ViewLimit = 30
+
+// commandTimeout - предел на обработку одной команды
+const commandTimeout = 30 * time.Second
+
+// ListenCommands - принимает команды из чата бота (long polling) до остановки процесса:
+//
+//   /views [владелец] - сохраненные представления (всех пользователей или одного)
+//   /view <ID или имя> - задачи представления, как POST /api/views/:id/telegram
+//
+// Команды из других чатов игнорируются: бот отвечает только в своем чате
+func (tb *TelegramBot) ListenCommands(repo *repository.TaskRepository) {
+    u := tgbotapi.NewUpdate(0)
+    u.Timeout = 60
+    for update := range tb.bot.GetUpdatesChan(u) {
+        msg := update.Message
+        if msg == nil || !msg.IsCommand() || !tb.ownChat(msg.Chat) {
+            continue
+        }
+        ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+        var err error
+        switch msg.Command() {
+        case "views":
+            err = tb.listViews(ctx, repo, strings.TrimSpace(msg.CommandArguments()))
+        case "view":
+            err = tb.runView(ctx, repo, strings.TrimSpace(msg.CommandArguments()))
+        case "help", "start":
+            err = tb.reply("Команды:\n/views [владелец] — сохраненные представления\n/view <ID или имя> — задачи представления")
+        }
+        cancel()
+        if err != nil {
+            log.Printf("Ошибка команды /%s в TG: %v", msg.Command(), err)
+        }
+    }
+}
+
+// ownChat - сообщение пришло из чата бота (ChatID - числовой ID или @имя)
+func (tb *TelegramBot) ownChat(chat *tgbotapi.Chat) bool {
+    if chat == nil {
+        return false
+    }
+    return strconv.FormatInt(chat.ID, 10) == tb.ChatID ||
+        (chat.UserName != "" && strings.EqualFold("@"+chat.UserName, tb.ChatID))
+}
+
+// listViews - /views: список представлений с ID для /view
+func (tb *TelegramBot) listViews(ctx context.Context, repo *repository.TaskRepository, owner string) error {
+    views, err := repo.GetViews(ctx, owner)
+    if err != nil {
+        return tb.replyError("Не удалось получить представления", err)
+    }
+    if len(views) == 0 {
+        return tb.reply("Сохраненных представлений нет.")
+    }
+    lines := []string{"Сохраненные представления:"}
+    for _, view := range views {
+        lines = append(lines, fmt.Sprintf("%d. %s (%s)", view.ID, view.Name, view.Owner))
+    }
+    lines = append(lines, "", "Показать задачи: /view <ID или имя>")
+    return tb.reply(strings.Join(lines, "\n"))
+}
+
+// runView - /view: представление по ID или по имени (имя должно быть однозначным) и его задачи через SendView
+func (tb *TelegramBot) runView(ctx context.Context, repo *repository.TaskRepository, arg string) error {
+    if arg == "" {
+        return tb.reply("Укажите ID или имя представления: /view 3. Список — /views")
+    }
+    view, err := findView(ctx, repo, arg)
+    if err != nil {
+        var ambiguous *ambiguousViewError
+        switch {
+        case errors.As(err, &ambiguous):
+            return tb.reply(ambiguous.Error())
+        case errors.Is(err, repository.ErrNotFound):
+            return tb.reply(fmt.Sprintf("Представление «%s» не найдено. Список — /views", arg))
+        }
+        return tb.replyError("Не удалось получить представление", err)
+    }
+
+    env := repository.QueryEnv{User: view.Owner, Zone: tb.zones.Chat(tb.ChatID), Now: time.Now()}
+    tasks, more, err := repo.RunView(ctx, view, env, ViewLimit)
+    if err != nil {
+        return tb.replyError("Не удалось выполнить представление", err)
+    }
+    return tb.SendView(*view, tasks, more)
+}
+
+// ambiguousViewError - под имя подходит несколько представлений разных владельцев
+type ambiguousViewError struct {
+    name  string
+    views []models.SavedView
+}
+
+func (e *ambiguousViewError) Error() string {
+    lines := []string{fmt.Sprintf("Представлений «%s» несколько, укажите ID:", e.name)}
+    for _, view := range e.views {
+        lines = append(lines, fmt.Sprintf("/view %d — %s", view.ID, view.Owner))
+    }
+    return strings.Join(lines, "\n")
+}
+
+// findView - представление по ID или по имени без учета регистра
+func findView(ctx context.Context, repo *repository.TaskRepository, arg string) (*models.SavedView, error) {
+    if id, err := strconv.Atoi(strings.TrimPrefix(arg, "#")); err == nil {
+        return repo.GetView(ctx, id)
+    }
+    found, err := repo.FindViews(ctx, arg)
+    if err != nil {
+        return nil, err
+    }
+    switch len(found) {
+    case 0:
+        return nil, fmt.Errorf("представление %q: %w", arg, repository.ErrNotFound)
+    case 1:
+        return &found[0], nil
+    }
+    return nil, &ambiguousViewError{name: arg, views: found}
+}
+
+// reply - простой текст в чат бота (без разметки: имена представлений и запросы пишут пользователи)
+func (tb *TelegramBot) reply(text string) error {
+    _, err := tb.bot.Send(tgbotapi.NewMessageToChannel(tb.ChatID, text))
+    return err
+}
+
+// replyError - сообщает в чат о неудаче и возвращает исходную ошибку для лога
+func (tb *TelegramBot) replyError(text string, err error) error {
+    if sendErr := tb.reply(text + ": " + err.Error()); sendErr != nil {
+        log.Printf("Ошибка ответа в TG: %v", sendErr)
+    }
+    return err
+}